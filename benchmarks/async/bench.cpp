@@ -19,6 +19,7 @@ limitations under the License.
 
 #include <stdio.h>
 #include <unistd.h>
+#include <ctime>
 #include <chrono>
 #include <thread>
 #include <vector>
@@ -32,12 +33,16 @@ extern "C"
     void plugin_destroy(ss_plugin_t*);
     ss_plugin_t* plugin_init(const ss_plugin_init_input *input, ss_plugin_rc *rc);
     ss_plugin_rc plugin_extract_fields(ss_plugin_t*, const ss_plugin_event_input*, const ss_plugin_field_extract_input*);
+    ss_plugin_metric* plugin_get_metrics(ss_plugin_t*, uint32_t*);
 }
 
 // global benchmark options
 static int g_parallelism;
 static int g_niterations;
 static bool g_use_async;
+static std::string g_wait;
+static int g_idle_us;
+static bool g_print_stats;
 
 static void print_help()
 {
@@ -46,8 +51,12 @@ static void print_help()
         "Options:\n"
         " -h, --help    Print this usage snippet.\n"
         " -a, --async   Run the benchmark by enabling the async extraction optimization (default: off).\n"
+        " -w, --wait <spin|futex>\n"
+        "               The wait strategy of the async extraction optimization, implies -a (default: spin).\n"
         " -n <number>   The number of extraction requests performed in the benchmark (default: 10000).\n"
-        " -p <number>   The number of plugins that run the benchmark in parallel (default: 1).\n");
+        " -p <number>   The number of plugins that run the benchmark in parallel (default: 1).\n"
+        " -i <number>   The idle time in microseconds between two extraction requests (default: 0).\n"
+        " -s, --stats   Print the metrics of the async extraction optimization at the end of the benchmark.\n");
 }
 
 static void parse_options(int argc, char** argv)
@@ -55,6 +64,9 @@ static void parse_options(int argc, char** argv)
     g_parallelism = 1;
     g_niterations = 10000;
     g_use_async = false;
+    g_wait = "";
+    g_idle_us = 0;
+    g_print_stats = false;
 
     for (int i = 1; i < argc; i++)
     {
@@ -68,7 +80,27 @@ static void parse_options(int argc, char** argv)
         {
             g_use_async = true;
         }
-        else if (arg == "-p" || arg == "-n")
+        else if (arg == "-s" || arg == "--stats" )
+        {
+            g_print_stats = true;
+        }
+        else if (arg == "-w" || arg == "--wait" )
+        {
+            i++;
+            if (i >= argc)
+            {
+                fprintf(stderr, "option '%s' requires a parameter\n", arg.c_str());
+                exit(1);
+            }
+            g_wait = argv[i];
+            if (g_wait != "spin" && g_wait != "futex")
+            {
+                fprintf(stderr, "option '%s' parameter must be either 'spin' or 'futex'\n", arg.c_str());
+                exit(1);
+            }
+            g_use_async = true;
+        }
+        else if (arg == "-p" || arg == "-n" || arg == "-i")
         {
             int tmp;
             i++;
@@ -89,6 +121,10 @@ static void parse_options(int argc, char** argv)
             {
                 g_parallelism = tmp;
             }
+            else if (arg == "-i")
+            {
+                g_idle_us = tmp;
+            }
             else
             {
                 g_niterations = tmp;
@@ -127,6 +163,10 @@ static void benchmark(ss_plugin_t *plugin) noexcept
             fprintf(stderr, "plugin %" PRIu64 ": plugin_extract_fields failure: %d\n", (uint64_t) plugin, rc);
             return;
         }
+        if (g_idle_us > 0)
+        {
+            std::this_thread::sleep_for(std::chrono::microseconds(g_idle_us));
+        }
     }
     auto end = std::chrono::high_resolution_clock::now();
 
@@ -147,11 +187,18 @@ int main(int argc, char** argv)
     // initialize plugins and launch a benchmark for each of them in parallel
     std::vector<std::thread> threads;
     std::vector<ss_plugin_t*> plugins;
+    std::string config = g_use_async ? "async" : "";
+    if (g_use_async && !g_wait.empty())
+    {
+        config += ":" + g_wait;
+    }
+    auto start = std::chrono::high_resolution_clock::now();
+    auto cpu_start = std::clock();
     for (int i = 0; i < g_parallelism; ++i)
     {
         ss_plugin_rc rc = SS_PLUGIN_FAILURE;
         ss_plugin_init_input in;
-        in.config = g_use_async ? "async" : "";
+        in.config = config.c_str();
         plugins.push_back(plugin_init(&in, &rc));
         if (rc != SS_PLUGIN_SUCCESS)
         {
@@ -161,15 +208,45 @@ int main(int argc, char** argv)
         threads.push_back(std::thread(benchmark, plugins[i]));
     }
 
-    // wait for all banchmarks to finish and destroy plugins
+    // wait for all banchmarks to finish
     for (int i = 0; i < g_parallelism; ++i)
     {
         if (threads[i].joinable())
         {
             threads[i].join();
         }
+    }
+
+    // print the metrics, which are shared by all the plugins
+    if (g_print_stats)
+    {
+        uint32_t num_metrics = 0;
+        ss_plugin_metric* metrics = plugin_get_metrics(plugins[0], &num_metrics);
+        for (uint32_t i = 0; i < num_metrics; i++)
+        {
+            if (metrics[i].value_type == SS_PLUGIN_METRIC_VALUE_TYPE_U64)
+            {
+                printf("%s: %" PRIu64 "\n", metrics[i].name, metrics[i].value.u64);
+            }
+        }
+    }
+
+    // destroy plugins
+    for (int i = 0; i < g_parallelism; ++i)
+    {
         plugin_destroy(plugins[i]);
     }
 
+    // print the CPU usage of the whole process, which includes the one
+    // of the async workers, relative to the elapsed wall time
+    auto cpu_end = std::clock();
+    auto end = std::chrono::high_resolution_clock::now();
+    auto time_ns = std::chrono::duration_cast<std::chrono::nanoseconds>(end - start);
+    double cpu_ns = (double) (cpu_end - cpu_start) * 1e9 / (double) CLOCKS_PER_SEC;
+    printf("cpu usage: %.02f%% (cpu time %" PRIu64 "ns, elapsed time %" PRIu64 "ns)\n",
+        100.0 * cpu_ns / (double) time_ns.count(),
+        (uint64_t) cpu_ns,
+        (uint64_t) time_ns.count());
+
     return 0;
 }
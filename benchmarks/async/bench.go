@@ -23,6 +23,8 @@ limitations under the License.
 package main
 
 import (
+	"strings"
+
 	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk"
 	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk/plugins"
 	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk/plugins/extractor"
@@ -50,9 +52,13 @@ func (m *MockPlugin) Fields() []sdk.FieldEntry {
 }
 
 // note: we enable/disable the async extraction optimization depending on the
-// passed-in config
+// passed-in config, which is either "async" or "async:<wait strategy>"
 func (m *MockPlugin) Init(config string) error {
-	extract.SetAsync(config == "async")
+	extract.SetAsync(strings.HasPrefix(config, "async"))
+	if strings.HasPrefix(config, "async:") {
+		wait := extract.AsyncWait(strings.TrimPrefix(config, "async:"))
+		return extract.SetAsyncConfig(extract.AsyncConfig{Wait: wait})
+	}
 	return nil
 }
 
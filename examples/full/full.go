@@ -154,6 +154,7 @@ func (m *MyPlugin) Fields() []sdk.FieldEntry {
 		{Type: "ipaddr", Name: "example.ipv6addr", Display: "Sample IPv6 address", Desc: "A sample IPv6 address"},
 		{Type: "ipnet", Name: "example.ipv4net", Display: "Sample IPv4 network", Desc: "A sample IPv4 network"},
 		{Type: "ipnet", Name: "example.ipv6net", Display: "Sample IPv6 network", Desc: "A sample IPv6 network"},
+		{Type: "ipaddr", Name: "example.ipaddrs", IsList: true, Display: "Sample IP addresses", Desc: "A list of sample IPv4 and IPv6 addresses"},
 	}
 }
 
@@ -205,6 +206,9 @@ func (m *MyPlugin) Extract(req sdk.ExtractRequest, evt sdk.EventReader) error {
 			println(err.Error())
 		}
 		return nil
+	case "example.ipaddrs":
+		req.SetValue([]net.IP{net.IPv4allsys.To4(), net.IPv6loopback})
+		return nil
 	default:
 		return fmt.Errorf("unsupported field: %s", req.Field())
 	}
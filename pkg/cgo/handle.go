@@ -37,36 +37,131 @@ import (
 // a sentinel in C APIs.
 //
 // The performance optimization comes with a limitation: the maximum number
-// of valid handles is capped to a fixed value (see MaxHandle).
-// However, since the intended usage is to pass opaque pointers holding the
-// plugin states (usually at most two pointers per one instance of a plugin),
-// this hard limit is considered acceptable.
+// of valid handles is capped to a fixed value (see MaxHandle). Handles are
+// stored in a table that grows on demand up to that value, and each handle
+// value holds an index in the table (see Index) and a generation counter
+// that is used to detect the usage of deleted handles.
 //
-// The thread-safety guarantees have been dropped for further
-// performance improvements. The current version of the Plugin API does not
-// require thread safety.
+// Creating, accessing, and deleting handles is lock-free and safe to be
+// done concurrently, however deleting a handle while it is being accessed
+// is a misuse.
 //
 // The usage in other contexts is discuraged.
 type Handle uintptr
 
 const (
-	// MaxHandle is the largest value that an Handle can hold
-	MaxHandle = 256 - 1
+	// HandleIndexBits is the number of least significant bits of a Handle
+	// that hold its index in the handle table. The remaining bits hold
+	// the generation counter of the handle.
+	HandleIndexBits = 20
 
-	// max number of times we're willing to iterate over the vector of reusable
-	// handles to do compare-and-swap before giving up
-	maxNewHandleRounds = 20
+	// MaxHandle is the largest index that an Handle can hold, which is also
+	// the maximum number of handles that can be valid at the same time
+	MaxHandle = 1<<HandleIndexBits - 1
+
+	// handleChunkSize is the number of handles by which the table grows
+	handleChunkSize = 256
+
+	// handleMaxChunks is the max number of chunks in the table
+	handleMaxChunks = (MaxHandle + 1) / handleChunkSize
+
+	// handleGenMask is the mask of the generation counter, once shifted
+	handleGenMask = ^uintptr(0) >> HandleIndexBits
 )
 
+// handleSlot is an entry of the handle table
+type handleSlot struct {
+	// state is the generation of the slot, shifted left by one and with the
+	// least significant bit set if the slot is in use. It comes first to be
+	// 64-bit aligned for atomic accesses.
+	state uint64
+	value unsafe.Pointer // *interface{}
+}
+
 var (
-	handles  [MaxHandle + 1]unsafe.Pointer // [int]*interface{}
-	noHandle unsafe.Pointer                = nil
+	// handles is the handle table, as an array of chunks that are allocated
+	// on demand. Chunks are never released.
+	handles [handleMaxChunks]unsafe.Pointer // [int]*[handleChunkSize]handleSlot
+
+	// numChunks is the number of chunks allocated in the table
+	numChunks int32
+
+	// nextHandle is the smallest index that is likely to be free, from which
+	// the table is looked up when creating new handles
+	nextHandle int64
+
+	// inUse and peakInUse are the current and the maximum number of
+	// handles valid at the same time
+	inUse     int64
+	peakInUse int64
 )
 
 func init() {
 	resetHandles()
 }
 
+// HandleUsage represents the usage of the handle table.
+type HandleUsage struct {
+	// InUse is the number of currently valid handles.
+	InUse int
+	//
+	// Peak is the maximum number of handles observed to be valid at
+	// the same time.
+	Peak int
+	//
+	// Capacity is the number of handles that can be valid at the same
+	// time before the handle table needs to grow. This is always lower
+	// or equal than MaxHandle.
+	Capacity int
+}
+
+// Usage returns the current usage of the handle table.
+func Usage() HandleUsage {
+	return HandleUsage{
+		InUse:    int(atomic.LoadInt64(&inUse)),
+		Peak:     int(atomic.LoadInt64(&peakInUse)),
+		Capacity: int(atomic.LoadInt32(&numChunks))*handleChunkSize - 1,
+	}
+}
+
+// slotAt returns the slot of the table at the given index, or
+// nil if the slot is not allocated
+func slotAt(idx int) *handleSlot {
+	c := atomic.LoadPointer(&handles[idx/handleChunkSize])
+	if c == nil {
+		return nil
+	}
+	return &(*[handleChunkSize]handleSlot)(c)[idx%handleChunkSize]
+}
+
+// acquire attempts obtaining ownership of a free slot in the [from, to)
+// range of indexes, and returns its index and its state before
+// acquisition. Returns zero if no slot is available.
+func acquire(from, to int, v *interface{}) (int, uint64) {
+	for idx := from; idx < to; idx++ {
+		s := slotAt(idx)
+		st := atomic.LoadUint64(&s.state)
+		if st&1 == 0 && atomic.CompareAndSwapUint64(&s.state, st, st+1) {
+			atomic.StorePointer(&s.value, unsafe.Pointer(v))
+			return idx, st
+		}
+	}
+	return 0, 0
+}
+
+// grow adds a chunk to the table, if it still has the given number of
+// chunks, and returns false if the table can't grow anymore
+func grow(n int32) bool {
+	if n >= handleMaxChunks {
+		return false
+	}
+	// if some other goroutine grew the table in the meantime,
+	// we just help it completing the operation
+	atomic.CompareAndSwapPointer(&handles[n], nil, unsafe.Pointer(new([handleChunkSize]handleSlot)))
+	atomic.CompareAndSwapInt32(&numChunks, n, n+1)
+	return true
+}
+
 // NewHandle returns a handle for a given value.
 //
 // The handle is valid until the program calls Delete on it. The handle
@@ -80,45 +175,77 @@ func init() {
 // The simultaneous number of the valid handles cannot exceed MaxHandle.
 // This function panics if there are no more handles available.
 // Previously created handles may be made available again when
-// invalidated with Delete.
-//
-// This function is not thread-safe.
+// invalidated with Delete, in which case the returned handle has the
+// same index but a different generation counter.
 func NewHandle(v interface{}) Handle {
-	rounds := 0
-	for h := uintptr(1); ; h++ {
-		// we acquired ownership of an handle, return it
-		// note: we attempt accessing slots 1..MaxHandle (included)
-		if atomic.CompareAndSwapPointer(&handles[h], noHandle, (unsafe.Pointer)(&v)) {
-			return Handle(h)
+	for {
+		// we look for a free slot starting from the most likely one,
+		// and then from the start of the table, unless the table is known
+		// to be full. The index 0 is reserved so that the zero value of
+		// a Handle is never valid.
+		n := atomic.LoadInt32(&numChunks)
+		size := int(n) * handleChunkSize
+		next := int(atomic.LoadInt64(&nextHandle))
+		if next < 1 || next > size {
+			next = 1
+		}
+		var idx int
+		var st uint64
+		if atomic.LoadInt64(&inUse) < int64(size-1) {
+			idx, st = acquire(next, size, &v)
+			if idx == 0 {
+				idx, st = acquire(1, next, &v)
+			}
 		}
 
-		// we haven't acquired a handle, but we can try with the next one
-		if h < MaxHandle {
-			continue
+		// we acquired ownership of an handle, return it
+		if idx != 0 {
+			atomic.CompareAndSwapInt64(&nextHandle, int64(next), int64(idx+1))
+			cur := atomic.AddInt64(&inUse, 1)
+			for peak := atomic.LoadInt64(&peakInUse); cur > peak; peak = atomic.LoadInt64(&peakInUse) {
+				if atomic.CompareAndSwapInt64(&peakInUse, peak, cur) {
+					break
+				}
+			}
+			return Handle((uintptr(st>>1)&handleGenMask)<<HandleIndexBits | uintptr(idx))
 		}
 
-		// we iterated over the whole vector of handles, so we get back to start
-		// and try again with another round. Once we do this too many times,
-		// we have no choice if not panic-ing
-		h = uintptr(0) // note: will be incremented when continuing
-		if rounds < maxNewHandleRounds {
-			rounds++
-			continue
+		// the table is full, so we grow it and try again. Once it reached
+		// its max size, we have no choice if not panic-ing
+		if !grow(n) {
+			panic(fmt.Sprintf("plugin-sdk-go/cgo: could not obtain a new handle, all the %d handles are in use", MaxHandle))
 		}
+	}
+}
 
-		panic(fmt.Sprintf("plugin-sdk-go/cgo: could not obtain a new handle after round #%d", rounds))
+// Index returns the index of the handle in the handle table, which is in
+// the range [1, MaxHandle] for valid handles. Indexes are compact, and
+// can be used to associate valid handles to the entries of an array.
+// A deleted handle and a new one may have the same index.
+func (h Handle) Index() int {
+	return int(uintptr(h) & MaxHandle)
+}
+
+// slot returns the slot of a valid handle and its current state,
+// and panics if the handle is invalid
+func (h Handle) slot(op string) (*handleSlot, uint64) {
+	if idx := h.Index(); idx > 0 {
+		if s := slotAt(idx); s != nil {
+			st := atomic.LoadUint64(&s.state)
+			if st&1 == 1 && uintptr(st>>1)&handleGenMask == uintptr(h)>>HandleIndexBits {
+				return s, st
+			}
+		}
 	}
+	panic(fmt.Sprintf("plugin-sdk-go/cgo: misuse (%s) of an invalid Handle %d", op, h))
 }
 
 // Value returns the associated Go value for a valid handle.
 //
 // The method panics if the handle is invalid.
-// This function is not thread-safe.
 func (h Handle) Value() interface{} {
-	if h > MaxHandle || atomic.LoadPointer(&handles[h]) == noHandle {
-		panic(fmt.Sprintf("plugin-sdk-go/cgo: misuse (value) of an invalid Handle %d", h))
-	}
-	return *(*interface{})(atomic.LoadPointer(&handles[h]))
+	s, _ := h.slot("value")
+	return *(*interface{})(atomic.LoadPointer(&s.value))
 }
 
 // Delete invalidates a handle. This method should only be called once
@@ -126,16 +253,37 @@ func (h Handle) Value() interface{} {
 // no longer has a copy of the handle value.
 //
 // The method panics if the handle is invalid.
-// This function is not thread-safe.
 func (h Handle) Delete() {
-	if h > MaxHandle || atomic.LoadPointer(&handles[h]) == noHandle {
+	s, st := h.slot("delete")
+	atomic.StorePointer(&s.value, nil)
+
+	// this increments the generation and marks the slot as free
+	if !atomic.CompareAndSwapUint64(&s.state, st, st+1) {
 		panic(fmt.Sprintf("plugin-sdk-go/cgo: misuse (delete) of an invalid Handle %d", h))
 	}
-	atomic.StorePointer(&handles[h], noHandle)
+	atomic.AddInt64(&inUse, -1)
+
+	// make this the next handle to be looked up, if it comes first
+	idx := int64(h.Index())
+	for next := atomic.LoadInt64(&nextHandle); idx < next; next = atomic.LoadInt64(&nextHandle) {
+		if atomic.CompareAndSwapInt64(&nextHandle, next, idx) {
+			break
+		}
+	}
 }
 
 func resetHandles() {
-	for i := 0; i <= MaxHandle; i++ {
-		atomic.StorePointer(&handles[i], noHandle)
+	if atomic.LoadInt32(&numChunks) == 0 {
+		grow(0)
+	}
+	for i := 0; i < int(atomic.LoadInt32(&numChunks))*handleChunkSize; i++ {
+		s := slotAt(i)
+		atomic.StorePointer(&s.value, nil)
+		if st := atomic.LoadUint64(&s.state); st&1 == 1 {
+			atomic.StoreUint64(&s.state, st+1)
+		}
 	}
+	atomic.StoreInt64(&nextHandle, 1)
+	atomic.StoreInt64(&inUse, 0)
+	atomic.StoreInt64(&peakInUse, 0)
 }
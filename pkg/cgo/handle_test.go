@@ -18,7 +18,9 @@ limitations under the License.
 package cgo
 
 import (
+	"fmt"
 	"reflect"
+	"sync"
 	"sync/atomic"
 	"testing"
 )
@@ -61,13 +63,13 @@ func TestHandle(t *testing.T) {
 	}
 
 	siz := 0
-	for i := 0; i < MaxHandle; i++ {
-		if atomic.LoadPointer(&handles[i]) != noHandle {
+	for i := 0; i < int(atomic.LoadInt32(&numChunks))*handleChunkSize; i++ {
+		if atomic.LoadPointer(&slotAt(i).value) != nil {
 			siz++
 		}
 	}
 
-	if siz != 0 {
+	if siz != 0 || Usage().InUse != 0 {
 		t.Fatalf("handles are not cleared, got %d, want %d", siz, 0)
 	}
 }
@@ -130,23 +132,108 @@ func TestMaxHandle(t *testing.T) {
 	})
 
 	t.Run("max", func(t *testing.T) {
+		handles := make([]Handle, 0)
 		defer func() {
+			for _, h := range handles {
+				h.Delete()
+			}
 			if r := recover(); r != nil {
 				return
 			}
 			t.Fatalf("NewHandle with max handle count did not triggered a panic")
 		}()
-		handles := make([]Handle, 0)
 		for i := 1; i <= MaxHandle+1; i++ {
 			v := i
 			handles = append(handles, NewHandle(&v))
 		}
-		for _, h := range handles {
-			h.Delete()
-		}
 	})
 }
 
+func TestHandleGeneration(t *testing.T) {
+	h1 := NewHandle(1)
+	h1.Delete()
+	h2 := NewHandle(2)
+	defer h2.Delete()
+	if h1.Index() != h2.Index() || h1 == h2 {
+		t.Fatalf("expected handles with same index and different generation, got %d and %d", h1, h2)
+	}
+
+	// deleted handles can't be used even if their index is reused
+	for name, f := range map[string]func(){"value": func() { h1.Value() }, "delete": h1.Delete} {
+		func() {
+			defer func() {
+				if r := recover(); r == nil {
+					t.Fatalf("%s of a deleted handle did not trigger a panic", name)
+				}
+			}()
+			f()
+		}()
+	}
+	if h2.Value() != 2 {
+		t.Fatalf("Value of a Handle got wrong, got %+v, want %+v", h2.Value(), 2)
+	}
+}
+
+func TestHandleUsage(t *testing.T) {
+	resetHandles()
+	n := handleChunkSize * 3
+	handles := make([]Handle, 0)
+	for i := 0; i < n; i++ {
+		handles = append(handles, NewHandle(i))
+	}
+	for i, h := range handles {
+		if h.Index() != i+1 {
+			t.Fatalf("expected index %d, got %d", i+1, h.Index())
+		}
+	}
+	u := Usage()
+	if u.InUse != n || u.Peak != n || u.Capacity < n || u.Capacity > MaxHandle {
+		t.Fatalf("unexpected usage with %d handles: %+v", n, u)
+	}
+
+	// deleted handles are reused first, starting from the smallest index
+	handles[10].Delete()
+	handles[5].Delete()
+	if h := NewHandle(nil); h.Index() != 6 {
+		t.Fatalf("expected index %d, got %d", 6, h.Index())
+	} else {
+		handles[5] = h
+	}
+	if h := NewHandle(nil); h.Index() != 11 {
+		t.Fatalf("expected index %d, got %d", 11, h.Index())
+	} else {
+		handles[10] = h
+	}
+
+	for _, h := range handles {
+		h.Delete()
+	}
+	if u := Usage(); u.InUse != 0 || u.Peak != n {
+		t.Fatalf("unexpected usage with no handles: %+v", u)
+	}
+}
+
+func TestHandleConcurrent(t *testing.T) {
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				h := NewHandle(g*1000 + i)
+				if v := h.Value(); v != g*1000+i {
+					panic(fmt.Sprintf("Value of a Handle got wrong, got %+v, want %+v", v, g*1000+i))
+				}
+				h.Delete()
+			}
+		}(g)
+	}
+	wg.Wait()
+	if u := Usage(); u.InUse != 0 {
+		t.Fatalf("unexpected usage with no handles: %+v", u)
+	}
+}
+
 func BenchmarkHandle(b *testing.B) {
 	b.Run("non-concurrent", func(b *testing.B) {
 		for i := 0; i < b.N; i++ {
@@ -0,0 +1,431 @@
+// SPDX-License-Identifier: Apache-2.0
+/*
+Copyright (C) 2026 The Falco Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package conformance provides a test suite that checks whether a plugin
+// built as a shared library complies with the contract of the plugin API.
+// The suite loads the plugin with the loader package, and runs one subtest
+// for each part of the contract:
+//   - Validate: the plugin passes the validation of the loader, and none
+//     of its capabilities is broken
+//   - Info: the info strings are not empty, and the versions are valid
+//     semantic versions
+//   - Fields: get_fields returns well-formed entries with supported types
+//     and unique names
+//   - InitSchema: get_init_schema returns a valid JSON Schema, if any
+//   - Init: the plugin initializes with the given config, which is empty
+//     by default
+//   - OpenParams: list_open_params returns a well-formed JSON array
+//   - OpenClose: capture sessions can be opened and closed repeatedly
+//     without leaking file descriptors or memory
+//   - NextBatch: next_batch returns well-formed events, and each call
+//     returns within the configured timeout
+//   - Extract: every field can be extracted from the produced events
+//
+// The subtests about event sourcing and field extraction run only if the
+// plugin supports the related capability. Every time the plugin reports
+// a failure, the suite also checks that the plugin sets its last error.
+//
+// Usage example:
+//
+//	func TestConformance(t *testing.T) {
+//		conformance.Run(t, "./libmyplugin.so", conformance.WithOpenParams("file.log"))
+//	}
+package conformance
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"regexp"
+	"runtime"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/falcosecurity/plugin-sdk-go/pkg/loader"
+	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk"
+	"github.com/xeipuuv/gojsonschema"
+)
+
+const (
+	defaultOpenCloseCycles  = 100
+	defaultNextBatchTimeout = time.Second
+	// maxNextBatchCalls is the max number of next_batch calls of NextBatch
+	maxNextBatchCalls = 32
+	// maxEvents is the max number of events collected by NextBatch and
+	// used by Extract
+	maxEvents = 256
+	// openCloseWarmup is the number of cycles of OpenClose run before
+	// taking the first resource usage sample
+	openCloseWarmup = 10
+	// maxRSSGrowth is the max growth of the resident memory tolerated
+	// by OpenClose
+	maxRSSGrowth = 32 << 20
+)
+
+// semverRegexp matches the semantic versions, as in https://semver.org
+var semverRegexp = regexp.MustCompile(`^(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)` +
+	`(?:-((?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*)(?:\.(?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*))*))?` +
+	`(?:\+([0-9a-zA-Z-]+(?:\.[0-9a-zA-Z-]+)*))?$`)
+
+// Option is an option of the conformance suite, which can be passed to Run.
+type Option func(*suite)
+
+type suite struct {
+	p                 *loader.Plugin
+	initConfig        string
+	openParams        string
+	failingOpenParams *string
+	cycles            int
+	timeout           time.Duration
+	evts              []loader.Event
+}
+
+// WithInitConfig sets the config passed to the plugin by the Init subtest.
+// By default, the plugin is initialized with an empty config.
+func WithInitConfig(config string) Option {
+	return func(s *suite) {
+		s.initConfig = config
+	}
+}
+
+// WithOpenParams sets the params passed to the plugin when opening
+// capture sessions. By default, capture sessions are opened with empty
+// params.
+func WithOpenParams(params string) Option {
+	return func(s *suite) {
+		s.openParams = params
+	}
+}
+
+// WithFailingOpenParams sets params with which opening a capture session
+// is expected to fail. If set, the OpenParams subtest checks that the
+// plugin fails and sets its last error.
+func WithFailingOpenParams(params string) Option {
+	return func(s *suite) {
+		s.failingOpenParams = &params
+	}
+}
+
+// WithOpenCloseCycles sets the number of capture sessions opened and closed
+// by the OpenClose subtest. The default is 100.
+func WithOpenCloseCycles(cycles int) Option {
+	return func(s *suite) {
+		s.cycles = cycles
+	}
+}
+
+// WithNextBatchTimeout sets the max duration of each next_batch call
+// tolerated by the NextBatch subtest. The default is one second.
+func WithNextBatchTimeout(timeout time.Duration) Option {
+	return func(s *suite) {
+		s.timeout = timeout
+	}
+}
+
+// WithEvents sets events from which the Extract subtest extracts fields,
+// in addition to the ones produced by the plugin itself. This is useful
+// for plugins with field extraction capability only.
+func WithEvents(evts ...loader.Event) Option {
+	return func(s *suite) {
+		s.evts = append(s.evts, evts...)
+	}
+}
+
+// Run loads the plugin at the given path and runs the conformance suite
+// on it, with one subtest for each part of the plugin API contract. The
+// plugin is unloaded at the end of the test.
+func Run(t *testing.T, pluginPath string, options ...Option) {
+	t.Helper()
+	s := &suite{
+		cycles:  defaultOpenCloseCycles,
+		timeout: defaultNextBatchTimeout,
+	}
+	for _, opt := range options {
+		opt(s)
+	}
+
+	p, err := loader.NewPlugin(pluginPath)
+	if err != nil {
+		t.Fatalf("can't load plugin: %s", err.Error())
+	}
+	t.Cleanup(p.Unload)
+	s.p = p
+
+	if !t.Run("Validate", s.testValidate) {
+		return
+	}
+	t.Run("Info", s.testInfo)
+	if p.HasCapExtraction() {
+		t.Run("Fields", s.testFields)
+	}
+	t.Run("InitSchema", s.testInitSchema)
+	if !t.Run("Init", s.testInit) {
+		return
+	}
+	if p.HasCapSourcing() {
+		t.Run("OpenParams", s.testOpenParams)
+		t.Run("OpenClose", s.testOpenClose)
+		t.Run("NextBatch", s.testNextBatch)
+	}
+	if p.HasCapExtraction() {
+		t.Run("Extract", s.testExtract)
+	}
+}
+
+// checkFailure reports an error if err is the result of a failure for which
+// the plugin did not set its last error
+func checkFailure(t *testing.T, fn string, err error) {
+	t.Helper()
+	if errors.Is(err, loader.ErrMissingLastError) {
+		t.Errorf("%s failed without setting a last error", fn)
+	}
+}
+
+func (s *suite) testValidate(t *testing.T) {
+	if err := s.p.Validate(); err != nil {
+		t.Fatalf("plugin is not valid: %s", err.Error())
+	}
+	if s.p.HasCapBroken() {
+		t.Fatalf("plugin has a broken capability: %s", s.p.CapBrokenError().Error())
+	}
+}
+
+func (s *suite) testInfo(t *testing.T) {
+	info := s.p.Info()
+	for name, value := range map[string]string{
+		"name":                 info.Name,
+		"description":          info.Description,
+		"contact":              info.Contact,
+		"version":              info.Version,
+		"required API version": info.RequiredAPIVersion,
+	} {
+		if len(value) == 0 {
+			t.Errorf("plugin %s is empty", name)
+		}
+	}
+	if !semverRegexp.MatchString(info.Version) {
+		t.Errorf("plugin version is not a valid semantic version: %q", info.Version)
+	}
+	if !semverRegexp.MatchString(info.RequiredAPIVersion) {
+		t.Errorf("plugin required API version is not a valid semantic version: %q", info.RequiredAPIVersion)
+	}
+	if s.p.HasCapSourcing() && info.ID != 0 && len(info.EventSource) == 0 {
+		t.Errorf("plugin has ID %d, but its event source is empty", info.ID)
+	}
+	if s.p.HasCapSourcing() && info.ID == 0 && len(info.EventSource) > 0 {
+		t.Errorf("plugin has event source %q, but its ID is 0", info.EventSource)
+	}
+	for _, src := range info.ExtractEventSources {
+		if len(src) == 0 {
+			t.Errorf("plugin has an empty compatible extraction event source")
+		}
+	}
+}
+
+func (s *suite) testFields(t *testing.T) {
+	fields := s.p.Fields()
+	if len(fields) == 0 {
+		t.Fatalf("plugin has the field extraction capability, but no fields")
+	}
+	names := make(map[string]bool)
+	for i := range fields {
+		f := &fields[i]
+		if len(f.Name) == 0 {
+			t.Errorf("field at index %d has an empty name", i)
+			continue
+		}
+		if names[f.Name] {
+			t.Errorf("field %s is defined more than once", f.Name)
+		}
+		names[f.Name] = true
+		if _, ok := sdk.FieldTypeCode(f.Type); !ok {
+			t.Errorf("field %s has unsupported type %q", f.Name, f.Type)
+		}
+		if len(f.Desc) == 0 {
+			t.Errorf("field %s has an empty description", f.Name)
+		}
+		if err := f.Check(); err != nil {
+			t.Errorf("field %s is not valid: %s", f.Name, err.Error())
+		}
+		for _, w := range f.Warnings() {
+			t.Logf("warning: field %s: %s", f.Name, w)
+		}
+	}
+}
+
+func (s *suite) testInitSchema(t *testing.T) {
+	schema := s.p.InitSchema()
+	if schema == nil {
+		t.Skip("plugin has no init schema")
+	}
+	if _, err := gojsonschema.NewSchema(gojsonschema.NewStringLoader(schema.Schema)); err != nil {
+		t.Errorf("plugin init schema is not a valid JSON Schema: %s", err.Error())
+	}
+}
+
+func (s *suite) testInit(t *testing.T) {
+	if err := s.p.Init(s.initConfig); err != nil {
+		checkFailure(t, "plugin_init", err)
+		t.Fatalf("can't initialize plugin with config %q: %s", s.initConfig, err.Error())
+	}
+}
+
+func (s *suite) testOpenParams(t *testing.T) {
+	params, err := s.p.OpenParams()
+	if err != nil {
+		checkFailure(t, "plugin_list_open_params", err)
+		t.Errorf("can't list open params: %s", err.Error())
+	}
+	for i, p := range params {
+		if len(p.Value) == 0 {
+			t.Errorf("open param at index %d has an empty value", i)
+		}
+	}
+	if s.failingOpenParams != nil {
+		inst, err := s.p.Open(*s.failingOpenParams)
+		if err == nil {
+			inst.Close()
+			t.Errorf("plugin_open succeeded with params %q, but should have failed", *s.failingOpenParams)
+		} else {
+			checkFailure(t, "plugin_open", err)
+		}
+	}
+}
+
+func (s *suite) testOpenClose(t *testing.T) {
+	var fds, rss int
+	for i := 0; i < openCloseWarmup+s.cycles; i++ {
+		if i == openCloseWarmup {
+			fds, rss = resourceUsage()
+		}
+		inst, err := s.p.Open(s.openParams)
+		if err != nil {
+			checkFailure(t, "plugin_open", err)
+			t.Fatalf("can't open capture session with params %q: %s", s.openParams, err.Error())
+		}
+		inst.Close()
+	}
+	if fds < 0 {
+		t.Skip("can't measure resource usage on " + runtime.GOOS)
+	}
+	newFds, newRSS := resourceUsage()
+	if newFds > fds {
+		t.Errorf("%d file descriptors leaked after %d capture sessions", newFds-fds, s.cycles)
+	}
+	if newRSS-rss > maxRSSGrowth {
+		t.Errorf("resident memory grew by %d bytes after %d capture sessions", newRSS-rss, s.cycles)
+	}
+}
+
+func (s *suite) testNextBatch(t *testing.T) {
+	inst, err := s.p.Open(s.openParams)
+	if err != nil {
+		checkFailure(t, "plugin_open", err)
+		t.Fatalf("can't open capture session with params %q: %s", s.openParams, err.Error())
+	}
+	defer inst.Close()
+
+	for i := 0; i < maxNextBatchCalls; i++ {
+		start := time.Now()
+		evts, err := inst.NextBatch()
+		if elapsed := time.Since(start); elapsed > s.timeout {
+			t.Errorf("plugin_next_batch returned after %s, but the timeout is %s", elapsed, s.timeout)
+		}
+		for j := 0; j < len(evts) && len(s.evts) < maxEvents; j++ {
+			s.evts = append(s.evts, evts[j])
+		}
+		if errors.Is(err, sdk.ErrEOF) {
+			return
+		}
+		if err != nil && !errors.Is(err, sdk.ErrTimeout) {
+			checkFailure(t, "plugin_next_batch", err)
+			t.Fatalf("can't read events: %s", err.Error())
+		}
+	}
+}
+
+func (s *suite) testExtract(t *testing.T) {
+	if len(s.evts) == 0 {
+		t.Skip("no events to extract fields from")
+	}
+	for _, f := range s.p.Fields() {
+		if f.Arg.IsRequired {
+			// the request lacks the required argument, so the plugin
+			// is expected to either fail or extract no value
+			_, err := s.p.ExtractBatch(s.evts, []loader.ExtractField{{Name: f.Name}})
+			checkFailure(t, "plugin_extract_fields", err)
+		}
+		field, ok := extractField(&f)
+		if !ok {
+			continue
+		}
+		if _, err := s.p.ExtractBatch(s.evts, []loader.ExtractField{field}); err != nil {
+			checkFailure(t, "plugin_extract_fields", err)
+			t.Errorf("can't extract field %s: %s", f.Name, err.Error())
+		}
+	}
+}
+
+// extractField returns a request for the given field, with an argument
+// satisfying its constraints if required. The returned bool is false if
+// no such argument can be built.
+func extractField(f *sdk.FieldEntry) (loader.ExtractField, bool) {
+	res := loader.ExtractField{Name: f.Name}
+	if !f.Arg.IsRequired {
+		return res, true
+	}
+	res.ArgPresent = true
+	if f.Arg.IsIndex {
+		if f.Arg.IndexRange != nil {
+			res.ArgIndex = f.Arg.IndexRange.Min
+		}
+		return res, true
+	}
+	if len(f.Arg.AllowedKeys) > 0 {
+		res.ArgKey = f.Arg.AllowedKeys[0]
+		return res, true
+	}
+	if len(f.Arg.KeyPattern) == 0 {
+		res.ArgKey = "key"
+		return res, true
+	}
+	return res, false
+}
+
+// resourceUsage returns the number of open file descriptors and the
+// resident memory in bytes of the current process, or -1 if they can't
+// be measured
+func resourceUsage() (fds int, rss int) {
+	entries, err := os.ReadDir("/proc/self/fd")
+	if err != nil {
+		return -1, -1
+	}
+	statm, err := os.ReadFile("/proc/self/statm")
+	if err != nil {
+		return -1, -1
+	}
+	fields := bytes.Fields(statm)
+	if len(fields) < 2 {
+		return -1, -1
+	}
+	pages, err := strconv.Atoi(string(fields[1]))
+	if err != nil {
+		return -1, -1
+	}
+	return len(entries), pages * os.Getpagesize()
+}
@@ -0,0 +1,38 @@
+// SPDX-License-Identifier: Apache-2.0
+/*
+Copyright (C) 2026 The Falco Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conformance
+
+import (
+	"os"
+	"testing"
+
+	"github.com/falcosecurity/plugin-sdk-go/pkg/loader/internal/examples"
+)
+
+func TestMain(m *testing.M) {
+	code := m.Run()
+	examples.Cleanup()
+	os.Exit(code)
+}
+
+func TestRun(t *testing.T) {
+	Run(t, examples.Build(t, "full"),
+		WithInitConfig(`{"start": 1}`),
+		WithOpenCloseCycles(10),
+	)
+}
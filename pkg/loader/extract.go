@@ -0,0 +1,355 @@
+// SPDX-License-Identifier: Apache-2.0
+/*
+Copyright (C) 2026 The Falco Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loader
+
+/*
+#include "plugin_loader.h"
+#include <stdlib.h>
+#ifndef _WIN32
+#include <dlfcn.h>
+#endif
+
+static ss_plugin_rc __extract_fields(plugin_api* p, ss_plugin_t *s, const ss_plugin_event_input *e, ss_plugin_field_extract_input *in)
+{
+    return p->extract_fields(s, e, in);
+}
+
+typedef ss_plugin_rc (*extract_fields_batch_fn)(ss_plugin_t*, uint32_t, const ss_plugin_event_input*, uint32_t, ss_plugin_extract_field*);
+
+// Returns the plugin_extract_fields_batch symbol exported by plugins built
+// with the Go SDK, or NULL if not available
+static void* __get_extract_fields_batch(plugin_handle_t* h)
+{
+	if (!h->handle) return NULL;
+#ifdef _WIN32
+	return (void*)GetProcAddress(h->handle, "plugin_extract_fields_batch");
+#else
+	return dlsym(h->handle, "plugin_extract_fields_batch");
+#endif
+}
+
+static ss_plugin_rc __extract_fields_batch(void* f, ss_plugin_t *s, uint32_t n, const ss_plugin_event_input *e, uint32_t nf, ss_plugin_extract_field *fields)
+{
+	return ((extract_fields_batch_fn)f)(s, n, e, nf, fields);
+}
+*/
+import "C"
+import (
+	"fmt"
+	"net"
+	"time"
+	"unsafe"
+
+	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk"
+)
+
+// extractBatchSize is the max number of events passed to the plugin in
+// a single extraction call by ExtractBatch
+const extractBatchSize = sdk.MaxExtractBatchEvents
+
+// extractBatchDisabled makes ExtractBatch invoke plugin_extract_fields once
+// for each event even if the plugin exports plugin_extract_fields_batch,
+// and is only meant to be set by tests
+var extractBatchDisabled = false
+
+// ExtractField represents a field to be extracted with ExtractBatch,
+// along with its optional argument.
+type ExtractField struct {
+	Name       string
+	ArgKey     string
+	ArgIndex   uint64
+	ArgPresent bool
+}
+
+// Event represents an event from which fields are extracted with
+// ExtractBatch. Data is the payload of the event, as written by the
+// plugin that produced it through an sdk.EventWriter.
+type Event struct {
+	Num       uint64
+	Timestamp uint64
+	Source    string
+	Data      []byte
+}
+
+// Column contains the values of a field extracted from a batch of events.
+// Values[i] is the value extracted from the i-th event, or nil if the
+// field has no value for it. Depending on Type, values are of type uint64,
+// string, time.Duration, time.Time, bool, net.IP, or net.IPNet, or slices
+// of those if IsList is true.
+type Column struct {
+	Field  ExtractField
+	Type   uint32
+	IsList bool
+	Values []interface{}
+}
+
+// ExtractBatch extracts the given fields from each of the given events, and
+// returns the extracted values as one Column for each field, in the same
+// order. This is meant to be used by offline tools that extract fields from
+// many events at once. The plugin must be initialized and support the field
+// extraction capability.
+//
+// For plugins built with the Go SDK, events are passed to the plugin in
+// batches through the plugin_extract_fields_batch symbol, which requires
+// a single C -> Go call for many events. For other plugins, this falls back
+// to invoking plugin_extract_fields once for each event. In both cases, the
+// first extraction failure is returned as an error.
+func (p *Plugin) ExtractBatch(evts []Event, fields []ExtractField) ([]Column, error) {
+	p.m.Lock()
+	defer p.m.Unlock()
+	if !p.HasCapExtraction() {
+		return nil, errNoExtractionCap
+	}
+	if p.state == nil {
+		return nil, errNotInitialized
+	}
+
+	// prepare the columns and the C representation of the fields
+	cols := make([]Column, len(fields))
+	cFields := make([]C.ss_plugin_extract_field, len(fields))
+	var cStrs []*C.char
+	defer func() {
+		for _, s := range cStrs {
+			C.free(unsafe.Pointer(s))
+		}
+	}()
+	cString := func(s string) *C.char {
+		res := C.CString(s)
+		cStrs = append(cStrs, res)
+		return res
+	}
+	for i, f := range fields {
+		id, entry := p.field(f.Name)
+		if entry == nil {
+			return nil, fmt.Errorf("unknown field: %s", f.Name)
+		}
+		ftype, ok := sdk.FieldTypeCode(entry.Type)
+		if !ok {
+			return nil, fmt.Errorf("field %s has unsupported type: %s", f.Name, entry.Type)
+		}
+		cols[i] = Column{Field: f, Type: ftype, IsList: entry.IsList, Values: make([]interface{}, len(evts))}
+		cFields[i].field_id = C.uint32_t(id)
+		cFields[i].field = cString(f.Name)
+		if len(f.ArgKey) > 0 {
+			cFields[i].arg_key = cString(f.ArgKey)
+		}
+		cFields[i].arg_index = C.uint64_t(f.ArgIndex)
+		cFields[i].arg_present = C.ss_plugin_bool(boolToUint32(f.ArgPresent))
+		cFields[i].ftype = C.uint32_t(ftype)
+		cFields[i].flist = C.ss_plugin_bool(boolToUint32(entry.IsList))
+	}
+	if len(evts) == 0 || len(fields) == 0 {
+		return cols, nil
+	}
+
+	// allocate the C memory for a batch of events and their requests
+	maxDataSize := 0
+	for _, e := range evts {
+		if len(e.Data) > maxDataSize {
+			maxDataSize = len(e.Data)
+		}
+	}
+	batchSize := len(evts)
+	if batchSize > extractBatchSize {
+		batchSize = extractBatchSize
+	}
+	writers, err := sdk.NewEventWriters(int64(batchSize), int64(maxDataSize))
+	if err != nil {
+		return nil, err
+	}
+	defer writers.Free()
+	evtPtrs := unsafe.Slice((**C.ss_plugin_event)(writers.ArrayPtr()), batchSize)
+	cEvts := (*C.ss_plugin_event_input)(C.calloc(C.size_t(batchSize), C.sizeof_ss_plugin_event_input))
+	defer C.free(unsafe.Pointer(cEvts))
+	cReqs := (*C.ss_plugin_extract_field)(C.calloc(C.size_t(batchSize*len(fields)), C.sizeof_ss_plugin_extract_field))
+	defer C.free(unsafe.Pointer(cReqs))
+	evtInputs := unsafe.Slice(cEvts, batchSize)
+	reqs := unsafe.Slice(cReqs, batchSize*len(fields))
+	sources := make(map[string]*C.char)
+
+	batchSym := C.__get_extract_fields_batch(p.handle)
+	if extractBatchDisabled {
+		batchSym = nil
+	}
+	for start := 0; start < len(evts); start += batchSize {
+		n := len(evts) - start
+		if n > batchSize {
+			n = batchSize
+		}
+
+		// write the events and their requests
+		for i := 0; i < n; i++ {
+			evt := &evts[start+i]
+			w := writers.Get(i)
+			if _, err := w.Writer().Write(evt.Data); err != nil {
+				return nil, err
+			}
+			w.SetTimestamp(evt.Timestamp)
+			src, ok := sources[evt.Source]
+			if !ok {
+				src = cString(evt.Source)
+				sources[evt.Source] = src
+			}
+			evtInputs[i] = C.ss_plugin_event_input{evt: evtPtrs[i], evtnum: C.uint64_t(evt.Num), evtsrc: src}
+			copy(reqs[i*len(fields):(i+1)*len(fields)], cFields)
+		}
+
+		// extract the fields from all the events at once, if supported,
+		// or from each event otherwise
+		if batchSym != nil {
+			rc := C.__extract_fields_batch(batchSym, unsafe.Pointer(p.state), C.uint32_t(n), cEvts, C.uint32_t(len(fields)), cReqs)
+			if rc != C.ss_plugin_rc(sdk.SSPluginSuccess) {
+				return nil, fmt.Errorf("extraction failed on events %d to %d: %w", start, start+n-1, p.failure())
+			}
+			for i := 0; i < n; i++ {
+				readColumns(cols, start+i, reqs[i*len(fields):(i+1)*len(fields)])
+			}
+			continue
+		}
+		for i := 0; i < n; i++ {
+			in := C.ss_plugin_field_extract_input{}
+			in.num_fields = C.uint32_t(len(fields))
+			in.fields = &reqs[i*len(fields)]
+			rc := C.__extract_fields(&p.handle.api, unsafe.Pointer(p.state), &evtInputs[i], &in)
+			if rc != C.ss_plugin_rc(sdk.SSPluginSuccess) {
+				return nil, fmt.Errorf("extraction failed on event %d: %w", start+i, p.failure())
+			}
+			readColumns(cols, start+i, reqs[i*len(fields):(i+1)*len(fields)])
+		}
+	}
+	return cols, nil
+}
+
+// field returns the index and the entry of the field with the given name,
+// or nil if the plugin has no such field
+func (p *Plugin) field(name string) (int, *sdk.FieldEntry) {
+	for i := range p.fields {
+		if p.fields[i].Name == name {
+			return i, &p.fields[i]
+		}
+	}
+	return 0, nil
+}
+
+// readColumns copies the results of the given requests, one for each
+// column, as the values of the event at the given index
+func readColumns(cols []Column, evtIdx int, reqs []C.ss_plugin_extract_field) {
+	for i := range cols {
+		cols[i].Values[evtIdx] = readValue(&reqs[i], cols[i].Type, cols[i].IsList)
+	}
+}
+
+// readValue copies the result of the given request in Go memory
+func readValue(req *C.ss_plugin_extract_field, ftype uint32, isList bool) interface{} {
+	n := int(req.res_len)
+	if n == 0 {
+		return nil
+	}
+	res := *(*unsafe.Pointer)(unsafe.Pointer(&req.res))
+	var values []interface{}
+	switch ftype {
+	case sdk.FieldTypeUint64, sdk.FieldTypeRelTime, sdk.FieldTypeAbsTime:
+		for _, v := range unsafe.Slice((*uint64)(res), n) {
+			switch ftype {
+			case sdk.FieldTypeRelTime:
+				values = append(values, time.Duration(v))
+			case sdk.FieldTypeAbsTime:
+				values = append(values, time.Unix(0, int64(v)))
+			default:
+				values = append(values, v)
+			}
+		}
+	case sdk.FieldTypeCharBuf:
+		for _, v := range unsafe.Slice((**C.char)(res), n) {
+			values = append(values, C.GoString(v))
+		}
+	case sdk.FieldTypeBool:
+		for _, v := range unsafe.Slice((*uint32)(res), n) {
+			values = append(values, v != 0)
+		}
+	case sdk.FieldTypeIPAddr, sdk.FieldTypeIPNet:
+		for _, v := range unsafe.Slice((*C.ss_plugin_byte_buffer)(res), n) {
+			ip := net.IP(C.GoBytes(v.ptr, C.int(v.len)))
+			if ftype == sdk.FieldTypeIPNet {
+				values = append(values, net.IPNet{IP: ip})
+			} else {
+				values = append(values, ip)
+			}
+		}
+	}
+	if !isList {
+		return values[0]
+	}
+	return listOf(ftype, values)
+}
+
+// listOf converts the given values to a slice of the Go type of ftype
+func listOf(ftype uint32, values []interface{}) interface{} {
+	switch ftype {
+	case sdk.FieldTypeUint64:
+		res := make([]uint64, len(values))
+		for i, v := range values {
+			res[i] = v.(uint64)
+		}
+		return res
+	case sdk.FieldTypeRelTime:
+		res := make([]time.Duration, len(values))
+		for i, v := range values {
+			res[i] = v.(time.Duration)
+		}
+		return res
+	case sdk.FieldTypeAbsTime:
+		res := make([]time.Time, len(values))
+		for i, v := range values {
+			res[i] = v.(time.Time)
+		}
+		return res
+	case sdk.FieldTypeCharBuf:
+		res := make([]string, len(values))
+		for i, v := range values {
+			res[i] = v.(string)
+		}
+		return res
+	case sdk.FieldTypeBool:
+		res := make([]bool, len(values))
+		for i, v := range values {
+			res[i] = v.(bool)
+		}
+		return res
+	case sdk.FieldTypeIPAddr:
+		res := make([]net.IP, len(values))
+		for i, v := range values {
+			res[i] = v.(net.IP)
+		}
+		return res
+	case sdk.FieldTypeIPNet:
+		res := make([]net.IPNet, len(values))
+		for i, v := range values {
+			res[i] = v.(net.IPNet)
+		}
+		return res
+	}
+	return values
+}
+
+func boolToUint32(v bool) uint32 {
+	if v {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,130 @@
+// SPDX-License-Identifier: Apache-2.0
+/*
+Copyright (C) 2026 The Falco Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loader
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"net"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/falcosecurity/plugin-sdk-go/pkg/loader/internal/examples"
+)
+
+func TestMain(m *testing.M) {
+	code := m.Run()
+	examples.Cleanup()
+	os.Exit(code)
+}
+
+// exampleEvents returns n events of the full example plugin, which
+// contain the gob encoding of their event number
+func exampleEvents(t *testing.T, n int) []Event {
+	res := make([]Event, n)
+	for i := range res {
+		var buf bytes.Buffer
+		if err := gob.NewEncoder(&buf).Encode(uint64(i + 1)); err != nil {
+			t.Fatal(err)
+		}
+		res[i] = Event{
+			Num:       uint64(i + 1),
+			Timestamp: 1700000000000000000 + uint64(i),
+			Source:    "example",
+			Data:      buf.Bytes(),
+		}
+	}
+	return res
+}
+
+func TestExtractBatch(t *testing.T) {
+	p, err := NewPlugin(examples.Build(t, "full"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(p.Unload)
+	if err := p.Init(`{"start": 1}`); err != nil {
+		t.Fatal(err)
+	}
+
+	// the events don't fit in a single batch
+	evts := exampleEvents(t, extractBatchSize*2+10)
+	fields := []ExtractField{
+		{Name: "example.count"},
+		{Name: "example.countstr"},
+		{Name: "example.oddcount"},
+		{Name: "example.initduration"},
+		{Name: "example.evttime"},
+		{Name: "example.ipv4addr"},
+		{Name: "example.ipv6addr"},
+		{Name: "example.ipv4net"},
+		{Name: "example.ipaddrs"},
+	}
+	_, ipv4net, _ := net.ParseCIDR("192.0.2.1/24")
+	expected := func(evt *Event) []interface{} {
+		return []interface{}{
+			evt.Num,
+			fmt.Sprintf("%d", evt.Num),
+			evt.Num%2 == 1,
+			nil,
+			time.Unix(0, int64(evt.Timestamp)),
+			net.IPv4allsys.To4(),
+			net.IPv6loopback,
+			net.IPNet{IP: ipv4net.IP},
+			[]net.IP{net.IPv4allsys.To4(), net.IPv6loopback},
+		}
+	}
+
+	for _, batch := range []bool{true, false} {
+		name := "batch"
+		if !batch {
+			name = "fallback"
+		}
+		t.Run(name, func(t *testing.T) {
+			extractBatchDisabled = !batch
+			defer func() { extractBatchDisabled = false }()
+			cols, err := p.ExtractBatch(evts, fields)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(cols) != len(fields) {
+				t.Fatalf("expected %d columns, but found %d", len(fields), len(cols))
+			}
+			for i := range evts {
+				for j, v := range expected(&evts[i]) {
+					if len(cols[j].Values) != len(evts) {
+						t.Fatalf("expected %d values for %s, but found %d", len(evts), fields[j].Name, len(cols[j].Values))
+					}
+					res := cols[j].Values[i]
+					if v == nil {
+						if d, ok := res.(time.Duration); !ok || d < 0 {
+							t.Errorf("unexpected value of %s for event %d: %v", fields[j].Name, evts[i].Num, res)
+						}
+						continue
+					}
+					if !reflect.DeepEqual(res, v) {
+						t.Errorf("expected value %v of %s for event %d, but found %v", v, fields[j].Name, evts[i].Num, res)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+// SPDX-License-Identifier: Apache-2.0
+/*
+Copyright (C) 2026 The Falco Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package examples builds the example plugins of this repository as shared
+// libraries, to be loaded in the tests of the loader packages.
+package examples
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"testing"
+)
+
+var (
+	mu   sync.Mutex
+	dir  string
+	libs = make(map[string]string)
+)
+
+// Build builds the example plugin with the given name as a shared library,
+// and returns its path. Each example is built only once by a test binary,
+// and the test is skipped in short mode or if the go command is not found.
+// The built libraries are removed by Cleanup.
+func Build(t testing.TB, name string) string {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("building example plugins is skipped in short mode")
+	}
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command not found")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if lib, ok := libs[name]; ok {
+		return lib
+	}
+	if len(dir) == 0 {
+		if dir, err = os.MkdirTemp("", "plugin-sdk-go-examples"); err != nil {
+			t.Fatalf("can't create build directory: %s", err.Error())
+		}
+	}
+	_, file, _, _ := runtime.Caller(0)
+	out := filepath.Join(dir, "lib"+name+".so")
+	cmd := exec.Command(goBin, "build", "-buildmode=c-shared", "-o", out, ".")
+	cmd.Dir = filepath.Join(filepath.Dir(file), "..", "..", "..", "..", "examples", name)
+	if b, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("can't build example plugin %s: %s\n%s", name, err.Error(), string(b))
+	}
+	libs[name] = out
+	return out
+}
+
+// Cleanup removes the libraries built by Build. This is meant to be
+// called from TestMain, once all the tests have run.
+func Cleanup() {
+	mu.Lock()
+	defer mu.Unlock()
+	if len(dir) > 0 {
+		os.RemoveAll(dir)
+		dir = ""
+		libs = make(map[string]string)
+	}
+}
@@ -61,16 +61,6 @@ static const char* __get_last_err(plugin_api* p, ss_plugin_t* s)
     return p->get_last_error(s);
 }
 
-static ss_instance_t* __open(plugin_api* p, ss_plugin_t* s, const char* o, ss_plugin_rc* r)
-{
-    return p->open(s, o, r);
-}
-
-static void __close(plugin_api* p, ss_plugin_t* s, ss_instance_t* h)
-{
-    p->close(s, h);
-}
-
 static const char* __list_open_params(plugin_api* p, ss_plugin_t* s, ss_plugin_rc* rc)
 {
     return p->list_open_params(s, rc);
@@ -86,16 +76,6 @@ static const char* __event_to_string(plugin_api* p, ss_plugin_t *s, const ss_plu
     return p->event_to_string(s, e);
 }
 
-static ss_plugin_rc __next_batch(plugin_api* p, ss_plugin_t* s, ss_instance_t* h, uint32_t *n, ss_plugin_event ***e)
-{
-    return p->next_batch(s, h, n, e);
-}
-
-static ss_plugin_rc __extract_fields(plugin_api* p, ss_plugin_t *s, const ss_plugin_event_input *e, ss_plugin_field_extract_input *in)
-{
-    return p->extract_fields(s, e, in);
-}
-
 */
 import "C"
 import (
@@ -117,10 +97,15 @@ import (
 //   - get_extract_event_types
 
 var (
-	errNotInitialized = errors.New("plugin is not initialized")
-	errNoSourcingCap  = errors.New("plugin does not support event sourcing capability")
+	errNotInitialized  = errors.New("plugin is not initialized")
+	errNoSourcingCap   = errors.New("plugin does not support event sourcing capability")
+	errNoExtractionCap = errors.New("plugin does not support field extraction capability")
 )
 
+// ErrMissingLastError is returned when a plugin reports a failure without
+// setting a last error string, which is a violation of the plugin API.
+var ErrMissingLastError = errors.New("plugin failed without setting a last error")
+
 // Plugin represents a Falcosecurity Plugin loaded from an external shared
 // dynamic library
 type Plugin struct {
@@ -340,12 +325,10 @@ func (p *Plugin) OpenParams() ([]sdk.OpenParam, error) {
 		return ret, nil
 	}
 
-	errBuf := (*C.char)(C.malloc(C.size_t(C.__plugin_max_errlen) * C.sizeof_char))
-	defer C.free(unsafe.Pointer(errBuf))
 	rc := C.ss_plugin_rc(sdk.SSPluginSuccess)
 	str := C.GoString((C.__list_open_params(&p.handle.api, unsafe.Pointer(p.state), (*C.ss_plugin_rc)(&rc))))
 	if rc != C.ss_plugin_rc(sdk.SSPluginSuccess) {
-		return nil, errors.New(C.GoString(errBuf))
+		return nil, p.failure()
 	}
 
 	if len(str) > 0 {
@@ -396,7 +379,7 @@ func (p *Plugin) Init(config string) error {
 		return nil
 	}
 	if p.state != nil {
-		err := p.lastError()
+		err := p.failure()
 		p.destroy()
 		return err
 	}
@@ -440,3 +423,12 @@ func (p *Plugin) lastError() error {
 	}
 	return errNotInitialized
 }
+
+// failure returns the last error of the plugin after a failed call, or
+// ErrMissingLastError if the plugin did not set any
+func (p *Plugin) failure() error {
+	if err := p.lastError(); err != nil {
+		return err
+	}
+	return ErrMissingLastError
+}
@@ -0,0 +1,90 @@
+// SPDX-License-Identifier: Apache-2.0
+/*
+Copyright (C) 2026 The Falco Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package loadertest provides utilities for testing plugins built as shared
+// libraries and loaded with the loader package. This complements the
+// sdktest package, which tests plugins without building them.
+package loadertest
+
+import (
+	"testing"
+
+	"github.com/falcosecurity/plugin-sdk-go/pkg/loader"
+	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk"
+	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk/sdktest"
+)
+
+// AssertGoldenPluginFields is like the AssertGoldenFields function of the
+// sdktest package, but extracts the fields with a plugin loaded with the
+// loader package. The plugin must be initialized, and its fields are
+// extracted with the ExtractBatch method of loader.Plugin. The events are
+// passed to the plugin as events of the event source of the plugin, or of
+// the first of its compatible extraction event sources if it has none.
+// Golden files are regenerated with the -sdktest.update flag.
+func AssertGoldenPluginFields(t testing.TB, path string, p *loader.Plugin, evts []sdk.EventReader, fields ...string) {
+	t.Helper()
+	reqs := make([]loader.ExtractField, len(fields))
+	for i, f := range fields {
+		req, err := newField(p, f)
+		if err != nil {
+			t.Fatalf("invalid field '%s': %s", f, err.Error())
+		}
+		reqs[i] = req
+	}
+
+	source := p.Info().EventSource
+	if len(source) == 0 && len(p.Info().ExtractEventSources) > 0 {
+		source = p.Info().ExtractEventSources[0]
+	}
+	levts := make([]loader.Event, len(evts))
+	for i, evt := range evts {
+		levts[i] = loader.Event{
+			Num:       evt.EventNum(),
+			Timestamp: evt.Timestamp(),
+			Source:    source,
+			Data:      append([]byte(nil), evt.Bytes()...),
+		}
+	}
+	cols, err := p.ExtractBatch(levts, reqs)
+	if err != nil {
+		t.Fatalf("can't extract fields: %s", err.Error())
+	}
+
+	values := make([][]interface{}, len(evts))
+	for i := range evts {
+		values[i] = make([]interface{}, len(fields))
+		for j := range fields {
+			values[i][j] = cols[j].Values[i]
+		}
+	}
+	sdktest.AssertGoldenValues(t, path, evts, fields, values)
+}
+
+// newField returns a request for the given field of the plugin, with the
+// field expressed as in sdktest.ExtractField
+func newField(p *loader.Plugin, field string) (loader.ExtractField, error) {
+	f, err := sdktest.ParseField(p.Fields(), field)
+	if err != nil {
+		return loader.ExtractField{}, err
+	}
+	return loader.ExtractField{
+		Name:       f.Name,
+		ArgKey:     f.ArgKey,
+		ArgIndex:   f.ArgIndex,
+		ArgPresent: f.ArgPresent,
+	}, nil
+}
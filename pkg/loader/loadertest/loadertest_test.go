@@ -0,0 +1,67 @@
+// SPDX-License-Identifier: Apache-2.0
+/*
+Copyright (C) 2026 The Falco Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadertest
+
+import (
+	"bytes"
+	"encoding/gob"
+	"os"
+	"testing"
+
+	"github.com/falcosecurity/plugin-sdk-go/pkg/loader"
+	"github.com/falcosecurity/plugin-sdk-go/pkg/loader/internal/examples"
+	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk"
+	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk/sdktest"
+)
+
+func TestMain(m *testing.M) {
+	code := m.Run()
+	examples.Cleanup()
+	os.Exit(code)
+}
+
+func TestAssertGoldenPluginFields(t *testing.T) {
+	p, err := loader.NewPlugin(examples.Build(t, "full"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(p.Unload)
+	if err := p.Init(`{"start": 1}`); err != nil {
+		t.Fatal(err)
+	}
+
+	var evts []sdk.EventReader
+	for i := uint64(1); i <= 3; i++ {
+		var buf bytes.Buffer
+		if err := gob.NewEncoder(&buf).Encode(i); err != nil {
+			t.Fatal(err)
+		}
+		evts = append(evts, &sdktest.InMemoryEventReader{
+			Buffer:       buf.Bytes(),
+			ValEventNum:  i,
+			ValTimestamp: 1700000000000000000 + i,
+		})
+	}
+	AssertGoldenPluginFields(t, "testdata/fields.json", p, evts,
+		"example.count",
+		"example.countstr",
+		"example.oddcount",
+		"example.evttime",
+		"example.ipv4net",
+	)
+}
@@ -0,0 +1,148 @@
+// SPDX-License-Identifier: Apache-2.0
+/*
+Copyright (C) 2026 The Falco Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loader
+
+/*
+#include "plugin_loader.h"
+#include <stdlib.h>
+
+// note: instance pointers are passed to Go as integers, because plugins can
+// use values that are not valid pointers, which must not be kept in Go
+// pointer types
+static uintptr_t __open(plugin_api* p, ss_plugin_t* s, const char* o, ss_plugin_rc* r)
+{
+    return (uintptr_t)p->open(s, o, r);
+}
+
+static void __close(plugin_api* p, ss_plugin_t* s, uintptr_t h)
+{
+    p->close(s, (ss_instance_t*)h);
+}
+
+static ss_plugin_rc __next_batch(plugin_api* p, ss_plugin_t* s, uintptr_t h, uint32_t *n, ss_plugin_event ***e)
+{
+    return p->next_batch(s, (ss_instance_t*)h, n, e);
+}
+*/
+import "C"
+import (
+	"errors"
+	"fmt"
+	"unsafe"
+
+	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk"
+)
+
+var errInstanceClosed = errors.New("capture instance is closed")
+
+// Instance represents a capture session opened with Plugin.Open
+type Instance struct {
+	p      *Plugin
+	handle C.uintptr_t
+	evtNum uint64
+}
+
+// Open opens a new capture session with the given params, and returns
+// an Instance from which events can be read with NextBatch. The plugin
+// must be initialized and support the event sourcing capability. The
+// returned Instance must be closed with Close.
+func (p *Plugin) Open(params string) (*Instance, error) {
+	p.m.Lock()
+	defer p.m.Unlock()
+	if !p.HasCapSourcing() {
+		return nil, errNoSourcingCap
+	}
+	if p.state == nil {
+		return nil, errNotInitialized
+	}
+
+	cParams := C.CString(params)
+	defer C.free(unsafe.Pointer(cParams))
+	rc := C.ss_plugin_rc(sdk.SSPluginSuccess)
+	handle := C.__open(&p.handle.api, unsafe.Pointer(p.state), cParams, (*C.ss_plugin_rc)(&rc))
+	if rc != C.ss_plugin_rc(sdk.SSPluginSuccess) {
+		return nil, p.failure()
+	}
+	if handle == 0 {
+		return nil, errors.New("plugin returned a NULL capture instance")
+	}
+	return &Instance{p: p, handle: handle}, nil
+}
+
+// Close closes the capture session. Calling Close more than once, or after
+// the plugin has been unloaded, has no effect.
+func (i *Instance) Close() {
+	i.p.m.Lock()
+	defer i.p.m.Unlock()
+	if i.handle != 0 && i.p.state != nil {
+		C.__close(&i.p.handle.api, unsafe.Pointer(i.p.state), i.handle)
+	}
+	i.handle = 0
+}
+
+// NextBatch returns the next batch of events of the capture session. The
+// returned error is sdk.ErrTimeout or sdk.ErrEOF if the plugin reports a
+// timeout or the end of the capture, in which case events can still be
+// returned. Events are copied in Go memory, and are numbered sequentially
+// starting from 1. A non-nil error is returned if the plugin fails, or if
+// it produces malformed events.
+func (i *Instance) NextBatch() ([]Event, error) {
+	i.p.m.Lock()
+	defer i.p.m.Unlock()
+	if i.handle == 0 {
+		return nil, errInstanceClosed
+	}
+	if i.p.state == nil {
+		return nil, errNotInitialized
+	}
+
+	var nevts C.uint32_t
+	var evts **C.ss_plugin_event
+	rc := C.__next_batch(&i.p.handle.api, unsafe.Pointer(i.p.state), i.handle, &nevts, &evts)
+	var err error
+	switch rc {
+	case C.ss_plugin_rc(sdk.SSPluginSuccess):
+	case C.ss_plugin_rc(sdk.SSPluginTimeout):
+		err = sdk.ErrTimeout
+	case C.ss_plugin_rc(sdk.SSPluginEOF):
+		err = sdk.ErrEOF
+	case C.ss_plugin_rc(sdk.SSPluginFailure):
+		return nil, i.p.failure()
+	default:
+		return nil, fmt.Errorf("plugin returned an unexpected code %d", int(rc))
+	}
+	if nevts > 0 && evts == nil {
+		return nil, fmt.Errorf("plugin returned %d events with a NULL array", int(nevts))
+	}
+
+	res := make([]Event, 0, int(nevts))
+	for n, evt := range unsafe.Slice(evts, int(nevts)) {
+		data, verr := sdk.ReadPluginEvent(unsafe.Pointer(evt), i.p.info.ID)
+		if verr != nil {
+			return nil, fmt.Errorf("plugin returned a malformed event at index %d: %s", n, verr.Error())
+		}
+		i.evtNum++
+		res = append(res, Event{
+			Num:       i.evtNum,
+			Timestamp: uint64(evt.ts),
+			Source:    i.p.info.EventSource,
+			Data:      data,
+		})
+	}
+	return res, err
+}
@@ -66,6 +66,13 @@ type ProgressBuffer interface {
 	ProgressBuffer() StringBuffer
 }
 
+// MetricsBuffer is an interface wrapping the basic MetricsBuffer method.
+// MetricsBuffer returns a MetricBuffer meant to be used as buffer for
+// plugin_get_metrics().
+type MetricsBuffer interface {
+	MetricsBuffer() MetricBuffer
+}
+
 // OpenParamsBuffer is an interface wrapping the basic OpenParamsBuffer method.
 // OpenParamsBuffer returns a StringBuffer meant to be used as buffer for
 // plugin_list_open_params().
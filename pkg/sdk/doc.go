@@ -55,4 +55,9 @@ limitations under the License.
 // plugins. The SDK describes the behavior of plugins as a set of minimal and
 // composable interfaces, to be used flexibly in other packages.
 //
+// Moreover, the "sdk/sdktest" package provides utilities for testing plugins
+// without building them as shared libraries, such as in-memory
+// implementations of the interfaces of the "sdk" package, fuzzing helpers,
+// and a harness driving plugins through their C symbols within "go test".
+//
 package sdk
@@ -23,8 +23,13 @@ package sdk
 */
 import "C"
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"io"
+	"math"
+	"sync/atomic"
+	"time"
 	"unsafe"
 
 	"github.com/falcosecurity/plugin-sdk-go/pkg/ptr"
@@ -44,6 +49,38 @@ const pluginEventCode = 322
 // plus 4 bytes for the plugin ID.
 const PluginEventPayloadOffset = C.sizeof_ss_plugin_event + 4 + 4 + 4
 
+// ReadPluginEvent checks that evt points to a well-formed ss_plugin_event
+// C structure of a plugin event, as produced by the plugin with the given
+// ID, and returns a copy of its data. Events with a zero plugin ID are
+// accepted, as the ID is then assigned by the framework. This is meant for
+// tools that drive plugins through the C symbols they export.
+func ReadPluginEvent(evt unsafe.Pointer, pluginID uint32) ([]byte, error) {
+	if evt == nil {
+		return nil, errors.New("NULL event")
+	}
+	// note: nparams is unaligned, so it is not accessible from Go
+	u32 := func(off uintptr) uint32 { return *(*uint32)(unsafe.Pointer(uintptr(evt) + off)) }
+	hdr := (*C.ss_plugin_event)(evt)
+	evtLen, nparams := u32(16), u32(22)
+	if hdr._type != pluginEventCode {
+		return nil, fmt.Errorf("event type is %d, but should be %d", hdr._type, pluginEventCode)
+	}
+	if nparams != 2 {
+		return nil, fmt.Errorf("event has %d params, but should have 2", nparams)
+	}
+	idLen, dataLen := u32(C.sizeof_ss_plugin_event), u32(C.sizeof_ss_plugin_event+4)
+	if idLen != 4 {
+		return nil, fmt.Errorf("plugin ID param has length %d, but should be 4", idLen)
+	}
+	if uint64(evtLen) != uint64(PluginEventPayloadOffset)+uint64(dataLen) {
+		return nil, fmt.Errorf("event length is %d, but should be %d", evtLen, uint64(PluginEventPayloadOffset)+uint64(dataLen))
+	}
+	if id := u32(C.sizeof_ss_plugin_event + 8); id != 0 && id != pluginID {
+		return nil, fmt.Errorf("event has plugin ID %d, but should have %d", id, pluginID)
+	}
+	return C.GoBytes(unsafe.Pointer(uintptr(evt)+PluginEventPayloadOffset), C.int(dataLen)), nil
+}
+
 // EventWriter can be used to represent events produced by a plugin.
 // This interface is meant to be used in the next/next_batch.
 //
@@ -62,8 +99,51 @@ type EventWriter interface {
 	// any data written inside the event would be erased.
 	Writer() io.Writer
 	//
-	// SetTimestamp sets the timestamp of the event.
+	// SetTimestamp sets the timestamp of the event, in nanoseconds since
+	// the epoch. UnsetTimestamp lets the framework assign the timestamp
+	// automatically. Timestamp can be used to convert a time.Time.
+	// The timestamp is not cleared by Writer, so writers reused across
+	// batches keep the one of their previous event until it's set again.
 	SetTimestamp(value uint64)
+	//
+	// SetTID sets the ID of the thread to which the event is related.
+	// UnsetTID indicates that the event is not related to any thread.
+	// Just like the timestamp, the thread ID is not cleared by Writer.
+	SetTID(value uint64)
+}
+
+const (
+	// UnsetTimestamp is the timestamp of events for which no timestamp is
+	// set, which is assigned automatically by the framework.
+	UnsetTimestamp uint64 = math.MaxUint64
+	//
+	// UnsetTID is the thread ID of events not related to any thread.
+	UnsetTID uint64 = math.MaxUint64
+)
+
+// Timestamp returns the event timestamp representing t, in nanoseconds
+// since the epoch. The zero time is represented by UnsetTimestamp.
+func Timestamp(t time.Time) uint64 {
+	if t.IsZero() {
+		return UnsetTimestamp
+	}
+	return uint64(t.UnixNano())
+}
+
+// ResizableEventWriter is an EventWriter whose maximum data size can be
+// changed. The EventWriter instances of the EventWriters created with
+// NewEventWriters implement this interface.
+type ResizableEventWriter interface {
+	EventWriter
+	//
+	// DataSize returns the maximum data size of the event.
+	DataSize() int64
+	//
+	// Resize changes the maximum data size of the event by reallocating its
+	// memory, and clears the event data. Resizing is only safe while the
+	// framework is not reading the event, which is the case while the event
+	// batch is being filled during plugin_next_batch.
+	Resize(dataSize int64) error
 }
 
 // EventReader can be used to represent events passed by the framework
@@ -85,6 +165,15 @@ type EventReader interface {
 	// open for seek-related optimizations, which could be useful in the
 	// field extraction use case.
 	Reader() io.ReadSeeker
+	//
+	// Bytes returns a slice pointing to the event data, without copying it.
+	// This is the fastest way to read from the event data, but it is unsafe
+	// if misused: the returned slice is only valid for the duration of the
+	// plugin framework call in which the event is received (e.g. the
+	// extraction of the event fields), and must not be modified.
+	// Retaining or writing in the slice leads to undefined behavior.
+	// Lifetime misuses can be debugged with SetEventReaderChecks.
+	Bytes() []byte
 }
 
 // EventWriters represent a list of sdk.EventWriter to be used inside
@@ -132,6 +221,15 @@ type eventWriters struct {
 	evtPtrs **C.ss_plugin_event
 }
 
+// checkDataSize returns a non-nil error if dataSize is not a valid
+// data size for an event
+func checkDataSize(dataSize int64) error {
+	if dataSize < 0 || dataSize > C.UINT32_MAX || int64(int(dataSize)) != dataSize {
+		return fmt.Errorf("invalid dataSize: %d", dataSize)
+	}
+	return nil
+}
+
 // NewEventWriters creates a new instance of sdk.EventWriters.
 // The size argument indicates the length of the list, which is the amount
 // of events contained. Then dataSize argument indicates the maximum data
@@ -140,25 +238,67 @@ func NewEventWriters(size, dataSize int64) (EventWriters, error) {
 	if size < 1 {
 		return nil, fmt.Errorf("invalid size: %d", size)
 	}
-	if dataSize < 0 || dataSize > C.UINT32_MAX {
-		return nil, fmt.Errorf("invalid dataSize: %d", dataSize)
+	if err := checkDataSize(dataSize); err != nil {
+		return nil, err
 	}
-
-	ret := &eventWriters{
-		evts:    make([]*eventWriter, size),
-		evtPtrs: (**C.ss_plugin_event)(C.malloc((C.size_t)(size * C.sizeof_uintptr_t))),
+	ret := newEventWriters(size)
+	for i := range ret.evts {
+		ret.evts[i] = &eventWriter{evtPtr: ret.evtPtr(i)}
+		if err := ret.evts[i].alloc(dataSize); err != nil {
+			ret.Free()
+			return nil, err
+		}
 	}
+	return ret, nil
+}
 
-	var err error
+// NewGrowableEventWriters creates a new instance of sdk.EventWriters in which
+// the memory of each event is allocated lazily, and grows on demand.
+// The size argument indicates the length of the list, which is the amount
+// of events contained. The initDataSize argument indicates the data size for
+// which the memory of each event is allocated the first time it gets written,
+// and the maxDataSize argument indicates the maximum data size of each event.
+// The memory of an event grows whenever the data written inside it does not
+// fit in the current allocation, until maxDataSize is reached. This allows
+// memory consumption to scale with the actual size of the events produced.
+//
+// Until an event is written for the first time, the pointer to it in the C
+// array returned by ArrayPtr is nil. This is not an issue for the framework,
+// which only reads the events that have been returned by plugin_next_batch.
+func NewGrowableEventWriters(size, initDataSize, maxDataSize int64) (EventWriters, error) {
+	if size < 1 {
+		return nil, fmt.Errorf("invalid size: %d", size)
+	}
+	if err := checkDataSize(maxDataSize); err != nil {
+		return nil, err
+	}
+	if initDataSize < 0 || initDataSize > maxDataSize {
+		return nil, fmt.Errorf("invalid initDataSize: %d", initDataSize)
+	}
+	ret := newEventWriters(size)
 	for i := range ret.evts {
-		if ret.evts[i], err = newEventWriter(dataSize); err != nil {
-			return nil, err
+		ret.evts[i] = &eventWriter{
+			evtPtr:      ret.evtPtr(i),
+			dataSize:    initDataSize,
+			growable:    true,
+			maxDataSize: maxDataSize,
 		}
-		*(**C.ss_plugin_event)(unsafe.Pointer(uintptr(unsafe.Pointer(ret.evtPtrs)) + uintptr(i*C.sizeof_uintptr_t))) = ret.evts[i].ssPluginEvt
 	}
 	return ret, nil
 }
 
+func newEventWriters(size int64) *eventWriters {
+	return &eventWriters{
+		evts:    make([]*eventWriter, size),
+		evtPtrs: (**C.ss_plugin_event)(C.calloc((C.size_t)(size), C.sizeof_uintptr_t)),
+	}
+}
+
+// evtPtr returns a pointer to the i-th entry of the C array of events
+func (p *eventWriters) evtPtr(i int) **C.ss_plugin_event {
+	return (**C.ss_plugin_event)(unsafe.Pointer(uintptr(unsafe.Pointer(p.evtPtrs)) + uintptr(i*C.sizeof_uintptr_t)))
+}
+
 func (p *eventWriters) Get(eventIndex int) EventWriter {
 	return p.evts[eventIndex]
 }
@@ -169,7 +309,9 @@ func (p *eventWriters) Len() int {
 
 func (p *eventWriters) Free() {
 	for _, pe := range p.evts {
-		pe.free()
+		if pe != nil {
+			pe.free()
+		}
 	}
 	C.free( /*(*C.ss_plugin_event)*/ p.ArrayPtr())
 }
@@ -182,10 +324,21 @@ type eventWriter struct {
 	data        ptr.BytesReadWriter
 	dataSize    int64
 	ssPluginEvt *C.ss_plugin_event
+	// Pointer to the entry of the C array of the owning eventWriters
+	// that points to ssPluginEvt
+	evtPtr **C.ss_plugin_event
+	// If true, ssPluginEvt is allocated lazily and grows on demand
+	// until its data size reaches maxDataSize
+	growable    bool
+	maxDataSize int64
 }
 
-func newEventWriter(dataSize int64) (*eventWriter, error) {
+// alloc allocates a new empty plugin event with room for dataSize bytes
+func (p *eventWriter) alloc(dataSize int64) error {
 	evt := (*C.ss_plugin_event)(C.calloc(1, C.size_t(dataSize+PluginEventPayloadOffset)))
+	if evt == nil {
+		return fmt.Errorf("could not allocate event with dataSize: %d", dataSize)
+	}
 	evt._type = pluginEventCode
 	evt.ts = C.uint64_t(C.UINT64_MAX)
 	evt.tid = C.uint64_t(C.UINT64_MAX)
@@ -199,17 +352,52 @@ func newEventWriter(dataSize int64) (*eventWriter, error) {
 	*(*C.uint32_t)(unsafe.Pointer(uintptr(unsafe.Pointer(evt)) + C.sizeof_ss_plugin_event + 4)) = 0
 	// plugin ID value (note: putting zero makes the framework set it automatically)
 	*(*C.uint32_t)(unsafe.Pointer(uintptr(unsafe.Pointer(evt)) + C.sizeof_ss_plugin_event + 8)) = 0
-	// create a read/writer for the data payload
-	brw, err := ptr.NewBytesReadWriter(unsafe.Pointer(uintptr(unsafe.Pointer(evt))+PluginEventPayloadOffset), int64(dataSize), int64(dataSize))
+	p.set(evt, dataSize)
+	return nil
+}
+
+// realloc changes the data size of the plugin event, preserving its
+// header, its data, and the current write offset
+func (p *eventWriter) realloc(dataSize int64) error {
+	offset := p.data.Offset()
+	evt := (*C.ss_plugin_event)(C.realloc(unsafe.Pointer(p.ssPluginEvt), C.size_t(dataSize+PluginEventPayloadOffset)))
+	if evt == nil {
+		return fmt.Errorf("could not allocate event with dataSize: %d", dataSize)
+	}
+	p.set(evt, dataSize)
+	if offset > dataSize {
+		offset = dataSize
+		p.ssPluginEvt.len = (C.uint32_t)(PluginEventPayloadOffset + dataSize)
+		*p.dataLenPtr() = C.uint32_t(dataSize)
+	}
+	p.data.Seek(offset, io.SeekStart)
+	return nil
+}
+
+// set makes the writer use the given plugin event, with room for dataSize
+// bytes of data
+func (p *eventWriter) set(evt *C.ss_plugin_event, dataSize int64) {
+	// note: this can't fail, because the data size is checked by the
+	// public constructors and methods
+	brw, err := ptr.NewBytesReadWriter(unsafe.Pointer(uintptr(unsafe.Pointer(evt))+PluginEventPayloadOffset), dataSize, dataSize)
 	if err != nil {
-		return nil, err
+		panic(fmt.Sprintf("plugin-sdk-go/sdk: %s", err.Error()))
 	}
+	p.ssPluginEvt = evt
+	p.data = brw
+	p.dataSize = dataSize
+	if p.evtPtr != nil {
+		*p.evtPtr = evt
+	}
+}
 
-	return &eventWriter{
-		ssPluginEvt: evt,
-		data:        brw,
-		dataSize:    dataSize,
-	}, nil
+// ensureAlloc allocates the plugin event if it was not allocated yet
+func (p *eventWriter) ensureAlloc() {
+	if p.ssPluginEvt == nil {
+		if err := p.alloc(p.dataSize); err != nil {
+			panic(fmt.Sprintf("plugin-sdk-go/sdk: %s", err.Error()))
+		}
+	}
 }
 
 func (p *eventWriter) dataLenPtr() *C.uint32_t {
@@ -217,6 +405,7 @@ func (p *eventWriter) dataLenPtr() *C.uint32_t {
 }
 
 func (p *eventWriter) Writer() io.Writer {
+	p.ensureAlloc()
 	p.data.SetLen(p.dataSize)
 	p.data.Seek(0, io.SeekStart)
 	p.ssPluginEvt.len = (C.uint32_t)(PluginEventPayloadOffset)
@@ -225,6 +414,21 @@ func (p *eventWriter) Writer() io.Writer {
 }
 
 func (p *eventWriter) Write(data []byte) (n int, err error) {
+	// grow the event if the data does not fit
+	if p.growable && p.dataSize < p.maxDataSize {
+		if need := p.data.Offset() + int64(len(data)); need > p.dataSize {
+			size := p.dataSize * 2
+			if size < need {
+				size = need
+			}
+			if size > p.maxDataSize {
+				size = p.maxDataSize
+			}
+			if err = p.realloc(size); err != nil {
+				return
+			}
+		}
+	}
 	n, err = p.data.Write(data)
 	if err != nil {
 		return
@@ -235,32 +439,138 @@ func (p *eventWriter) Write(data []byte) (n int, err error) {
 }
 
 func (p *eventWriter) SetTimestamp(value uint64) {
+	// events are allocated with an unset timestamp, so there's no need
+	// to allocate them just for resetting it
+	if p.ssPluginEvt == nil && value == UnsetTimestamp {
+		return
+	}
+	p.ensureAlloc()
 	(*C.ss_plugin_event)(p.ssPluginEvt).ts = C.uint64_t(value)
 }
 
+func (p *eventWriter) SetTID(value uint64) {
+	if p.ssPluginEvt == nil && value == UnsetTID {
+		return
+	}
+	p.ensureAlloc()
+	(*C.ss_plugin_event)(p.ssPluginEvt).tid = C.uint64_t(value)
+}
+
+func (p *eventWriter) DataSize() int64 {
+	if p.growable {
+		return p.maxDataSize
+	}
+	return p.dataSize
+}
+
+func (p *eventWriter) Resize(dataSize int64) error {
+	if err := checkDataSize(dataSize); err != nil {
+		return err
+	}
+	if p.growable {
+		// the memory is reallocated lazily in case of growth
+		p.maxDataSize = dataSize
+		if p.ssPluginEvt == nil || p.dataSize <= dataSize {
+			if p.ssPluginEvt != nil {
+				p.Writer()
+			}
+			return nil
+		}
+	}
+	p.ensureAlloc()
+	p.Writer()
+	return p.realloc(dataSize)
+}
+
 func (p *eventWriter) free() {
 	C.free(unsafe.Pointer(p.ssPluginEvt))
+	p.ssPluginEvt = nil
 	p.data = nil
 }
 
 type eventReader C.ss_plugin_event_input
 
+// eventReaderChecks is non-zero if the checked mode of the EventReaders
+// returned by NewEventReader is enabled
+var eventReaderChecks int32
+
+// eventReaderPoison is the byte used to overwrite the data returned by
+// Bytes in checked mode, once the EventReader is released
+const eventReaderPoison = 0xDD
+
+// SetEventReaderChecks enables or disables the checked mode of the
+// EventReaders returned by NewEventReader, which is disabled by default.
+//
+// This is meant for debugging lifetime misuses of the slice returned by the
+// Bytes method of EventReader. In checked mode, Bytes returns a copy of the
+// event data. Once the plugin framework call in which the event is received
+// returns, the SDK panics if the copy has been modified, and overwrites it
+// with a 0xDD pattern so that retained slices do not silently read stale
+// data. The checked mode has a performance cost, and should not be enabled
+// in production.
+func SetEventReaderChecks(enable bool) {
+	if enable {
+		atomic.StoreInt32(&eventReaderChecks, 1)
+	} else {
+		atomic.StoreInt32(&eventReaderChecks, 0)
+	}
+}
+
+// EventReaderChecks returns true if the checked mode of the EventReaders
+// returned by NewEventReader is enabled, and false otherwise.
+func EventReaderChecks() bool {
+	return atomic.LoadInt32(&eventReaderChecks) != 0
+}
+
 // NewEventReader wraps a pointer to a ss_plugin_event_input C structure to create
 // a new instance of EventReader. It's not possible to check that the pointer is valid.
 // Passing an invalid pointer may cause undefined behavior.
+//
+// The returned EventReader should be passed to ReleaseEventReader once the
+// plugin framework call in which the event is received returns.
 func NewEventReader(ssPluginEvtInput unsafe.Pointer) EventReader {
+	if EventReaderChecks() {
+		return &checkedEventReader{eventReader: (*eventReader)(ssPluginEvtInput)}
+	}
 	return (*eventReader)(ssPluginEvtInput)
 }
 
-func (e *eventReader) Reader() io.ReadSeeker {
+// ReleaseEventReader notifies that an EventReader returned by NewEventReader
+// will not be used anymore. In checked mode, this panics if the data returned
+// by the Bytes method has been modified, and then poisons it.
+// See SetEventReaderChecks.
+func ReleaseEventReader(evt EventReader) {
+	if c, ok := evt.(*checkedEventReader); ok {
+		c.release()
+	}
+}
+
+func (e *eventReader) checkType() {
 	if e.evt._type != pluginEventCode {
 		panic(fmt.Sprintf("plugin-sdk-go/sdk: reveived extraction request for non-plugin event (code=%d)", e.evt._type))
 	}
-	datalen := *(*C.uint32_t)(unsafe.Pointer(uintptr(unsafe.Pointer(e.evt)) + C.sizeof_ss_plugin_event + 4))
-	brw, _ := ptr.NewBytesReadWriter(unsafe.Pointer(uintptr(unsafe.Pointer(e.evt))+PluginEventPayloadOffset), int64(datalen), int64(datalen))
+}
+
+func (e *eventReader) dataLen() C.uint32_t {
+	return *(*C.uint32_t)(unsafe.Pointer(uintptr(unsafe.Pointer(e.evt)) + C.sizeof_ss_plugin_event + 4))
+}
+
+func (e *eventReader) dataPtr() unsafe.Pointer {
+	return unsafe.Pointer(uintptr(unsafe.Pointer(e.evt)) + PluginEventPayloadOffset)
+}
+
+func (e *eventReader) Reader() io.ReadSeeker {
+	e.checkType()
+	datalen := e.dataLen()
+	brw, _ := ptr.NewBytesReadWriter(e.dataPtr(), int64(datalen), int64(datalen))
 	return brw
 }
 
+func (e *eventReader) Bytes() []byte {
+	e.checkType()
+	return unsafe.Slice((*byte)(e.dataPtr()), int(e.dataLen()))
+}
+
 func (e *eventReader) Timestamp() uint64 {
 	return uint64(e.evt.ts)
 }
@@ -268,3 +578,30 @@ func (e *eventReader) Timestamp() uint64 {
 func (e *eventReader) EventNum() uint64 {
 	return uint64(e.evtnum)
 }
+
+// checkedEventReader is the EventReader returned by NewEventReader
+// in checked mode
+type checkedEventReader struct {
+	*eventReader
+	data []byte
+}
+
+func (c *checkedEventReader) Bytes() []byte {
+	if c.data == nil {
+		c.data = append([]byte{}, c.eventReader.Bytes()...)
+	}
+	return c.data
+}
+
+func (c *checkedEventReader) release() {
+	if c.data == nil {
+		return
+	}
+	if !bytes.Equal(c.data, c.eventReader.Bytes()) {
+		panic(fmt.Sprintf("plugin-sdk-go/sdk: data returned by EventReader.Bytes() has been modified (evtnum=%d)", c.EventNum()))
+	}
+	for i := range c.data {
+		c.data[i] = eventReaderPoison
+	}
+	c.data = nil
+}
@@ -18,6 +18,7 @@ limitations under the License.
 package sdk
 
 import (
+	"bytes"
 	"encoding/json"
 	"io"
 	"testing"
@@ -152,3 +153,231 @@ func TestEventWriterEventReader(t *testing.T) {
 
 	writers.Free()
 }
+
+func TestReadPluginEvent(t *testing.T) {
+	data := []byte("hello world")
+	writers, err := NewEventWriters(1, int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer writers.Free()
+	if _, err := writers.Get(0).Writer().Write(data); err != nil {
+		t.Fatal(err)
+	}
+	evt := *(**_Ctype_struct_ss_plugin_event)(writers.ArrayPtr())
+	res, err := ReadPluginEvent(unsafe.Pointer(evt), 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(res) != string(data) {
+		t.Errorf("expected %s, but found %s", string(data), string(res))
+	}
+
+	// malformed events are rejected
+	idPtr := (*uint32)(unsafe.Pointer(uintptr(unsafe.Pointer(evt)) + PluginEventPayloadOffset - 4))
+	*idPtr = 6
+	if _, err := ReadPluginEvent(unsafe.Pointer(evt), 5); err == nil {
+		t.Errorf("expected error for wrong plugin ID")
+	}
+	*idPtr = 0
+	evt.len++
+	if _, err := ReadPluginEvent(unsafe.Pointer(evt), 5); err == nil {
+		t.Errorf("expected error for wrong event length")
+	}
+	evt.len--
+	evt._type = 1
+	if _, err := ReadPluginEvent(unsafe.Pointer(evt), 5); err == nil {
+		t.Errorf("expected error for wrong event type")
+	}
+	if _, err := ReadPluginEvent(nil, 5); err == nil {
+		t.Errorf("expected error for NULL event")
+	}
+}
+
+func TestEventReaderBytes(t *testing.T) {
+	data := []byte("hello world")
+	writers, err := NewEventWriters(1, int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer writers.Free()
+	writers.Get(0).Writer().Write(data)
+	evtInput := &_Ctype_struct_ss_plugin_event_input{evt: *(**_Ctype_struct_ss_plugin_event)(writers.ArrayPtr())}
+
+	// unchecked mode returns a view over the C payload
+	evtReader := NewEventReader(unsafe.Pointer(evtInput))
+	res := evtReader.Bytes()
+	if !bytes.Equal(res, data) {
+		t.Errorf("expected %v, but found %v", data, res)
+	}
+	if unsafe.Pointer(&res[0]) != unsafe.Pointer(uintptr(unsafe.Pointer(evtInput.evt))+PluginEventPayloadOffset) {
+		t.Errorf("expected Bytes to point to the event payload")
+	}
+	ReleaseEventReader(evtReader)
+
+	// checked mode returns a copy, which gets poisoned once released
+	SetEventReaderChecks(true)
+	defer SetEventReaderChecks(false)
+	evtReader = NewEventReader(unsafe.Pointer(evtInput))
+	res = evtReader.Bytes()
+	if !bytes.Equal(res, data) {
+		t.Errorf("expected %v, but found %v", data, res)
+	}
+	ReleaseEventReader(evtReader)
+	for i, b := range res {
+		if b != eventReaderPoison {
+			t.Errorf("expected byte #%d to be poisoned, but found %d", i, b)
+		}
+	}
+
+	// checked mode detects writes
+	evtReader = NewEventReader(unsafe.Pointer(evtInput))
+	evtReader.Bytes()[0] = 0
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("expected panic")
+		}
+	}()
+	ReleaseEventReader(evtReader)
+}
+
+func TestEventWriterResize(t *testing.T) {
+	data := []byte("hello world")
+	writers, err := NewEventWriters(2, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer writers.Free()
+
+	w, ok := writers.Get(1).(ResizableEventWriter)
+	if !ok {
+		t.Fatalf("expected event writer to implement ResizableEventWriter")
+	}
+	if w.DataSize() != 4 {
+		t.Errorf("expected %d, but found %d", 4, w.DataSize())
+	}
+	if n, err := w.Writer().Write(data); err != io.ErrShortWrite || n != 4 {
+		t.Errorf("expected %d and io.ErrShortWrite, but found %d and %v", 4, n, err)
+	}
+	if err := w.Resize(int64(len(data))); err != nil {
+		t.Fatal(err)
+	}
+	if w.DataSize() != int64(len(data)) {
+		t.Errorf("expected %d, but found %d", len(data), w.DataSize())
+	}
+	if n, err := w.Writer().Write(data); err != nil || n != len(data) {
+		t.Errorf("expected %d and no error, but found %d and %v", len(data), n, err)
+	}
+	w.SetTimestamp(5)
+
+	// the C array must point to the reallocated event
+	evtPtr := (**_Ctype_struct_ss_plugin_event)(unsafe.Pointer(uintptr(writers.ArrayPtr()) + unsafe.Sizeof(uintptr(0))))
+	evtReader := NewEventReader(unsafe.Pointer(&_Ctype_struct_ss_plugin_event_input{evt: *evtPtr}))
+	if !bytes.Equal(evtReader.Bytes(), data) || evtReader.Timestamp() != 5 {
+		t.Errorf("expected %v and timestamp %d, but found %v and %d", data, 5, evtReader.Bytes(), evtReader.Timestamp())
+	}
+	if err := w.Resize(-1); err == nil {
+		t.Errorf("expected error")
+	}
+}
+
+func TestGrowableEventWriters(t *testing.T) {
+	writers, err := NewGrowableEventWriters(2, 4, 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer writers.Free()
+	evtPtr := func(i int) *_Ctype_struct_ss_plugin_event {
+		return *(**_Ctype_struct_ss_plugin_event)(unsafe.Add(writers.ArrayPtr(), i*int(unsafe.Sizeof(uintptr(0)))))
+	}
+	readData := func(i int) []byte {
+		return NewEventReader(unsafe.Pointer(&_Ctype_struct_ss_plugin_event_input{evt: evtPtr(i)})).Bytes()
+	}
+
+	// events are allocated lazily
+	if evtPtr(0) != nil || evtPtr(1) != nil {
+		t.Fatalf("expected events not to be allocated")
+	}
+	w := writers.Get(0).Writer()
+	if evtPtr(0) == nil || evtPtr(1) != nil {
+		t.Fatalf("expected only the first event to be allocated")
+	}
+
+	// events grow on demand, until the maximum data size
+	for _, s := range []string{"hello", " world"} {
+		if n, err := w.Write([]byte(s)); err != nil || n != len(s) {
+			t.Fatalf("expected %d and no error, but found %d and %v", len(s), n, err)
+		}
+	}
+	if string(readData(0)) != "hello world" {
+		t.Errorf("expected %s, but found %s", "hello world", string(readData(0)))
+	}
+	data := []byte("0123456789abcdefghij")
+	if n, err := writers.Get(0).Writer().Write(data); err != io.ErrShortWrite || n != 16 {
+		t.Errorf("expected %d and io.ErrShortWrite, but found %d and %v", 16, n, err)
+	}
+
+	// the maximum data size can be changed
+	r := writers.Get(0).(ResizableEventWriter)
+	if r.DataSize() != 16 {
+		t.Errorf("expected %d, but found %d", 16, r.DataSize())
+	}
+	if err := r.Resize(32); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := r.Writer().Write(data); err != nil || n != len(data) {
+		t.Fatalf("expected %d and no error, but found %d and %v", len(data), n, err)
+	}
+	if !bytes.Equal(readData(0), data) {
+		t.Errorf("expected %s, but found %s", string(data), string(readData(0)))
+	}
+
+	if _, err := NewGrowableEventWriters(1, 8, 4); err == nil {
+		t.Errorf("expected error")
+	}
+}
+
+func BenchmarkGrowableEventWritersNextBatch(b *testing.B) {
+	writers, err := NewGrowableEventWriters(int64(DefaultBatchSize), 0, int64(DefaultEvtSize))
+	if err != nil {
+		println(err.Error())
+		b.Fail()
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for j := 0; j < writers.Len(); j++ {
+			err := nextCallback(nil, nil, writers.Get(j))
+			if err != nil {
+				println(err.Error())
+				b.Fail()
+			}
+		}
+	}
+}
+
+func TestEventWriterSetTID(t *testing.T) {
+	writers, err := NewEventWriters(1, int64(DefaultEvtSize))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer writers.Free()
+	evt := *(**_Ctype_struct_ss_plugin_event)(writers.ArrayPtr())
+	if uint64(evt.tid) != UnsetTID {
+		t.Errorf("expected %d, but found %d", UnsetTID, uint64(evt.tid))
+	}
+	writers.Get(0).SetTID(5)
+	if uint64(evt.tid) != 5 {
+		t.Errorf("expected %d, but found %d", 5, uint64(evt.tid))
+	}
+}
+
+func TestTimestamp(t *testing.T) {
+	if Timestamp(time.Time{}) != UnsetTimestamp {
+		t.Errorf("expected %d, but found %d", UnsetTimestamp, Timestamp(time.Time{}))
+	}
+	now := time.Now()
+	if Timestamp(now) != uint64(now.UnixNano()) {
+		t.Errorf("expected %d, but found %d", uint64(now.UnixNano()), Timestamp(now))
+	}
+}
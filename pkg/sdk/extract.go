@@ -99,7 +99,7 @@ type ExtractRequest interface {
 	// of them, in case IsList() returns true):
 	//  - sdk.FieldTypeBool: bool
 	//  - sdk.FieldTypeUint64: uint64
-	//  - sdk.FieldTypeCharBuf: string
+	//  - sdk.FieldTypeCharBuf: string, []byte
 	//  - sdk.FieldTypeRelTime: time.Duration, *time.Duration
 	//  - sdk.FieldTypeAbsTime: time.Time, *time.Time
 	//  - sdk.FieldTypeIPAddr: net.IP, *net.IP
@@ -128,6 +128,30 @@ type ExtractRequest interface {
 	WantOffset() bool
 }
 
+// StringBytesExtractRequest is an ExtractRequest that can set the values of
+// FieldTypeCharBuf fields pointing into the payload of the event, without
+// copying them. The ExtractRequest instances of the ExtractRequestPool
+// created with NewExtractRequestPool implement this interface.
+type StringBytesExtractRequest interface {
+	ExtractRequest
+	//
+	// SetStringBytes sets the extracted value for a FieldTypeCharBuf field
+	// from a byte slice, and is equivalent to calling SetValue with it.
+	// If v is a sub-slice of the payload of the event set with SetEventPtr
+	// (such as one obtained from the Bytes method of EventReader), the
+	// value offsets are set automatically. Moreover, if v is also directly
+	// followed by a null terminator in the payload, the value points to
+	// the event memory and no copy is performed. Otherwise, the contents
+	// of v are copied as in SetValue.
+	SetStringBytes(v []byte)
+	//
+	// SetEventPtr sets a pointer to the ss_plugin_event_input C structure
+	// of the event for which the extraction is requested. This is used by
+	// SetStringBytes to recognize values pointing into the event payload.
+	// A nil pointer means that the event is unknown.
+	SetEventPtr(unsafe.Pointer)
+}
+
 // ExtractRequestPool represents a pool of reusable ExtractRequest objects.
 // Each ExtractRequest can be reused by calling its SetPtr method to wrap
 // a new ss_plugin_extract_field C structure pointer.
@@ -226,6 +250,8 @@ func NewExtractRequestPool() ExtractRequestPool {
 
 type extractRequest struct {
 	req *C.ss_plugin_extract_field
+	// Pointer to the event for which the extraction is requested
+	evt *C.ss_plugin_event_input
 	// Pointer to the field's offset
 	resOffsetStart *C.uint32_t
 	// Pointer to the field's length
@@ -246,6 +272,10 @@ func (e *extractRequest) SetPtr(pef unsafe.Pointer) {
 	e.req = (*C.ss_plugin_extract_field)(pef)
 }
 
+func (e *extractRequest) SetEventPtr(evt unsafe.Pointer) {
+	e.evt = (*C.ss_plugin_event_input)(evt)
+}
+
 func (e *extractRequest) SetOffsetPtrs(startPtr, lengthPtr unsafe.Pointer) {
 	e.resOffsetStart = (*C.uint32_t)(startPtr)
 	e.resOffsetLength = (*C.uint32_t)(lengthPtr)
@@ -324,17 +354,26 @@ func (e *extractRequest) SetValue(v interface{}) {
 		}
 	case FieldTypeCharBuf:
 		if e.IsList() {
-			for i, out := range e.resizeResValPtrs(len(v.([]string)), C.sizeof_uintptr_t) {
-				if len(e.resStrBufs) <= i {
-					e.resStrBufs = append(e.resStrBufs, &ptr.StringBuffer{})
+			if val, ok := v.([][]byte); ok {
+				for i, out := range e.resizeResValPtrs(len(val), C.sizeof_uintptr_t) {
+					e.setStringBytes(i, out, val[i])
+				}
+			} else {
+				for i, out := range e.resizeResValPtrs(len(v.([]string)), C.sizeof_uintptr_t) {
+					e.strBuf(i).Write(v.([]string)[i])
+					*((**C.char)(out)) = (*C.char)(e.resStrBufs[i].CharPtr())
 				}
-				e.resStrBufs[i].Write(v.([]string)[i])
-				*((**C.char)(out)) = (*C.char)(e.resStrBufs[i].CharPtr())
 			}
 		} else {
 			out := e.resizeResValPtrs(1, C.sizeof_uintptr_t)[0]
-			e.resStrBufs[0].Write(v.(string))
-			*((**C.char)(out)) = (*C.char)(e.resStrBufs[0].CharPtr())
+			if val, ok := v.([]byte); ok {
+				if off, ok := e.setStringBytes(0, out, val); ok {
+					e.SetValueOffset(PluginEventPayloadOffset+off, uint32(len(val)))
+				}
+			} else {
+				e.resStrBufs[0].Write(v.(string))
+				*((**C.char)(out)) = (*C.char)(e.resStrBufs[0].CharPtr())
+			}
 		}
 	case FieldTypeRelTime:
 		if e.IsList() {
@@ -432,6 +471,68 @@ func (e *extractRequest) SetValue(v interface{}) {
 	*((*C.uintptr_t)(unsafe.Pointer(&e.req.res))) = *(*C.uintptr_t)(unsafe.Pointer(&e.resBuf))
 }
 
+func (e *extractRequest) SetStringBytes(v []byte) {
+	if e.FieldType() != FieldTypeCharBuf || e.IsList() {
+		panic("plugin-sdk-go/sdk: called SetStringBytes on a field that is not a non-list string")
+	}
+	e.SetValue(v)
+}
+
+// strBuf returns the i-th StringBuffer used to return string results,
+// allocating it if necessary
+func (e *extractRequest) strBuf(i int) StringBuffer {
+	for len(e.resStrBufs) <= i {
+		e.resStrBufs = append(e.resStrBufs, &ptr.StringBuffer{})
+	}
+	return e.resStrBufs[i]
+}
+
+// payload returns a view over the payload of the event set with
+// SetEventPtr, or nil if the event is unknown or is not a plugin event
+func (e *extractRequest) payload() []byte {
+	if e.evt == nil || e.evt.evt == nil {
+		return nil
+	}
+	evt := (*eventReader)(unsafe.Pointer(e.evt))
+	if evt.evt._type != pluginEventCode || evt.dataLen() == 0 {
+		return nil
+	}
+	return unsafe.Slice((*byte)(evt.dataPtr()), int(evt.dataLen()))
+}
+
+// setStringBytes writes in out a pointer to a C string with the contents
+// of v. If v is a sub-slice of the event payload, this also returns its
+// offset from the start of the payload and true. In that case, if v is
+// directly followed by a null terminator the pointer refers to the event
+// memory. Otherwise, v is copied in the i-th StringBuffer.
+func (e *extractRequest) setStringBytes(i int, out unsafe.Pointer, v []byte) (uint32, bool) {
+	var off uintptr
+	inPayload := false
+	if data := e.payload(); len(data) > 0 && len(v) > 0 {
+		start := uintptr(unsafe.Pointer(&data[0]))
+		p := uintptr(unsafe.Pointer(&v[0]))
+		if p >= start && p+uintptr(len(v)) <= start+uintptr(len(data)) {
+			off = p - start
+			inPayload = true
+			if int(off)+len(v) < len(data) && data[int(off)+len(v)] == 0 {
+				*((**C.char)(out)) = (*C.char)(unsafe.Pointer(&v[0]))
+				return uint32(off), true
+			}
+		}
+	}
+
+	// note: the string is only used to copy the bytes inside the buffer,
+	// so we avoid allocating it
+	var str string
+	if len(v) > 0 {
+		(*reflect.StringHeader)(unsafe.Pointer(&str)).Data = uintptr(unsafe.Pointer(&v[0]))
+		(*reflect.StringHeader)(unsafe.Pointer(&str)).Len = len(v)
+	}
+	e.strBuf(i).Write(str)
+	*((**C.char)(out)) = (*C.char)(e.resStrBufs[i].CharPtr())
+	return uint32(off), inPayload
+}
+
 func (e *extractRequest) WantOffset() bool {
 	return e.resOffsetStart != nil && e.resOffsetLength != nil
 }
@@ -319,3 +319,89 @@ func TestExtractRequestSetValue(t *testing.T) {
 	freeBinPtr()
 	freeBinListPtr()
 }
+
+func TestExtractRequestSetStringBytes(t *testing.T) {
+	// the payload contains a null-terminated string followed by a non-terminated one
+	data := []byte("hello\x00world")
+	writers, err := NewEventWriters(1, int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer writers.Free()
+	writers.Get(0).Writer().Write(data)
+	evtInput := &_Ctype_struct_ss_plugin_event_input{evt: *(**_Ctype_struct_ss_plugin_event)(writers.ArrayPtr())}
+	payload := NewEventReader(unsafe.Pointer(evtInput)).Bytes()
+
+	pool := NewExtractRequestPool()
+	defer pool.Free()
+	strPtr, freeStrPtr := allocSSPluginExtractField(1, FieldTypeCharBuf, "test.str", "", 0, false, false)
+	defer freeStrPtr()
+	strListPtr, freeStrListPtr := allocSSPluginExtractField(2, FieldTypeCharBuf, "test.str", "", 0, false, true)
+	defer freeStrListPtr()
+	startPtr := allocSSPluginExtractOffset()
+	lengthPtr := allocSSPluginExtractOffset()
+	strReq := pool.Get(0).(StringBytesExtractRequest)
+	strReq.SetPtr(unsafe.Pointer(strPtr))
+	strReq.SetEventPtr(unsafe.Pointer(evtInput))
+	strReq.SetOffsetPtrs(unsafe.Pointer(startPtr), unsafe.Pointer(lengthPtr))
+	strReqList := pool.Get(1).(StringBytesExtractRequest)
+	strReqList.SetPtr(unsafe.Pointer(strListPtr))
+	strReqList.SetEventPtr(unsafe.Pointer(evtInput))
+
+	resPtr := func(p *_Ctype_ss_plugin_extract_field, index int) unsafe.Pointer {
+		res := *(*unsafe.Pointer)(unsafe.Pointer(&p.res))
+		return *(*unsafe.Pointer)(unsafe.Add(res, index*_Ciconst_sizeof_uintptr_t))
+	}
+
+	// null-terminated sub-slices of the payload are not copied
+	strReq.SetStringBytes(payload[:5])
+	if getStrResSSPluingExtractField(t, strPtr, 0) != "hello" {
+		t.Errorf("expected value '%s', but found '%s'", "hello", getStrResSSPluingExtractField(t, strPtr, 0))
+	}
+	if resPtr(strPtr, 0) != unsafe.Pointer(&payload[0]) {
+		t.Errorf("expected value to point to the event payload")
+	}
+	if getResSSPluginExtractOffsetFromPtr(startPtr) != PluginEventPayloadOffset || getResSSPluginExtractOffsetFromPtr(lengthPtr) != 5 {
+		t.Errorf("expected offset {%d,%d}, but found {%d,%d}", PluginEventPayloadOffset, 5,
+			getResSSPluginExtractOffsetFromPtr(startPtr), getResSSPluginExtractOffsetFromPtr(lengthPtr))
+	}
+
+	// other sub-slices of the payload are copied, but their offsets are known
+	strReq.SetStringBytes(payload[6:])
+	if getStrResSSPluingExtractField(t, strPtr, 0) != "world" {
+		t.Errorf("expected value '%s', but found '%s'", "world", getStrResSSPluingExtractField(t, strPtr, 0))
+	}
+	if resPtr(strPtr, 0) == unsafe.Pointer(&payload[6]) {
+		t.Errorf("expected value to be copied")
+	}
+	if getResSSPluginExtractOffsetFromPtr(startPtr) != PluginEventPayloadOffset+6 || getResSSPluginExtractOffsetFromPtr(lengthPtr) != 5 {
+		t.Errorf("expected offset {%d,%d}, but found {%d,%d}", PluginEventPayloadOffset+6, 5,
+			getResSSPluginExtractOffsetFromPtr(startPtr), getResSSPluginExtractOffsetFromPtr(lengthPtr))
+	}
+
+	// slices out of the payload are copied, and offsets are left untouched
+	strReq.SetValueOffset(0, 0)
+	strReq.SetValue([]byte("test"))
+	if getStrResSSPluingExtractField(t, strPtr, 0) != "test" {
+		t.Errorf("expected value '%s', but found '%s'", "test", getStrResSSPluingExtractField(t, strPtr, 0))
+	}
+	if getResSSPluginExtractOffsetFromPtr(startPtr) != 0 || getResSSPluginExtractOffsetFromPtr(lengthPtr) != 0 {
+		t.Errorf("expected offset {%d,%d}, but found {%d,%d}", 0, 0,
+			getResSSPluginExtractOffsetFromPtr(startPtr), getResSSPluginExtractOffsetFromPtr(lengthPtr))
+	}
+
+	// lists can mix both
+	strReqList.SetValue([][]byte{payload[:5], []byte("test"), payload[6:]})
+	for i, s := range []string{"hello", "test", "world"} {
+		if getStrResSSPluingExtractField(t, strListPtr, i) != s {
+			t.Errorf("expected value '%s', but found '%s'", s, getStrResSSPluingExtractField(t, strListPtr, i))
+		}
+	}
+	if resPtr(strListPtr, 0) != unsafe.Pointer(&payload[0]) {
+		t.Errorf("expected value to point to the event payload")
+	}
+
+	assertPanic(t, func() {
+		strReqList.SetStringBytes(payload[:5])
+	})
+}
@@ -0,0 +1,184 @@
+// SPDX-License-Identifier: Apache-2.0
+/*
+Copyright (C) 2026 The Falco Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdk
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sync"
+)
+
+// ErrInvalidFieldArg is the error returned when the argument of an
+// extraction request does not satisfy the constraints described by
+// the FieldEntryArg of the requested field.
+var ErrInvalidFieldArg = errors.New("invalid field argument")
+
+// fieldTypes maps the type names of FieldEntry to their FieldType* values
+var fieldTypes = map[string]uint32{
+	"uint64":  FieldTypeUint64,
+	"string":  FieldTypeCharBuf,
+	"reltime": FieldTypeRelTime,
+	"abstime": FieldTypeAbsTime,
+	"bool":    FieldTypeBool,
+	"ipaddr":  FieldTypeIPAddr,
+	"ipnet":   FieldTypeIPNet,
+}
+
+// FieldTypeCode returns the FieldType* value of the given type name, as
+// used in the Type member of FieldEntry, or false if the type name is
+// not supported.
+func FieldTypeCode(name string) (uint32, bool) {
+	t, ok := fieldTypes[name]
+	return t, ok
+}
+
+// fieldArgPatterns caches the compiled regular expressions of the
+// KeyPattern member of FieldEntryArg, indexed by their source string
+var fieldArgPatterns sync.Map // map[string]*regexp.Regexp
+
+func compileFieldArgPattern(pattern string) (*regexp.Regexp, error) {
+	if re, ok := fieldArgPatterns.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	// the pattern must match the whole key
+	re, err := regexp.Compile("^(?:" + pattern + ")$")
+	if err != nil {
+		return nil, err
+	}
+	fieldArgPatterns.Store(pattern, re)
+	return re, nil
+}
+
+// Check returns a non-nil error if f is not a valid field entry. This checks
+// that the aliases are not empty nor equal to the name, and that the argument
+// is consistent as for the Check method of FieldEntryArg. Warnings reports
+// the issues that do not make f invalid.
+func (f *FieldEntry) Check() error {
+	for _, a := range f.Aliases {
+		if len(a) == 0 || a == f.Name {
+			return fmt.Errorf("invalid alias '%s'", a)
+		}
+	}
+	if err := f.Arg.Check(); err != nil {
+		return fmt.Errorf("invalid arg: %s", err.Error())
+	}
+	return nil
+}
+
+// Warnings returns a description of each issue of f that does not make it
+// invalid as for Check. This reports the properties that are not among the
+// known FieldProperty values, which the plugin framework ignores, and the
+// issues reported by the Warnings method of FieldEntryArg.
+func (f *FieldEntry) Warnings() []string {
+	var res []string
+	for _, p := range f.Properties {
+		switch p {
+		case FieldPropertyHidden, FieldPropertyInfo, FieldPropertyConversation, FieldPropertyAddOutput:
+		default:
+			res = append(res, fmt.Sprintf("unknown property '%s'", p))
+		}
+	}
+	for _, w := range f.Arg.Warnings() {
+		res = append(res, "arg: "+w)
+	}
+	return res
+}
+
+// Check returns a non-nil error if the constraints described by the
+// AllowedKeys, KeyPattern, and IndexRange members of a are not consistent
+// with the other ones. The inconsistencies among the other members are
+// reported by Warnings instead, because they used to be accepted.
+func (a *FieldEntryArg) Check() error {
+	if !a.IsKey && (len(a.AllowedKeys) > 0 || len(a.KeyPattern) > 0) {
+		return errors.New("allowedKeys and keyPattern require isKey to be enabled")
+	}
+	if !a.IsIndex && a.IndexRange != nil {
+		return errors.New("indexRange requires isIndex to be enabled")
+	}
+	if a.IndexRange != nil && a.IndexRange.Min > a.IndexRange.Max {
+		return fmt.Errorf("invalid indexRange: min %d is greater than max %d", a.IndexRange.Min, a.IndexRange.Max)
+	}
+	if len(a.KeyPattern) > 0 {
+		if _, err := compileFieldArgPattern(a.KeyPattern); err != nil {
+			return fmt.Errorf("invalid keyPattern: %s", err.Error())
+		}
+	}
+	return nil
+}
+
+// Warnings returns a description of each inconsistency among the IsKey,
+// IsIndex, and IsRequired members of a, which the plugin framework might
+// not support.
+func (a *FieldEntryArg) Warnings() []string {
+	var res []string
+	if a.IsKey && a.IsIndex {
+		res = append(res, "isKey and isIndex should not be both enabled")
+	}
+	if a.IsRequired && !a.IsKey && !a.IsIndex {
+		res = append(res, "isRequired should be enabled along with either isKey or isIndex")
+	}
+	return res
+}
+
+// Validate returns an error wrapping ErrInvalidFieldArg if the argument of
+// the given ExtractRequest does not satisfy the constraints described by a.
+func (a *FieldEntryArg) Validate(req ExtractRequest) error {
+	if !req.ArgPresent() {
+		if a.IsRequired {
+			return fmt.Errorf("%w: field '%s' requires an argument", ErrInvalidFieldArg, req.Field())
+		}
+		return nil
+	}
+
+	if a.IsIndex {
+		if a.IndexRange != nil && (req.ArgIndex() < a.IndexRange.Min || req.ArgIndex() > a.IndexRange.Max) {
+			return fmt.Errorf("%w: field '%s' accepts indexes in [%d, %d], but %d was requested",
+				ErrInvalidFieldArg, req.Field(), a.IndexRange.Min, a.IndexRange.Max, req.ArgIndex())
+		}
+		return nil
+	}
+
+	if a.IsKey {
+		key := req.ArgKey()
+		if len(a.AllowedKeys) > 0 {
+			allowed := false
+			for _, k := range a.AllowedKeys {
+				if k == key {
+					allowed = true
+					break
+				}
+			}
+			if !allowed {
+				return fmt.Errorf("%w: field '%s' does not accept key '%s'", ErrInvalidFieldArg, req.Field(), key)
+			}
+		}
+		if len(a.KeyPattern) > 0 {
+			re, err := compileFieldArgPattern(a.KeyPattern)
+			if err != nil {
+				return fmt.Errorf("%w: field '%s' has an invalid keyPattern: %s", ErrInvalidFieldArg, req.Field(), err.Error())
+			}
+			if !re.MatchString(key) {
+				return fmt.Errorf("%w: field '%s' does not accept key '%s' (must match '%s')", ErrInvalidFieldArg, req.Field(), key, a.KeyPattern)
+			}
+		}
+		return nil
+	}
+
+	return fmt.Errorf("%w: field '%s' does not accept arguments", ErrInvalidFieldArg, req.Field())
+}
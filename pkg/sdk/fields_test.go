@@ -0,0 +1,161 @@
+// SPDX-License-Identifier: Apache-2.0
+/*
+Copyright (C) 2026 The Falco Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdk
+
+import (
+	"errors"
+	"testing"
+)
+
+type testArgRequest struct {
+	ExtractRequest
+	present bool
+	key     string
+	index   uint64
+}
+
+func (t *testArgRequest) Field() string {
+	return "test.field"
+}
+
+func (t *testArgRequest) ArgPresent() bool {
+	return t.present
+}
+
+func (t *testArgRequest) ArgKey() string {
+	return t.key
+}
+
+func (t *testArgRequest) ArgIndex() uint64 {
+	return t.index
+}
+
+func TestFieldTypeCode(t *testing.T) {
+	if v, ok := FieldTypeCode("ipnet"); !ok || v != FieldTypeIPNet {
+		t.Errorf("expected %d, but found %d (%v)", FieldTypeIPNet, v, ok)
+	}
+	if _, ok := FieldTypeCode("int64"); ok {
+		t.Errorf("expected unsupported type")
+	}
+}
+
+func TestFieldEntryCheck(t *testing.T) {
+	valid := []FieldEntry{
+		{Name: "test.field"},
+		{Name: "test.field", Properties: []FieldProperty{FieldPropertyHidden, FieldPropertyInfo, FieldPropertyConversation, FieldPropertyAddOutput}},
+		{Name: "test.field", Aliases: []string{"test.alias"}},
+		{Name: "test.field", Arg: FieldEntryArg{IsRequired: true}},
+		{Name: "test.field", Properties: []FieldProperty{"unknown"}},
+	}
+	invalid := []FieldEntry{
+		{Name: "test.field", Aliases: []string{""}},
+		{Name: "test.field", Aliases: []string{"test.field"}},
+		{Name: "test.field", Arg: FieldEntryArg{KeyPattern: "a"}},
+	}
+	for i, f := range valid {
+		if err := f.Check(); err != nil {
+			t.Errorf("(valid #%d): unexpected error: %s", i, err.Error())
+		}
+	}
+	for i, f := range invalid {
+		if err := f.Check(); err == nil {
+			t.Errorf("(invalid #%d): expected error", i)
+		}
+	}
+}
+
+func TestFieldEntryArgCheck(t *testing.T) {
+	valid := []FieldEntryArg{
+		{},
+		{IsKey: true},
+		{IsIndex: true, IsRequired: true},
+		{IsKey: true, AllowedKeys: []string{"a", "b"}, KeyPattern: "[a-z]+"},
+		{IsIndex: true, IndexRange: &FieldEntryArgRange{Min: 1, Max: 1}},
+		{IsKey: true, IsIndex: true},
+		{IsRequired: true},
+	}
+	invalid := []FieldEntryArg{
+		{AllowedKeys: []string{"a"}},
+		{KeyPattern: "a"},
+		{IndexRange: &FieldEntryArgRange{}},
+		{IsIndex: true, IndexRange: &FieldEntryArgRange{Min: 2, Max: 1}},
+		{IsKey: true, KeyPattern: "[a-z"},
+	}
+	for i, a := range valid {
+		if err := a.Check(); err != nil {
+			t.Errorf("(valid #%d): unexpected error: %s", i, err.Error())
+		}
+	}
+	for i, a := range invalid {
+		if err := a.Check(); err == nil {
+			t.Errorf("(invalid #%d): expected error", i)
+		}
+	}
+}
+
+func TestFieldEntryArgWarnings(t *testing.T) {
+	for i, a := range []FieldEntryArg{{}, {IsKey: true, IsRequired: true}, {IsIndex: true}} {
+		if w := a.Warnings(); len(w) != 0 {
+			t.Errorf("(#%d): unexpected warnings: %v", i, w)
+		}
+	}
+	for i, a := range []FieldEntryArg{{IsKey: true, IsIndex: true}, {IsRequired: true}} {
+		if w := a.Warnings(); len(w) != 1 {
+			t.Errorf("(#%d): expected %d warning, but found %v", i, 1, w)
+		}
+	}
+	f := FieldEntry{Name: "test.field", Arg: FieldEntryArg{IsRequired: true}}
+	if w := f.Warnings(); len(w) != 1 {
+		t.Errorf("expected %d warning, but found %v", 1, w)
+	}
+	f = FieldEntry{Name: "test.field", Properties: []FieldProperty{FieldPropertyHidden, "unknown"}}
+	if w := f.Warnings(); len(w) != 1 {
+		t.Errorf("expected %d warning, but found %v", 1, w)
+	}
+}
+
+func TestFieldEntryArgValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		arg   FieldEntryArg
+		req   testArgRequest
+		valid bool
+	}{
+		{"no-arg", FieldEntryArg{}, testArgRequest{}, true},
+		{"no-arg-present", FieldEntryArg{}, testArgRequest{present: true, key: "a"}, false},
+		{"required-missing", FieldEntryArg{IsKey: true, IsRequired: true}, testArgRequest{}, false},
+		{"optional-missing", FieldEntryArg{IsKey: true}, testArgRequest{}, true},
+		{"key-any", FieldEntryArg{IsKey: true}, testArgRequest{present: true, key: "x"}, true},
+		{"key-allowed", FieldEntryArg{IsKey: true, AllowedKeys: []string{"a", "b"}}, testArgRequest{present: true, key: "b"}, true},
+		{"key-not-allowed", FieldEntryArg{IsKey: true, AllowedKeys: []string{"a", "b"}}, testArgRequest{present: true, key: "c"}, false},
+		{"key-pattern", FieldEntryArg{IsKey: true, KeyPattern: "[a-z]+"}, testArgRequest{present: true, key: "abc"}, true},
+		{"key-pattern-partial", FieldEntryArg{IsKey: true, KeyPattern: "[a-z]+"}, testArgRequest{present: true, key: "abc1"}, false},
+		{"index-any", FieldEntryArg{IsIndex: true}, testArgRequest{present: true, index: 100}, true},
+		{"index-in-range", FieldEntryArg{IsIndex: true, IndexRange: &FieldEntryArgRange{Min: 1, Max: 3}}, testArgRequest{present: true, index: 3}, true},
+		{"index-below-range", FieldEntryArg{IsIndex: true, IndexRange: &FieldEntryArgRange{Min: 1, Max: 3}}, testArgRequest{present: true, index: 0}, false},
+		{"index-above-range", FieldEntryArg{IsIndex: true, IndexRange: &FieldEntryArgRange{Min: 1, Max: 3}}, testArgRequest{present: true, index: 4}, false},
+	}
+	for _, tt := range tests {
+		err := tt.arg.Validate(&tt.req)
+		if tt.valid && err != nil {
+			t.Errorf("(%s): unexpected error: %s", tt.name, err.Error())
+		} else if !tt.valid && !errors.Is(err, ErrInvalidFieldArg) {
+			t.Errorf("(%s): expected %s, but found %v", tt.name, ErrInvalidFieldArg.Error(), err)
+		}
+	}
+}
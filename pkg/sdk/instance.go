@@ -63,7 +63,11 @@ type Events interface {
 // calls to NextBatch. ErrEOF can be returned to indicate that no new events
 // will be available. After returning ErrEOF once, subsequent calls to
 // NextBatch must be idempotent and must keep returning ErrEOF.
-// If the returned error is non-nil, the batch of events is discarded.
+// Errors matching ErrTemporary or ErrSkip with errors.Is are handled like
+// ErrTimeout, so that a transient failure or a malformed record do not end
+// the capture session.
+// If the returned error is non-nil and does not match any of the errors
+// above, the batch of events is discarded.
 type NextBatcher interface {
 	NextBatch(pState PluginState, evts EventWriters) (int, error)
 }
@@ -20,14 +20,19 @@ package hooks
 
 import (
 	"github.com/falcosecurity/plugin-sdk-go/pkg/cgo"
+	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk"
 )
 
 type OnBeforeDestroyFn func(handle cgo.Handle)
 type OnAfterInitFn func(handle cgo.Handle)
+type OnBeforeExtractFn func(handle cgo.Handle, req sdk.ExtractRequest) (sdk.ExtractRequest, error)
+type OnMetricsFn func(handle cgo.Handle) []sdk.Metric
 
 var (
 	onBeforeDestroy OnBeforeDestroyFn = func(cgo.Handle) {}
 	onAfterInit     OnAfterInitFn     = func(cgo.Handle) {}
+	onBeforeExtract OnBeforeExtractFn = func(h cgo.Handle, r sdk.ExtractRequest) (sdk.ExtractRequest, error) { return r, nil }
+	onMetrics       OnMetricsFn       = func(cgo.Handle) []sdk.Metric { return nil }
 )
 
 // SetOnBeforeDestroy sets a callback that is invoked before the Destroy() method.
@@ -55,3 +60,35 @@ func SetOnAfterInit(fn OnAfterInitFn) {
 func OnAfterInit() OnAfterInitFn {
 	return onAfterInit
 }
+
+// SetOnBeforeExtract sets a callback that is invoked before the Extract()
+// method for each field extraction request. The callback returns the request
+// to be passed to Extract(), which can either be the given one or a wrapper
+// of it. If the callback returns a non-nil error, the extraction fails
+// without invoking Extract().
+func SetOnBeforeExtract(fn OnBeforeExtractFn) {
+	if fn == nil {
+		panic("plugin-sdk-go/sdk/internal/hooks.SetOnBeforeExtract: fn must not be nil")
+	}
+	onBeforeExtract = fn
+}
+
+// OnBeforeExtract returns a callback that is invoked before the Extract() method.
+func OnBeforeExtract() OnBeforeExtractFn {
+	return onBeforeExtract
+}
+
+// SetOnMetrics sets a callback that is invoked after the Metrics() method,
+// and that returns the metrics provided by the SDK itself. These are
+// reported in addition to the ones returned by Metrics(), if any.
+func SetOnMetrics(fn OnMetricsFn) {
+	if fn == nil {
+		panic("plugin-sdk-go/sdk/internal/hooks.SetOnMetrics: fn must not be nil")
+	}
+	onMetrics = fn
+}
+
+// OnMetrics returns a callback that is invoked after the Metrics() method.
+func OnMetrics() OnMetricsFn {
+	return onMetrics
+}
@@ -0,0 +1,88 @@
+// SPDX-License-Identifier: Apache-2.0
+/*
+Copyright (C) 2026 The Falco Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package logger sends messages to the logger of the owner of a plugin
+// state, as passed to plugin_init, to be used internally in the SDK.
+package logger
+
+/*
+#include <stdlib.h>
+#include "../../plugin_api.h"
+
+static void logger_log(ss_plugin_log_fn_t fn, ss_plugin_owner_t* o, const char* msg, ss_plugin_log_severity sev)
+{
+	fn(o, NULL, msg, sev);
+}
+*/
+import "C"
+import (
+	"sync"
+	"unsafe"
+
+	"github.com/falcosecurity/plugin-sdk-go/pkg/cgo"
+)
+
+// Severity is the severity of a message, as of ss_plugin_log_severity
+type Severity int32
+
+const (
+	SeverityError   Severity = C.SS_PLUGIN_LOG_SEV_ERROR
+	SeverityWarning Severity = C.SS_PLUGIN_LOG_SEV_WARNING
+	SeverityInfo    Severity = C.SS_PLUGIN_LOG_SEV_INFO
+	SeverityDebug   Severity = C.SS_PLUGIN_LOG_SEV_DEBUG
+)
+
+type ownerLogger struct {
+	owner unsafe.Pointer
+	logFn C.ss_plugin_log_fn_t
+}
+
+// loggers maps each plugin state handle to the logger of its owner
+var loggers sync.Map
+
+// Set sets the owner of the plugin state of the given handle, and the log
+// function of the init input received from it. A nil logFn unsets it.
+func Set(handle cgo.Handle, owner, logFn unsafe.Pointer) {
+	if logFn == nil {
+		loggers.Delete(handle)
+		return
+	}
+	loggers.Store(handle, ownerLogger{
+		owner: owner,
+		logFn: (C.ss_plugin_log_fn_t)(logFn),
+	})
+}
+
+// Delete unsets the logger of the plugin state of the given handle.
+func Delete(handle cgo.Handle) {
+	loggers.Delete(handle)
+}
+
+// Log sends msg with the given severity to the logger of the owner of the
+// plugin state of the given handle. The message is discarded if the owner
+// did not pass a log function in plugin_init. Note, the message can be
+// sent from any thread, such as from the async extraction workers.
+func Log(handle cgo.Handle, sev Severity, msg string) {
+	v, ok := loggers.Load(handle)
+	if !ok {
+		return
+	}
+	l := v.(ownerLogger)
+	cMsg := C.CString(msg)
+	defer C.free(unsafe.Pointer(cMsg))
+	C.logger_log(l.logFn, l.owner, cMsg, C.ss_plugin_log_severity(sev))
+}
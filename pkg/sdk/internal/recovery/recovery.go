@@ -0,0 +1,73 @@
+// SPDX-License-Identifier: Apache-2.0
+/*
+Copyright (C) 2026 The Falco Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package recovery contains the panic recovery layer used internally by
+// the prebuilt C symbols of the SDK.
+package recovery
+
+import (
+	"errors"
+	"fmt"
+	"runtime/debug"
+
+	"github.com/falcosecurity/plugin-sdk-go/pkg/cgo"
+	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk"
+)
+
+// Recover recovers from a panic, if any, and must be invoked directly with
+// a defer statement. The recovered value and the current stack trace are
+// wrapped in a *sdk.PanicError, which is recorded in the value of the
+// pState handle, and is then passed to onPanic so that the caller
+// can set its failure return values. A zero pState handle is ignored.
+func Recover(pState cgo.Handle, onPanic func(err error)) {
+	if r := recover(); r != nil {
+		err := &sdk.PanicError{Value: r, Stack: debug.Stack()}
+		if pState != 0 {
+			record(pState, err)
+		}
+		onPanic(err)
+	}
+}
+
+// record sets err as the last error of the value of the pState handle if it
+// implements sdk.LastError, and poisons it if it implements sdk.Poisoner.
+func record(pState cgo.Handle, err error) {
+	if state, ok := pState.Value().(sdk.LastError); ok {
+		state.SetLastError(err)
+	}
+	if state, ok := pState.Value().(sdk.Poisoner); ok {
+		state.SetPoisoned(err)
+	}
+}
+
+// Poisoned returns a non-nil error wrapping sdk.ErrPoisoned if the value of
+// the pState handle implements sdk.Poisoner and has been poisoned.
+func Poisoned(pState cgo.Handle) error {
+	state, ok := pState.Value().(sdk.Poisoner)
+	if !ok {
+		return nil
+	}
+	err := state.Poisoned()
+	if err == nil {
+		return nil
+	}
+	var panicErr *sdk.PanicError
+	if errors.As(err, &panicErr) {
+		return fmt.Errorf("%w: %v", sdk.ErrPoisoned, panicErr.Value)
+	}
+	return fmt.Errorf("%w: %s", sdk.ErrPoisoned, err.Error())
+}
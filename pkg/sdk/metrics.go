@@ -0,0 +1,159 @@
+// SPDX-License-Identifier: Apache-2.0
+/*
+Copyright (C) 2026 The Falco Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdk
+
+/*
+#include "plugin_types.h"
+#include <stdlib.h>
+*/
+import "C"
+import (
+	"fmt"
+	"unsafe"
+
+	"github.com/falcosecurity/plugin-sdk-go/pkg/ptr"
+)
+
+// MetricType represents the type of a Metric.
+type MetricType uint32
+
+const (
+	// MetricTypeMonotonic is the type of metrics whose value never decreases,
+	// such as counters
+	MetricTypeMonotonic MetricType = C.SS_PLUGIN_METRIC_TYPE_MONOTONIC
+	//
+	// MetricTypeNonMonotonic is the type of metrics whose value can both
+	// increase and decrease, such as gauges
+	MetricTypeNonMonotonic MetricType = C.SS_PLUGIN_METRIC_TYPE_NON_MONOTONIC
+)
+
+// Metric represents a metric provided by a plugin to the framework through
+// plugin_get_metrics(). The Value must be one of uint32, int32, uint64,
+// int64, float64, float32, or int.
+type Metric struct {
+	Name  string
+	Type  MetricType
+	Value interface{}
+}
+
+// MetricBuffer represents a buffer of metrics in C-allocated memory, in the
+// form of an array of ss_plugin_metric structs.
+type MetricBuffer interface {
+	// Write copies the given metrics in the buffer, which is resized
+	// automatically if needed. This returns an error if the value of any
+	// of the metrics has an unsupported type.
+	Write(metrics []Metric) error
+	//
+	// ArrayPtr returns a pointer to the first element of the array of
+	// ss_plugin_metric structs, or nil if the buffer is empty.
+	ArrayPtr() unsafe.Pointer
+	//
+	// Len returns the number of metrics written in the buffer.
+	Len() int
+	//
+	// Free deallocates any memory used by the buffer that can't be disposed
+	// through garbage collection. The behavior of the buffer after
+	// Free is undefined.
+	Free()
+}
+
+type metricBuffer struct {
+	cArray *C.ss_plugin_metric
+	cap    int
+	len    int
+	names  []StringBuffer
+}
+
+// NewMetricBuffer returns a new empty MetricBuffer. The underlying memory
+// buffer is allocated lazily at the first call to Write.
+func NewMetricBuffer() MetricBuffer {
+	return &metricBuffer{}
+}
+
+func (m *metricBuffer) Write(metrics []Metric) error {
+	if len(metrics) > m.cap {
+		if m.cArray != nil {
+			C.free(unsafe.Pointer(m.cArray))
+		}
+		m.cArray = (*C.ss_plugin_metric)(C.malloc(C.size_t(len(metrics)) * C.sizeof_ss_plugin_metric))
+		m.cap = len(metrics)
+	}
+	for len(m.names) < len(metrics) {
+		m.names = append(m.names, &ptr.StringBuffer{})
+	}
+
+	m.len = 0
+	arr := unsafe.Slice(m.cArray, m.cap)
+	for i, metric := range metrics {
+		value := unsafe.Pointer(&arr[i].value)
+		switch v := metric.Value.(type) {
+		case uint32:
+			*(*uint32)(value) = v
+			arr[i].value_type = C.SS_PLUGIN_METRIC_VALUE_TYPE_U32
+		case int32:
+			*(*int32)(value) = v
+			arr[i].value_type = C.SS_PLUGIN_METRIC_VALUE_TYPE_S32
+		case uint64:
+			*(*uint64)(value) = v
+			arr[i].value_type = C.SS_PLUGIN_METRIC_VALUE_TYPE_U64
+		case int64:
+			*(*int64)(value) = v
+			arr[i].value_type = C.SS_PLUGIN_METRIC_VALUE_TYPE_S64
+		case float64:
+			*(*float64)(value) = v
+			arr[i].value_type = C.SS_PLUGIN_METRIC_VALUE_TYPE_D
+		case float32:
+			*(*float32)(value) = v
+			arr[i].value_type = C.SS_PLUGIN_METRIC_VALUE_TYPE_F
+		case int:
+			*(*C.int)(value) = C.int(v)
+			arr[i].value_type = C.SS_PLUGIN_METRIC_VALUE_TYPE_I
+		default:
+			return fmt.Errorf("metric '%s' has value of unsupported type %T", metric.Name, metric.Value)
+		}
+		m.names[i].Write(metric.Name)
+		arr[i].name = (*C.char)(m.names[i].CharPtr())
+		arr[i]._type = C.ss_plugin_metric_type(metric.Type)
+	}
+	m.len = len(metrics)
+	return nil
+}
+
+func (m *metricBuffer) ArrayPtr() unsafe.Pointer {
+	if m.len == 0 {
+		return nil
+	}
+	return unsafe.Pointer(m.cArray)
+}
+
+func (m *metricBuffer) Len() int {
+	return m.len
+}
+
+func (m *metricBuffer) Free() {
+	if m.cArray != nil {
+		C.free(unsafe.Pointer(m.cArray))
+		m.cArray = nil
+	}
+	m.cap = 0
+	m.len = 0
+	for _, n := range m.names {
+		n.Free()
+	}
+	m.names = nil
+}
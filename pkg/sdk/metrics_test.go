@@ -0,0 +1,85 @@
+// SPDX-License-Identifier: Apache-2.0
+/*
+Copyright (C) 2026 The Falco Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdk
+
+import (
+	"testing"
+	"unsafe"
+
+	"github.com/falcosecurity/plugin-sdk-go/pkg/ptr"
+)
+
+func TestMetricBuffer(t *testing.T) {
+	buf := NewMetricBuffer()
+	defer buf.Free()
+	if buf.Len() != 0 || buf.ArrayPtr() != nil {
+		t.Fatalf("expected empty buffer")
+	}
+
+	metrics := []Metric{
+		{Name: "u32", Type: MetricTypeMonotonic, Value: uint32(1)},
+		{Name: "s32", Type: MetricTypeNonMonotonic, Value: int32(-2)},
+		{Name: "u64", Type: MetricTypeMonotonic, Value: uint64(3)},
+		{Name: "s64", Type: MetricTypeNonMonotonic, Value: int64(-4)},
+		{Name: "d", Type: MetricTypeNonMonotonic, Value: float64(5.5)},
+		{Name: "f", Type: MetricTypeNonMonotonic, Value: float32(6.5)},
+		{Name: "i", Type: MetricTypeNonMonotonic, Value: int(-7)},
+	}
+	for _, n := range []int{len(metrics), 2, len(metrics)} {
+		if err := buf.Write(metrics[:n]); err != nil {
+			t.Fatal(err)
+		}
+		if buf.Len() != n {
+			t.Fatalf("expected %d metrics, but found %d", n, buf.Len())
+		}
+		arr := unsafe.Slice((*_Ctype_ss_plugin_metric)(buf.ArrayPtr()), buf.Len())
+		for i, m := range arr {
+			if name := ptr.GoString(unsafe.Pointer(m.name)); name != metrics[i].Name {
+				t.Errorf("expected name %s, but found %s", metrics[i].Name, name)
+			}
+			if MetricType(m._type) != metrics[i].Type {
+				t.Errorf("expected type %d, but found %d", metrics[i].Type, m._type)
+			}
+			var value interface{}
+			p := unsafe.Pointer(&m.value)
+			switch m.value_type {
+			case _Ciconst_SS_PLUGIN_METRIC_VALUE_TYPE_U32:
+				value = *(*uint32)(p)
+			case _Ciconst_SS_PLUGIN_METRIC_VALUE_TYPE_S32:
+				value = *(*int32)(p)
+			case _Ciconst_SS_PLUGIN_METRIC_VALUE_TYPE_U64:
+				value = *(*uint64)(p)
+			case _Ciconst_SS_PLUGIN_METRIC_VALUE_TYPE_S64:
+				value = *(*int64)(p)
+			case _Ciconst_SS_PLUGIN_METRIC_VALUE_TYPE_D:
+				value = *(*float64)(p)
+			case _Ciconst_SS_PLUGIN_METRIC_VALUE_TYPE_F:
+				value = *(*float32)(p)
+			case _Ciconst_SS_PLUGIN_METRIC_VALUE_TYPE_I:
+				value = int(*(*int32)(p))
+			}
+			if value != metrics[i].Value {
+				t.Errorf("expected value %v, but found %v", metrics[i].Value, value)
+			}
+		}
+	}
+
+	if err := buf.Write([]Metric{{Name: "bad", Value: "str"}}); err == nil {
+		t.Errorf("expected error")
+	}
+}
@@ -37,6 +37,22 @@ type LastError interface {
 	SetLastError(err error)
 }
 
+// Poisoner is a composable interface wrapping the basic Poisoned and
+// SetPoisoned methods. The prebuilt C symbols recover from the panics
+// raised by the plugin code, and if the plugin state implements Poisoner
+// they mark it as poisoned. Once poisoned, all the subsequent calls to the
+// prebuilt C symbols fail fast by returning an error wrapping ErrPoisoned,
+// without invoking the plugin code again.
+type Poisoner interface {
+	// Poisoned returns the error that poisoned the plugin, or nil if
+	// the plugin is not poisoned.
+	Poisoned() error
+	//
+	// SetPoisoned marks the plugin as poisoned by the given error.
+	// Passing a nil error clears the poisoned state.
+	SetPoisoned(err error)
+}
+
 // Destroyer is an interface wrapping the basic Destroy method.
 // Destroy deinitializes the resources opened or allocated by a plugin.
 // This is meant to be used in plugin_destroy() to release the plugin's
@@ -62,6 +78,16 @@ type Extractor interface {
 	Extract(req ExtractRequest, evt EventReader) error
 }
 
+// BatchExtractor is an interface wrapping the basic ExtractBatch method.
+// ExtractBatch is an optional vectorized alternative to Extract, meant to be
+// used when the same field is extracted from many events at once, such as
+// by offline analysis tools. The reqs and evts slices have the same length,
+// and reqs[i] is the extraction request of the same field for evts[i].
+// Returning a non-nil error makes the whole batch fail.
+type BatchExtractor interface {
+	ExtractBatch(reqs []ExtractRequest, evts []EventReader) error
+}
+
 // OpenParams is an interface wrapping the basic OpenParams method.
 // OpenParams is meant to be used in plugin_list_open_params() to return a list
 // of suggested parameters that would be accepted as valid arguments
@@ -70,6 +96,14 @@ type OpenParams interface {
 	OpenParams() ([]OpenParam, error)
 }
 
+// Metrics is an interface wrapping the basic Metrics method.
+// Metrics is meant to be used in plugin_get_metrics() to return an updated
+// set of metrics provided by the plugin. See the Metric type for the
+// supported value types.
+type Metrics interface {
+	Metrics() []Metric
+}
+
 // InitSchema is an interface wrapping the basic InitSchema method.
 // InitSchema is meant to be used in plugin_get_init_schema() to return a
 // schema describing the data expected to be passed as a configuration
@@ -20,9 +20,12 @@ limitations under the License.
 package extractor
 
 import (
+	"sync"
+
 	"github.com/falcosecurity/plugin-sdk-go/pkg/cgo"
 	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk"
 	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk/internal/hooks"
+	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk/internal/logger"
 	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk/plugins"
 	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk/symbols/extract"
 	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk/symbols/fields"
@@ -41,8 +44,10 @@ type Plugin interface {
 
 // note: here the plugin Init method might have
 // called extract.SetAsync, which influences whether the async optimization
-// will be actually used or not. Potentially, extract.SetAsync might be
-// invoked with different boolean values at subsequent plugin initializations,
+// will be actually used or not, and extract.SetAsyncConfig, which configures
+// the async workers (e.g. from an extract.AsyncConfig in the init config).
+// Potentially, extract.SetAsync might be invoked with different boolean
+// values at subsequent plugin initializations,
 // however this code is still safe since:
 //   - Init methods are called in sequence and not concurrently. As such,
 //     every plugin invoking extract.SetAsync influences behavior of
@@ -53,9 +58,47 @@ type Plugin interface {
 //     is not influenced by the invocations of extract.SetAsync.
 func enableAsync(handle cgo.Handle) {
 	extract.StartAsync(handle)
-	hooks.SetOnBeforeDestroy(func(handle cgo.Handle) {
-		extract.StopAsync(handle)
-	})
+	hooks.SetOnBeforeDestroy(beforeDestroy)
+}
+
+// deprecations maps each plugin state handle to the *fields.Deprecations
+// keeping track of the deprecated fields it has been warned about
+var deprecations sync.Map
+
+func afterInit(handle cgo.Handle) {
+	for _, w := range fields.Warnings() {
+		logger.Log(handle, logger.SeverityWarning, w)
+	}
+	deprecations.Store(handle, fields.NewDeprecations())
+	enableAsync(handle)
+}
+
+func beforeDestroy(handle cgo.Handle) {
+	extract.StopAsync(handle)
+	deprecations.Delete(handle)
+}
+
+// asyncMetrics reports the statistics of the async extraction optimization
+// as plugin metrics
+func asyncMetrics(handle cgo.Handle) []sdk.Metric {
+	return extract.Stats().Metrics()
+}
+
+// beforeExtract resolves the aliased field of the request, if any, and checks
+// that the request is valid before passing it to Extract. The first request
+// of a deprecated field is extracted successfully, but a warning is sent to
+// the logger of the plugin's owner.
+func beforeExtract(handle cgo.Handle, req sdk.ExtractRequest) (sdk.ExtractRequest, error) {
+	req = fields.Resolve(req)
+	if err := fields.Validate(req); err != nil {
+		return nil, err
+	}
+	if d, ok := deprecations.Load(handle); ok {
+		if warn := d.(*fields.Deprecations).Warning(req); warn != nil {
+			logger.Log(handle, logger.SeverityWarning, warn.Error())
+		}
+	}
+	return req, nil
 }
 
 // Register registers the field extraction capability in the framework for the given Plugin.
@@ -66,6 +109,14 @@ func Register(p Plugin) {
 
 	fields.SetFields(p.Fields())
 
-	// setup hooks for automatically start/stop async extraction
-	hooks.SetOnAfterInit(enableAsync)
+	// resolve aliases and validate field arguments before invoking Extract
+	hooks.SetOnBeforeExtract(beforeExtract)
+
+	// setup hooks for automatically start/stop async extraction, and for
+	// warning each plugin state about the issues of the fields and the
+	// usage of deprecated ones
+	hooks.SetOnAfterInit(afterInit)
+
+	// report the async extraction statistics through plugin_get_metrics
+	hooks.SetOnMetrics(asyncMetrics)
 }
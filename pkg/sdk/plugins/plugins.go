@@ -18,11 +18,14 @@ limitations under the License.
 package plugins
 
 import (
+	"sync"
+
 	"github.com/falcosecurity/plugin-sdk-go/pkg/ptr"
 	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk"
 	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk/symbols/info"
 	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk/symbols/initialize"
 	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk/symbols/initschema"
+	_ "github.com/falcosecurity/plugin-sdk-go/pkg/sdk/symbols/metrics"
 )
 
 // Info is a struct containing the general information about a plugin.
@@ -44,6 +47,7 @@ type Info struct {
 type Plugin interface {
 	// (optional): sdk.Destroyer
 	// (optional): sdk.InitSchema
+	// (optional): sdk.Metrics
 	sdk.LastError
 	sdk.LastErrorBuffer
 	//
@@ -101,6 +105,34 @@ func (b *BaseLastError) LastErrorBuffer() sdk.StringBuffer {
 	return &b.lastErrBuf
 }
 
+// BasePoisoner is a base implementation of the sdk.Poisoner interface.
+// Developer-defined Plugin implementations can be composed with BasePoisoner
+// to make the SDK fail fast on every call following a panic in the
+// plugin code.
+//
+// BasePoisoner is safe to be used concurrently, because panics can be
+// recovered in any thread, such as in the async extraction workers.
+// Once poisoned, only the first error is kept until the poisoned state is
+// cleared by passing a nil error.
+type BasePoisoner struct {
+	poisonMu  sync.Mutex
+	poisonErr error
+}
+
+func (b *BasePoisoner) Poisoned() error {
+	b.poisonMu.Lock()
+	defer b.poisonMu.Unlock()
+	return b.poisonErr
+}
+
+func (b *BasePoisoner) SetPoisoned(err error) {
+	b.poisonMu.Lock()
+	defer b.poisonMu.Unlock()
+	if err == nil || b.poisonErr == nil {
+		b.poisonErr = err
+	}
+}
+
 // BaseStringer is a base implementation of the sdk.StringerBuffer interface.
 type BaseStringer struct {
 	stringerBuf ptr.StringBuffer
@@ -128,6 +160,18 @@ func (b *BaseOpenParams) OpenParamsBuffer() sdk.StringBuffer {
 	return &b.openParamsBuf
 }
 
+// BaseMetrics is a base implementation of the sdk.MetricsBuffer interface.
+type BaseMetrics struct {
+	metricsBuf sdk.MetricBuffer
+}
+
+func (b *BaseMetrics) MetricsBuffer() sdk.MetricBuffer {
+	if b.metricsBuf == nil {
+		b.metricsBuf = sdk.NewMetricBuffer()
+	}
+	return b.metricsBuf
+}
+
 // BasePlugin is a base implementation of the Plugin interface.
 // Developer-defined Plugin implementations should be composed with BasePlugin
 // to have out-of-the-box compliance with all the required interfaces.
@@ -136,6 +180,7 @@ type BasePlugin struct {
 	BaseStringer
 	BaseExtractRequests
 	BaseOpenParams
+	BaseMetrics
 }
 
 // FactoryFunc creates a new Plugin
@@ -19,6 +19,7 @@ package plugins
 
 import (
 	"errors"
+	"sync"
 	"testing"
 
 	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk"
@@ -66,6 +67,38 @@ func TestBaseLastError(t *testing.T) {
 	b.LastErrorBuffer().Free()
 }
 
+func TestBasePoisoner(t *testing.T) {
+	b := BasePoisoner{}
+	value := errors.New("test error")
+
+	if b.Poisoned() != nil {
+		t.Errorf("Poisoned: expected nil")
+	}
+	b.SetPoisoned(value)
+	if b.Poisoned() != value {
+		t.Errorf("Poisoned: value does not match")
+	}
+
+	// only the first error is kept, even with concurrent panics
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			b.SetPoisoned(errors.New("other error"))
+			_ = b.Poisoned()
+		}()
+	}
+	wg.Wait()
+	if b.Poisoned() != value {
+		t.Errorf("Poisoned: expected the first error to be kept")
+	}
+	b.SetPoisoned(nil)
+	if b.Poisoned() != nil {
+		t.Errorf("Poisoned: expected nil")
+	}
+}
+
 func TestBaseStringer(t *testing.T) {
 	b := BaseStringer{}
 	str := "test"
@@ -0,0 +1,280 @@
+// SPDX-License-Identifier: Apache-2.0
+/*
+Copyright (C) 2026 The Falco Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// CheckpointStore is an interface representing a persistent storage for
+// the cursor of a capture session. A cursor is an opaque byte sequence
+// defined by the plugin, that represents a position in the stream of events
+// (e.g. an offset in a file, or a sequence number in a remote log).
+type CheckpointStore interface {
+	// Load returns the last cursor saved with Save, or nil if no
+	// cursor has ever been saved.
+	Load() ([]byte, error)
+	//
+	// Save persists the given cursor.
+	Save(cursor []byte) error
+}
+
+// CheckpointKV is an interface representing a generic embedded key-value
+// database, such as bbolt. It can be used to create a CheckpointStore
+// with NewKVCheckpointStore.
+type CheckpointKV interface {
+	// Get returns the value associated to the given key, or nil if the key
+	// is not present.
+	Get(key []byte) ([]byte, error)
+	//
+	// Put associates the given value to the given key.
+	Put(key, value []byte) error
+}
+
+type fileCheckpointStore struct {
+	path string
+}
+
+// NewFileCheckpointStore returns a CheckpointStore that persists cursors
+// in the file at the given path. The file is replaced atomically at each
+// save, so that a crash can't leave a partially-written cursor behind.
+func NewFileCheckpointStore(path string) CheckpointStore {
+	return &fileCheckpointStore{path: path}
+}
+
+func (f *fileCheckpointStore) Load() ([]byte, error) {
+	b, err := os.ReadFile(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	return b, err
+}
+
+func (f *fileCheckpointStore) Save(cursor []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(f.path), filepath.Base(f.path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = tmp.Write(cursor); err == nil {
+		err = tmp.Sync()
+	}
+	if cErr := tmp.Close(); err == nil {
+		err = cErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), f.path)
+}
+
+type kvCheckpointStore struct {
+	kv  CheckpointKV
+	key []byte
+}
+
+// NewKVCheckpointStore returns a CheckpointStore that persists cursors
+// in the given key-value database, associated to the given key. Using
+// distinct keys allows multiple capture sessions to share the same database.
+func NewKVCheckpointStore(kv CheckpointKV, key string) CheckpointStore {
+	return &kvCheckpointStore{kv: kv, key: []byte(key)}
+}
+
+func (k *kvCheckpointStore) Load() ([]byte, error) {
+	return k.kv.Get(k.key)
+}
+
+func (k *kvCheckpointStore) Save(cursor []byte) error {
+	return k.kv.Put(k.key, cursor)
+}
+
+// Checkpointer keeps track of the position of a capture session, so that
+// a later one can resume from where it stopped. It can be used with the
+// pre-built event source instances through the WithInstanceCheckpointer
+// option.
+//
+// The instance records a cursor for each event it emits. The cursor of an
+// event is committed once the framework consumed the batch containing it,
+// which happens at the following invocation of NextBatch() or when the
+// instance gets closed. Committed cursors are persisted in the
+// CheckpointStore at most once per the configured interval, and when the
+// instance gets closed.
+//
+// A typical usage is to create a Checkpointer in the Open method of the
+// plugin, and use the cursor returned by Cursor() to resume reading from
+// the last committed position before opening the instance.
+type Checkpointer struct {
+	m         sync.Mutex
+	store     CheckpointStore
+	interval  time.Duration
+	lastSave  time.Time
+	err       error
+	staged    []byte // cursor recorded for the event being produced
+	batch     []byte // cursor of the last event of the current batch
+	committed []byte // cursor of the last consumed event
+	dirty     bool   // true if committed has not been saved yet
+}
+
+// NewCheckpointer creates a new Checkpointer that persists cursors in the
+// given CheckpointStore, at most once per the given interval. A zero interval
+// causes cursors to be persisted as soon as they are committed. The last
+// cursor saved in the store is loaded and returned by Cursor().
+func NewCheckpointer(store CheckpointStore, interval time.Duration) (*Checkpointer, error) {
+	cursor, err := store.Load()
+	if err != nil {
+		return nil, err
+	}
+	return &Checkpointer{
+		store:     store,
+		interval:  interval,
+		lastSave:  time.Now(),
+		committed: cursor,
+	}, nil
+}
+
+// Cursor returns the cursor of the last committed event, or nil if
+// no event has ever been committed.
+func (c *Checkpointer) Cursor() []byte {
+	c.m.Lock()
+	defer c.m.Unlock()
+	return c.committed
+}
+
+// Record sets the cursor of the event being produced. This should be invoked
+// from a PullFunc for each event it writes successfully. The cursor is
+// discarded if the PullFunc returns a non-nil error, unless the error matches
+// sdk.ErrSkip. The passed-in slice can
+// be reused after Record returns. Empty cursors, including nil ones, are
+// committed as any other cursor.
+//
+// Events produced by push instances carry their cursor in PushEvent instead.
+func (c *Checkpointer) Record(cursor []byte) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	c.staged = copyCursor(c.staged, cursor)
+}
+
+// Err returns the error of the last failed attempt to persist a cursor,
+// or nil if the last attempt succeeded. Failed attempts are retried at
+// the next commit.
+func (c *Checkpointer) Err() error {
+	c.m.Lock()
+	defer c.m.Unlock()
+	return c.err
+}
+
+// Flush persists the last committed cursor immediately, if it has not
+// been persisted yet.
+func (c *Checkpointer) Flush() error {
+	c.m.Lock()
+	defer c.m.Unlock()
+	return c.save()
+}
+
+// accept adds the staged cursor to the current batch, if any
+func (c *Checkpointer) accept() {
+	c.m.Lock()
+	defer c.m.Unlock()
+	if c.staged != nil {
+		c.batch = c.staged
+		c.staged = nil
+	}
+}
+
+// discard drops the staged cursor
+func (c *Checkpointer) discard() {
+	c.m.Lock()
+	defer c.m.Unlock()
+	c.staged = nil
+}
+
+// add adds the given cursor to the current batch
+func (c *Checkpointer) add(cursor []byte) {
+	if cursor == nil {
+		return
+	}
+	c.m.Lock()
+	defer c.m.Unlock()
+	c.batch = copyCursor(c.batch, cursor)
+}
+
+// copyCursor copies cursor in buf, reusing its memory if possible. The
+// result is never nil, so that empty cursors are not mistaken for the
+// absence of a cursor.
+func copyCursor(buf, cursor []byte) []byte {
+	if buf == nil {
+		buf = make([]byte, 0, len(cursor))
+	}
+	return append(buf[:0], cursor...)
+}
+
+// commit marks the current batch as consumed, and persists its cursor if
+// force is true or if the configured interval elapsed since the last save
+func (c *Checkpointer) commit(force bool) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	if c.batch != nil {
+		c.committed = c.batch
+		c.batch = nil
+		c.dirty = true
+	}
+	if force || time.Since(c.lastSave) >= c.interval {
+		c.save()
+	}
+}
+
+// take returns the cursor of the current batch and removes it from the
+// batch, so that it can be committed later with commitCursor
+func (c *Checkpointer) take() []byte {
+	c.m.Lock()
+	defer c.m.Unlock()
+	cursor := c.batch
+	c.batch = nil
+	return cursor
+}
+
+// commitCursor marks the given cursor as consumed, and persists it if
+// force is true or if the configured interval elapsed since the last save.
+// A nil cursor leaves the committed one unchanged.
+func (c *Checkpointer) commitCursor(cursor []byte, force bool) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	if cursor != nil {
+		c.committed = cursor
+		c.dirty = true
+	}
+	if force || time.Since(c.lastSave) >= c.interval {
+		c.save()
+	}
+}
+
+func (c *Checkpointer) save() error {
+	if !c.dirty {
+		return nil
+	}
+	c.lastSave = time.Now()
+	c.err = c.store.Save(c.committed)
+	if c.err == nil {
+		c.dirty = false
+	}
+	return c.err
+}
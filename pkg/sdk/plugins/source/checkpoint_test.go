@@ -0,0 +1,212 @@
+// SPDX-License-Identifier: Apache-2.0
+/*
+Copyright (C) 2026 The Falco Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk"
+	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk/sdktest"
+)
+
+type sampleCheckpointKV map[string][]byte
+
+func (s sampleCheckpointKV) Get(key []byte) ([]byte, error) {
+	return s[string(key)], nil
+}
+
+func (s sampleCheckpointKV) Put(key, value []byte) error {
+	s[string(key)] = append([]byte{}, value...)
+	return nil
+}
+
+func testCheckpointStore(t *testing.T, store CheckpointStore) {
+	cursor, err := store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cursor != nil {
+		t.Errorf("expected nil cursor, but found %v", cursor)
+	}
+	for _, c := range []string{"first", "second"} {
+		if err := store.Save([]byte(c)); err != nil {
+			t.Fatal(err)
+		}
+		cursor, err = store.Load()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(cursor) != c {
+			t.Errorf("expected %s, but found %s", c, string(cursor))
+		}
+	}
+}
+
+func TestFileCheckpointStore(t *testing.T) {
+	testCheckpointStore(t, NewFileCheckpointStore(filepath.Join(t.TempDir(), "cursor")))
+}
+
+func TestKVCheckpointStore(t *testing.T) {
+	kv := sampleCheckpointKV{}
+	testCheckpointStore(t, NewKVCheckpointStore(kv, "test"))
+	if string(kv["test"]) != "second" {
+		t.Errorf("expected %s, but found %s", "second", string(kv["test"]))
+	}
+}
+
+func TestPullInstanceCheckpointer(t *testing.T) {
+	batch := &sdktest.InMemoryEventWriters{}
+	for i := 0; i < 2; i++ {
+		batch.Writers = append(batch.Writers, &sdktest.InMemoryEventWriter{})
+	}
+
+	store := NewKVCheckpointStore(sampleCheckpointKV{}, "test")
+	cp, err := NewCheckpointer(store, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the cursor of failed pulls is discarded
+	nEvt := 0
+	pull := func(c context.Context, e sdk.EventWriter) error {
+		nEvt++
+		cp.Record([]byte(strconv.Itoa(nEvt)))
+		if nEvt == 4 {
+			return sdk.ErrEOF
+		}
+		return nil
+	}
+	inst, err := NewPullInstance(pull, WithInstanceCheckpointer(cp))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// cursors are committed only once batches are consumed
+	if _, err := inst.NextBatch(nil, batch); err != nil {
+		t.Fatal(err)
+	}
+	if c, _ := store.Load(); c != nil {
+		t.Errorf("expected nil cursor, but found %s", string(c))
+	}
+	if n, err := inst.NextBatch(nil, batch); err != sdk.ErrEOF || n != 1 {
+		t.Fatalf("expected %d and sdk.ErrEOF, but found %d and %v", 1, n, err)
+	}
+	if c, _ := store.Load(); string(c) != "2" {
+		t.Errorf("expected %s, but found %s", "2", string(c))
+	}
+	inst.(sdk.Closer).Close()
+	if c, _ := store.Load(); string(c) != "3" {
+		t.Errorf("expected %s, but found %s", "3", string(c))
+	}
+
+	// a new checkpointer resumes from the last cursor
+	cp, err = NewCheckpointer(store, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(cp.Cursor()) != "3" {
+		t.Errorf("expected %s, but found %s", "3", string(cp.Cursor()))
+	}
+}
+
+func TestPushInstanceCheckpointer(t *testing.T) {
+	batch := &sdktest.InMemoryEventWriters{}
+	for i := 0; i < 2; i++ {
+		batch.Writers = append(batch.Writers, &sdktest.InMemoryEventWriter{})
+	}
+
+	store := NewKVCheckpointStore(sampleCheckpointKV{}, "test")
+	cp, err := NewCheckpointer(store, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	evtChan := make(chan PushEvent, 3)
+	evtChan <- PushEvent{Data: []byte{0}, Cursor: []byte("1")}
+	evtChan <- PushEvent{Data: []byte{0}, Cursor: []byte("2")}
+	evtChan <- PushEvent{Data: []byte{0}}
+	close(evtChan)
+	inst, err := NewPushInstance(evtChan, WithInstanceCheckpointer(cp))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, err := inst.NextBatch(nil, batch); err != nil || n != 2 {
+		t.Fatalf("expected %d and no error, but found %d and %v", 2, n, err)
+	}
+	if n, err := inst.NextBatch(nil, batch); err != sdk.ErrEOF || n != 1 {
+		t.Fatalf("expected %d and sdk.ErrEOF, but found %d and %v", 1, n, err)
+	}
+
+	// cursors are not saved before the interval elapses, unless flushed
+	if string(cp.Cursor()) != "2" {
+		t.Errorf("expected %s, but found %s", "2", string(cp.Cursor()))
+	}
+	if c, _ := store.Load(); c != nil {
+		t.Errorf("expected nil cursor, but found %s", string(c))
+	}
+	if err := cp.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if c, _ := store.Load(); string(c) != "2" {
+		t.Errorf("expected %s, but found %s", "2", string(c))
+	}
+
+	// events without cursor don't change the committed one
+	inst.(sdk.Closer).Close()
+	if c, _ := store.Load(); string(c) != "2" {
+		t.Errorf("expected %s, but found %s", "2", string(c))
+	}
+}
+
+func TestCheckpointerEmptyCursor(t *testing.T) {
+	batch := &sdktest.InMemoryEventWriters{}
+	batch.Writers = append(batch.Writers, &sdktest.InMemoryEventWriter{})
+
+	store := NewFileCheckpointStore(filepath.Join(t.TempDir(), "cursor"))
+	cp, err := NewCheckpointer(store, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pull := func(c context.Context, e sdk.EventWriter) error {
+		cp.Record(nil)
+		return nil
+	}
+	inst, err := NewPullInstance(pull, WithInstanceCheckpointer(cp))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := inst.NextBatch(nil, batch); err != nil {
+		t.Fatal(err)
+	}
+	inst.(sdk.Closer).Close()
+
+	// empty cursors are committed and persisted as any other
+	if c := cp.Cursor(); c == nil || len(c) != 0 {
+		t.Errorf("expected empty cursor, but found %v", c)
+	}
+	cp, err = NewCheckpointer(store, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c := cp.Cursor(); c == nil || len(c) != 0 {
+		t.Errorf("expected empty cursor, but found %v", c)
+	}
+}
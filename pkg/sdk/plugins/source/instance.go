@@ -19,9 +19,11 @@ package source
 
 import (
 	"context"
-	"io"
-	"math"
+	"errors"
+	"fmt"
+	"sync/atomic"
 	"time"
+	"unsafe"
 
 	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk"
 )
@@ -31,15 +33,89 @@ var (
 )
 
 type builtinInstance struct {
+	// oversized is the number of events affected by the oversize policy,
+	// and comes first to be 64-bit aligned for atomic accesses
+	oversized uint64
 	BaseInstance
-	shutdown      func()
-	progress      func() (float64, string)
-	ctx           context.Context
-	timeout       time.Duration
-	timeoutTicker *time.Ticker
-	eof           bool
-	eventSize     uint32
-	batchSize     uint32
+	shutdown       func()
+	cancel         context.CancelFunc
+	progress       func() (float64, string)
+	ctx            context.Context
+	timeout        time.Duration
+	timeoutTicker  *time.Ticker
+	eof            bool
+	eventSize      uint32
+	eventInitSize  uint32
+	growable       bool
+	batchSize      uint32
+	checkpointer   *Checkpointer
+	bufferSize     uint32
+	bufferPolicy   BufferPolicy
+	mergeOrdered   bool
+	mergeWindow    time.Duration
+	mergeOnEOF     MergePolicy
+	mergeOnError   MergePolicy
+	oversizePolicy OversizePolicy
+	truncateMarker []byte
+	tsPolicy       TimestampPolicy
+	tsStart        time.Time
+	tsLast         uint64
+	limiter        *rateLimiter
+	retryPolicy    *RetryPolicy
+	retries        int
+	retryAt        time.Time
+}
+
+// init applies the given options and initializes the event batch, the
+// timeout timer, and the internally-cancellable context of the instance
+func (s *builtinInstance) init(options []func(*builtinInstance)) error {
+	s.ctx = context.Background()
+	s.timeout = defaultInstanceTimeout
+	s.shutdown = func() {}
+	s.eof = false
+	s.batchSize = sdk.DefaultBatchSize
+	s.eventSize = sdk.DefaultEvtSize
+	s.mergeOnEOF = MergeContinue
+	s.mergeOnError = MergeStop
+	s.oversizePolicy = OversizeFail
+	s.truncateMarker = DefaultTruncateMarker
+	s.tsPolicy = TimestampSource
+	s.tsStart = time.Now()
+
+	// apply options
+	for _, opt := range options {
+		opt(s)
+	}
+	if s.bufferPolicy < BufferBlock || s.bufferPolicy > BufferDropNewest {
+		return fmt.Errorf("unknown buffer policy: %d", s.bufferPolicy)
+	}
+
+	// create custom-sized event batch
+	var batch sdk.EventWriters
+	var err error
+	if s.growable {
+		batch, err = sdk.NewGrowableEventWriters(int64(s.batchSize), int64(s.eventInitSize), int64(s.eventSize))
+	} else {
+		batch, err = sdk.NewEventWriters(int64(s.batchSize), int64(s.eventSize))
+	}
+	if err != nil {
+		return err
+	}
+	s.SetEvents(batch)
+
+	// init timer
+	s.timeoutTicker = time.NewTicker(s.timeout)
+
+	// setup internally-cancellable context
+	prevCancel := s.shutdown
+	cancelableCtx, cancelCtx := context.WithCancel(s.ctx)
+	s.ctx = cancelableCtx
+	s.cancel = cancelCtx
+	s.shutdown = func() {
+		cancelCtx()
+		prevCancel()
+	}
+	return nil
 }
 
 func (s *builtinInstance) Close() {
@@ -48,6 +124,11 @@ func (s *builtinInstance) Close() {
 
 	// stop timeout ticker
 	s.timeoutTicker.Stop()
+
+	// the last batch has been consumed, so we persist its cursor
+	if s.checkpointer != nil {
+		s.checkpointer.commit(true)
+	}
 }
 
 func (s *builtinInstance) Progress(pState sdk.PluginState) (float64, string) {
@@ -100,6 +181,76 @@ func WithInstanceEventSize(size uint32) func(*builtinInstance) {
 	}
 }
 
+// WithInstanceGrowableEvents makes the memory of each event of the batch
+// used by NextBatch() allocated lazily with room for initSize bytes, and
+// grown on demand up to the maximum event size. This makes the memory used
+// by the event source scale with the actual size of the events produced.
+// See sdk.NewGrowableEventWriters.
+func WithInstanceGrowableEvents(initSize uint32) func(*builtinInstance) {
+	return func(s *builtinInstance) {
+		s.growable = true
+		s.eventInitSize = initSize
+	}
+}
+
+// TimestampPolicy represents how an event source assigns a timestamp to
+// the events produced without one.
+type TimestampPolicy int
+
+const (
+	// TimestampSource leaves the timestamp unset, which makes the
+	// framework assign it automatically
+	TimestampSource TimestampPolicy = iota
+	//
+	// TimestampWallclock uses the current wall clock time
+	TimestampWallclock
+	//
+	// TimestampMonotonic uses the wall clock time at which the event source
+	// has been opened, advanced with a monotonic clock. This makes timestamps
+	// strictly increasing, regardless of changes to the system clock
+	TimestampMonotonic
+)
+
+// WithInstanceTimestamp sets the policy used in the opened event source
+// to assign a timestamp to the events produced without one. By default,
+// TimestampSource is used.
+func WithInstanceTimestamp(policy TimestampPolicy) func(*builtinInstance) {
+	return func(s *builtinInstance) {
+		s.tsPolicy = policy
+	}
+}
+
+// timestamp returns a timestamp for an event produced without one,
+// according to the timestamp policy
+func (s *builtinInstance) timestamp() uint64 {
+	switch s.tsPolicy {
+	case TimestampWallclock:
+		return uint64(time.Now().UnixNano())
+	case TimestampMonotonic:
+		// note: time.Since uses the monotonic clock reading of tsStart
+		ts := uint64(s.tsStart.UnixNano()) + uint64(time.Since(s.tsStart))
+		if ts <= s.tsLast {
+			ts = s.tsLast + 1
+		}
+		s.tsLast = ts
+		return ts
+	default:
+		return sdk.UnsetTimestamp
+	}
+}
+
+// timestampEventWriter is an sdk.EventWriter that keeps track of whether
+// a timestamp has been set
+type timestampEventWriter struct {
+	sdk.EventWriter
+	set bool
+}
+
+func (t *timestampEventWriter) SetTimestamp(value uint64) {
+	t.set = value != sdk.UnsetTimestamp
+	t.EventWriter.SetTimestamp(value)
+}
+
 // WithInstanceProgress sets a custom callback for the framework to request
 // a the progress state of the opened event stream
 func WithInstanceProgress(progress func() (float64, string)) func(*builtinInstance) {
@@ -108,6 +259,48 @@ func WithInstanceProgress(progress func() (float64, string)) func(*builtinInstan
 	}
 }
 
+// BufferPolicy represents the policy used by a push instance with a bounded
+// event buffer when a new event is received and the buffer is full.
+type BufferPolicy int
+
+const (
+	// BufferBlock blocks the producer until there is room in the buffer
+	BufferBlock BufferPolicy = iota
+	//
+	// BufferDropOldest drops the oldest event in the buffer. Events
+	// carrying an error are never dropped, and if the buffer only
+	// contains those the received event is dropped instead.
+	BufferDropOldest
+	//
+	// BufferDropNewest drops the received event
+	BufferDropNewest
+)
+
+// WithInstanceBuffer sets a bounded buffer of the given size in the opened
+// event source, in which events are received while waiting for the framework
+// to request a new batch. When the buffer is full, new events are handled
+// according to the given policy. Events with a non-nil Err are never dropped.
+// Opening the event source fails if the policy is not one of the
+// BufferPolicy constants.
+// This only affects the event sources opened with NewPushInstance.
+func WithInstanceBuffer(size uint32, policy BufferPolicy) func(*builtinInstance) {
+	return func(s *builtinInstance) {
+		s.bufferSize = size
+		s.bufferPolicy = policy
+	}
+}
+
+// WithInstanceCheckpointer sets a Checkpointer in the opened event source,
+// which records the cursor of each emitted event and periodically persists
+// the one of the last event consumed by the framework. For pull instances,
+// the cursor of each event is set with the Record method of Checkpointer.
+// For push instances, it is set with the Cursor field of PushEvent.
+func WithInstanceCheckpointer(c *Checkpointer) func(*builtinInstance) {
+	return func(s *builtinInstance) {
+		s.checkpointer = c
+	}
+}
+
 // PullFunc produces a new event and returns a non-nil error in case of failure.
 //
 // The event data is produced through the sdk.EventWriter interface.
@@ -118,7 +311,8 @@ type PullFunc func(context.Context, sdk.EventWriter) error
 
 type pullInstance struct {
 	builtinInstance
-	pull PullFunc
+	pull     PullFunc
+	tsWriter timestampEventWriter
 }
 
 // NewPullInstance opens a new event source and starts a capture session,
@@ -138,51 +332,151 @@ type pullInstance struct {
 // Users can pass option parameters to influence the behavior of the opened
 // event source, such as passing a context or setting a custom timeout duration.
 //
+// Any fatal error returned by the pull function ends the event source (see
+// sdk.IsFatal), unless it is retried as configured with WithInstanceRetry.
+// Errors matching sdk.ErrSkip make the event be skipped, and errors matching
+// sdk.ErrTemporary are returned to the framework without ending the event
+// source.
+//
 // The context passed-in to the pull function is cancelled automatically
 // when the framework invokes Close() on the event source, or when the
 // user-configured context is cancelled.
 func NewPullInstance(pull PullFunc, options ...func(*builtinInstance)) (Instance, error) {
-	res := &pullInstance{
-		pull: pull,
-		builtinInstance: builtinInstance{
-			ctx:       context.Background(),
-			timeout:   defaultInstanceTimeout,
-			shutdown:  func() {},
-			eof:       false,
-			batchSize: sdk.DefaultBatchSize,
-			eventSize: sdk.DefaultEvtSize,
-		},
+	res := &pullInstance{pull: pull}
+	if err := res.init(options); err != nil {
+		return nil, err
 	}
+	return res, nil
+}
 
-	// apply options
-	for _, opt := range options {
-		opt(&res.builtinInstance)
+func (s *pullInstance) NextBatch(pState sdk.PluginState, evts sdk.EventWriters) (n int, err error) {
+	// the previous batch has been consumed, so we commit its cursor
+	if s.checkpointer != nil {
+		s.checkpointer.commit(false)
 	}
 
-	// create custom-sized event batch
-	batch, err := sdk.NewEventWriters(int64(res.batchSize), int64(res.eventSize))
-	if err != nil {
-		return nil, err
+	// once EOF has been hit, we should return it at each new call of NextBatch
+	if s.eof {
+		return 0, sdk.ErrEOF
 	}
-	res.SetEvents(batch)
 
-	// init timer
-	res.timeoutTicker = time.NewTicker(res.timeout)
+	// timeout needs to be resetted for this batch
+	s.timeoutTicker.Reset(s.timeout)
 
-	// setup internally-cancellable context
-	prevCancel := res.shutdown
-	cancelableCtx, cancelCtx := context.WithCancel(res.ctx)
-	res.ctx = cancelableCtx
-	res.shutdown = func() {
-		cancelCtx()
-		prevCancel()
+	// attempt filling the event batch
+	n = 0
+	for n < evts.Len() {
+		// check if we should return before pulling another event
+		select {
+		// timeout hits, so we flush a partial batch
+		case <-s.timeoutTicker.C:
+			return n, sdk.ErrTimeout
+		// context has been canceled, so we exit
+		case <-s.ctx.Done():
+			s.eof = true
+			return n, sdk.ErrEOF
+		default:
+		}
+
+		// wait for the scheduled retry and the rate limit, if any
+		if _, err = s.ready(1); err != nil {
+			return n, err
+		}
+
+		// pull a new event, keeping track of its timestamp if needed.
+		// Writers are reused across batches, so we reset the values
+		// set for the previous events
+		evt := evts.Get(n)
+		evt.SetTimestamp(sdk.UnsetTimestamp)
+		evt.SetTID(sdk.UnsetTID)
+		if s.tsPolicy != TimestampSource {
+			s.tsWriter = timestampEventWriter{EventWriter: evt}
+			evt = &s.tsWriter
+		}
+		if err = s.pull(s.ctx, evt); err != nil {
+			// skipped events are not added in the batch, but their
+			// cursor is, so that they are not produced again
+			if errors.Is(err, sdk.ErrSkip) {
+				if s.checkpointer != nil {
+					s.checkpointer.accept()
+				}
+				s.retries = 0
+				err = nil
+				continue
+			}
+			if s.checkpointer != nil {
+				s.checkpointer.discard()
+			}
+			// transient errors are retried later, if requested
+			if s.retry(err) {
+				err = nil
+				continue
+			}
+			// in case of fatal error, we consider the event source ended
+			if sdk.IsFatal(err) {
+				s.eof = true
+			}
+			return n, err
+		}
+		if s.tsPolicy != TimestampSource && !s.tsWriter.set {
+			s.tsWriter.EventWriter.SetTimestamp(s.timestamp())
+		}
+		if s.limiter != nil {
+			s.limiter.consume(1)
+		}
+		s.retries = 0
+		if s.checkpointer != nil {
+			s.checkpointer.accept()
+		}
+		n++
 	}
 
-	// return opened instance
+	// return a full batch
+	return n, nil
+}
+
+// BatchPullFunc produces a batch of new events, and returns the number of
+// events produced and a non-nil error in case of failure.
+//
+// The event data is produced through the sdk.EventWriters interface, by
+// writing events from the start of the list. The number of events produced
+// must not exceed the list length, and can be lower than it. Just like for
+// PullFunc, the context argument can be used to check for termination signals.
+type BatchPullFunc func(context.Context, sdk.EventWriters) (int, error)
+
+type batchPullInstance struct {
+	builtinInstance
+	pull      BatchPullFunc
+	tsWriters []timestampEventWriter
+}
+
+// NewBatchPullInstance opens a new event source and starts a capture session,
+// filling the event batches with a pull model in which many events can be
+// produced at once.
+//
+// This is analogous to NewPullInstance, and is suitable for cases in which
+// events are naturally received in groups, such as when reading pages of
+// records from a remote API. The BatchPullFunc required argument is invoked
+// with the portion of the event batch that still needs to be filled, until
+// the batch is full, the timeout is reached, or an error is returned. The
+// events produced before an error are returned to the framework.
+//
+// When using a Checkpointer, the BatchPullFunc should invoke its Record
+// method with the cursor of the last event produced.
+func NewBatchPullInstance(pull BatchPullFunc, options ...func(*builtinInstance)) (Instance, error) {
+	res := &batchPullInstance{pull: pull}
+	if err := res.init(options); err != nil {
+		return nil, err
+	}
 	return res, nil
 }
 
-func (s *pullInstance) NextBatch(pState sdk.PluginState, evts sdk.EventWriters) (n int, err error) {
+func (s *batchPullInstance) NextBatch(pState sdk.PluginState, evts sdk.EventWriters) (n int, err error) {
+	// the previous batch has been consumed, so we commit its cursor
+	if s.checkpointer != nil {
+		s.checkpointer.commit(false)
+	}
+
 	// once EOF has been hit, we should return it at each new call of NextBatch
 	if s.eof {
 		return 0, sdk.ErrEOF
@@ -191,10 +485,22 @@ func (s *pullInstance) NextBatch(pState sdk.PluginState, evts sdk.EventWriters)
 	// timeout needs to be resetted for this batch
 	s.timeoutTicker.Reset(s.timeout)
 
+	// keep track of the event timestamps if needed
+	var tsWriters []timestampEventWriter
+	if s.tsPolicy != TimestampSource {
+		if len(s.tsWriters) < evts.Len() {
+			s.tsWriters = make([]timestampEventWriter, evts.Len())
+		}
+		tsWriters = s.tsWriters[:evts.Len()]
+		for i := range tsWriters {
+			tsWriters[i] = timestampEventWriter{EventWriter: evts.Get(i)}
+		}
+	}
+
 	// attempt filling the event batch
 	n = 0
 	for n < evts.Len() {
-		// check if we should return before pulling another event
+		// check if we should return before pulling more events
 		select {
 		// timeout hits, so we flush a partial batch
 		case <-s.timeoutTicker.C:
@@ -206,21 +512,98 @@ func (s *pullInstance) NextBatch(pState sdk.PluginState, evts sdk.EventWriters)
 		default:
 		}
 
-		// pull a new event
-		if err = s.pull(s.ctx, evts.Get(n)); err != nil {
-			// in case of non-timeout error, we consider the event source ended
-			if err != sdk.ErrTimeout {
+		// wait for the scheduled retry and the rate limit, if any
+		var size int
+		if size, err = s.ready(evts.Len() - n); err != nil {
+			return n, err
+		}
+
+		// pull new events in the remaining portion of the batch, after
+		// resetting the values set for the previous events
+		for i := n; i < n+size; i++ {
+			evts.Get(i).SetTimestamp(sdk.UnsetTimestamp)
+			evts.Get(i).SetTID(sdk.UnsetTID)
+		}
+		var m int
+		m, err = s.pull(s.ctx, &eventWritersView{EventWriters: evts, offset: n, size: size, writers: tsWriters})
+		if m < 0 || m > size {
+			panic(fmt.Sprintf("plugin-sdk-go/sdk/plugins/source: batch pull function produced %d events, but only %d were available", m, size))
+		}
+		for i := n; i < n+m && tsWriters != nil; i++ {
+			if !tsWriters[i].set {
+				evts.Get(i).SetTimestamp(s.timestamp())
+			}
+		}
+		n += m
+		skip := errors.Is(err, sdk.ErrSkip)
+		if m > 0 || skip {
+			if s.limiter != nil {
+				s.limiter.consume(m)
+			}
+			s.retries = 0
+		}
+		if s.checkpointer != nil {
+			if m > 0 || skip {
+				s.checkpointer.accept()
+			} else {
+				s.checkpointer.discard()
+			}
+		}
+		if err != nil {
+			// skipped events are just not produced
+			if skip {
+				err = nil
+				continue
+			}
+			// transient errors are retried later, if requested
+			if s.retry(err) {
+				err = nil
+				continue
+			}
+			// in case of fatal error, we consider the event source ended
+			if sdk.IsFatal(err) {
 				s.eof = true
 			}
 			return n, err
 		}
-		n++
 	}
 
 	// return a full batch
 	return n, nil
 }
 
+// eventWritersView is an sdk.EventWriters representing the portion of
+// another sdk.EventWriters that starts at a given offset and has a given
+// size. If writers is non-nil, the events are accessed through it instead.
+type eventWritersView struct {
+	sdk.EventWriters
+	offset  int
+	size    int
+	writers []timestampEventWriter
+}
+
+func (e *eventWritersView) Get(eventIndex int) sdk.EventWriter {
+	if e.writers != nil {
+		return &e.writers[e.offset+eventIndex]
+	}
+	return e.EventWriters.Get(e.offset + eventIndex)
+}
+
+func (e *eventWritersView) Len() int {
+	return e.size
+}
+
+func (e *eventWritersView) ArrayPtr() unsafe.Pointer {
+	if p := e.EventWriters.ArrayPtr(); p != nil {
+		return unsafe.Add(p, e.offset*int(unsafe.Sizeof(uintptr(0))))
+	}
+	return nil
+}
+
+func (e *eventWritersView) Free() {
+	// the memory is owned by the viewed sdk.EventWriters
+}
+
 // PushEvent represents an event produced from an event source with the push model.
 //
 // If the event source produced the event successfully, then Data must be non-nil
@@ -229,15 +612,56 @@ func (s *pullInstance) NextBatch(pState sdk.PluginState, evts sdk.EventWriters)
 //
 // Timestamp can be optionally set to indicate a specific timestamp for the
 // produced event.
+//
+// Cursor can be optionally set to indicate the position of the produced event,
+// and is recorded if the event source has a Checkpointer.
+// See WithInstanceCheckpointer.
 type PushEvent struct {
 	Err       error
 	Data      []byte
 	Timestamp time.Time
+	Cursor    []byte
+}
+
+// PushStats contains the event counters of an event source opened with
+// NewPushInstance
+type PushStats struct {
+	// Received is the number of events received from the producer,
+	// including the ones with an Err matching sdk.ErrSkip
+	Received uint64
+	//
+	// Dropped is the number of received events that have been dropped
+	// due to the buffer being full, due to the OversizeDrop policy, or
+	// due to being skipped by the producer
+	Dropped uint64
+	//
+	// Emitted is the number of events returned to the framework. Events
+	// split by the OversizeSplit policy count once for each part.
+	Emitted uint64
+	//
+	// Oversized is the number of received events exceeding the maximum
+	// event size, which have been handled as for the oversize policy.
+	// See WithInstanceOversize.
+	Oversized uint64
+}
+
+func (p PushStats) String() string {
+	return fmt.Sprintf("received: %d, dropped: %d, emitted: %d, oversized: %d", p.Received, p.Dropped, p.Emitted, p.Oversized)
+}
+
+// PushStatser is an interface implemented by the event sources opened with
+// NewPushInstance, providing their event counters.
+type PushStatser interface {
+	// PushStats returns the current event counters of the event source.
+	// This is safe to be invoked concurrently.
+	PushStats() PushStats
 }
 
 type pushInstance struct {
 	builtinInstance
-	evtC <-chan PushEvent
+	evtC    <-chan PushEvent
+	stats   *PushStats
+	pending *PushEvent
 }
 
 // NewPushInstance opens a new event source and starts a capture session,
@@ -253,48 +677,148 @@ type pushInstance struct {
 //
 // The opened event source can be manually closed by cancelling the optional
 // passed-in context, by closing the event cannel, or by sending
-// source.PushEvent containing a non-nil Err.
+// source.PushEvent containing a fatal Err (see sdk.IsFatal). Events with an
+// Err matching sdk.ErrSkip are dropped.
+//
+// By default, the producer blocks until the framework requests a new batch.
+// A bounded buffer with a given overflow policy can be set with the
+// WithInstanceBuffer option. The returned Instance implements PushStatser,
+// and if no custom progress callback is set its progress string reports
+// the event counters.
 func NewPushInstance(evtC <-chan PushEvent, options ...func(*builtinInstance)) (Instance, error) {
-	res := &pushInstance{
-		evtC: evtC,
-		builtinInstance: builtinInstance{
-			ctx:       context.Background(),
-			timeout:   defaultInstanceTimeout,
-			shutdown:  func() {},
-			eof:       false,
-			batchSize: sdk.DefaultBatchSize,
-			eventSize: sdk.DefaultEvtSize,
-		},
+	res := &pushInstance{evtC: evtC, stats: &PushStats{}}
+	if err := res.init(options); err != nil {
+		return nil, err
 	}
 
-	// apply options
-	for _, opt := range options {
-		opt(&res.builtinInstance)
+	// setup the bounded buffer, if requested
+	if res.bufferSize > 0 {
+		bufC := make(chan PushEvent, res.bufferSize)
+		go res.buffer(evtC, bufC)
+		res.evtC = bufC
 	}
+	return res, nil
+}
 
-	// create custom-sized event batch
-	batch, err := sdk.NewEventWriters(int64(res.batchSize), int64(res.eventSize))
-	if err != nil {
-		return nil, err
+func (s *pushInstance) PushStats() PushStats {
+	return PushStats{
+		Received:  atomic.LoadUint64(&s.stats.Received),
+		Dropped:   atomic.LoadUint64(&s.stats.Dropped),
+		Emitted:   atomic.LoadUint64(&s.stats.Emitted),
+		Oversized: atomic.LoadUint64(&s.oversized),
 	}
-	res.SetEvents(batch)
+}
 
-	// init timer
-	res.timeoutTicker = time.NewTicker(res.timeout)
+func (s *pushInstance) Progress(pState sdk.PluginState) (float64, string) {
+	if s.progress != nil {
+		return s.progress()
+	}
+	return 0, s.PushStats().String()
+}
 
-	// setup internally-cancellable context
-	prevCancel := res.shutdown
-	cancelableCtx, cancelCtx := context.WithCancel(res.ctx)
-	res.ctx = cancelableCtx
-	res.shutdown = func() {
-		cancelCtx()
-		prevCancel()
+// buffer forwards the events received from in to the buffered channel out,
+// applying the configured policy when out is full. out is closed once in is
+// closed, once an event with a fatal error is forwarded, or once the
+// instance context is cancelled.
+func (s *pushInstance) buffer(in <-chan PushEvent, out chan PushEvent) {
+	defer close(out)
+	for {
+		var evt PushEvent
+		var ok bool
+		select {
+		case evt, ok = <-in:
+		case <-s.ctx.Done():
+			return
+		}
+		if !ok {
+			return
+		}
+
+		// errors are never dropped, and skipped events are received
+		// and then dropped when consumed
+		if evt.Err != nil || s.bufferPolicy == BufferBlock {
+			if evt.Err == nil || errors.Is(evt.Err, sdk.ErrSkip) {
+				atomic.AddUint64(&s.stats.Received, 1)
+			}
+			select {
+			case out <- evt:
+			case <-s.ctx.Done():
+				return
+			}
+			// the event source ends with any fatal error
+			if sdk.IsFatal(evt.Err) {
+				return
+			}
+			continue
+		}
+
+		// the event is counted before sending it, so that it is never
+		// emitted before being received
+		atomic.AddUint64(&s.stats.Received, 1)
+		for sent := false; !sent; {
+			select {
+			case out <- evt:
+				sent = true
+			default:
+				if s.bufferPolicy == BufferDropNewest {
+					atomic.AddUint64(&s.stats.Dropped, 1)
+					sent = true
+				} else {
+					// make room by dropping the oldest event, unless the
+					// consumer did it for us in the meantime
+					select {
+					case old := <-out:
+						if old.Err == nil {
+							atomic.AddUint64(&s.stats.Dropped, 1)
+						} else if !s.dropOldestAfter(old, out) {
+							// the buffer only contains errors
+							atomic.AddUint64(&s.stats.Dropped, 1)
+							sent = true
+						}
+					default:
+					}
+				}
+			}
+		}
 	}
+}
 
-	return res, nil
+// dropOldestAfter drops the oldest event of out that has no error, given
+// head as the error that has been taken from the head of out. The events
+// left are sent back to out in their original order, starting with head.
+// This is only invoked by the goroutine filling out, which has at least one
+// slot available after taking head. Returns false if no event was dropped.
+func (s *pushInstance) dropOldestAfter(head PushEvent, out chan PushEvent) bool {
+	evts := []PushEvent{head}
+	for more := true; more; {
+		select {
+		case evt := <-out:
+			evts = append(evts, evt)
+		default:
+			more = false
+		}
+	}
+	dropped := false
+	for i := range evts {
+		if evts[i].Err == nil {
+			evts = append(evts[:i], evts[i+1:]...)
+			atomic.AddUint64(&s.stats.Dropped, 1)
+			dropped = true
+			break
+		}
+	}
+	for _, evt := range evts {
+		out <- evt
+	}
+	return dropped
 }
 
 func (s *pushInstance) NextBatch(pState sdk.PluginState, evts sdk.EventWriters) (int, error) {
+	// the previous batch has been consumed, so we commit its cursor
+	if s.checkpointer != nil {
+		s.checkpointer.commit(false)
+	}
+
 	// once EOF has been hit, we should return it at each new call of NextBatch
 	if s.eof {
 		return 0, sdk.ErrEOF
@@ -303,47 +827,89 @@ func (s *pushInstance) NextBatch(pState sdk.PluginState, evts sdk.EventWriters)
 	// timeout needs to be resetted for this batch
 	s.timeoutTicker.Reset(s.timeout)
 
-	// attempt filling the event batch
+	// attempt filling the event batch, starting from the event that
+	// did not fit in the previous one, if any
 	n := 0
 	for n < evts.Len() {
-		select {
-		// an event is received, so we add it in the batch
-		case evt, ok := <-s.evtC:
-			// event channel is closed, we reached EOF
-			if !ok {
-				evt.Err = sdk.ErrEOF
-			}
-			// if there are no errors so far, try writing the event
-			if evt.Err == nil {
-				if l, wErr := evts.Get(n).Writer().Write(evt.Data); wErr != nil {
-					evt.Err = wErr
-				} else if l < len(evt.Data) {
-					evt.Err = io.ErrShortWrite
+		// wait for the rate limit, if any
+		if _, err := s.ready(1); err != nil {
+			return n, err
+		}
+
+		var evt PushEvent
+		if s.pending != nil {
+			evt = *s.pending
+			s.pending = nil
+		} else {
+			select {
+			// an event is received, so we add it in the batch
+			case e, ok := <-s.evtC:
+				evt = e
+				// event channel is closed, we reached EOF
+				if !ok {
+					evt.Err = sdk.ErrEOF
+				} else if (evt.Err == nil || errors.Is(evt.Err, sdk.ErrSkip)) && s.bufferSize == 0 {
+					atomic.AddUint64(&s.stats.Received, 1)
 				}
+			// timeout hits, so we flush a partial batch
+			case <-s.timeoutTicker.C:
+				return n, sdk.ErrTimeout
+			// context has been canceled, so we exit
+			case <-s.ctx.Done():
+				s.eof = true
+				return n, sdk.ErrEOF
 			}
-			// an error occurred, so we need to exit
-			if evt.Err != nil {
-				// in case of non-timeout error, we consider the event source ended
-				if evt.Err != sdk.ErrTimeout {
-					s.eof = true
-				}
-				return n, evt.Err
+		}
+
+		// if there are no errors so far, try writing the event
+		m := 0
+		if evt.Err == nil {
+			m, evt.Err = s.writeEvent(evts, n, evt.Data)
+			// the event does not fit in this batch, so we flush it
+			if evt.Err == errBatchFull {
+				s.pending = &evt
+				s.pending.Err = nil
+				return n, nil
 			}
-			// event added to the batch successfully
-			if evt.Timestamp.IsZero() {
-				evts.Get(n).SetTimestamp(math.MaxUint64)
-			} else {
-				evts.Get(n).SetTimestamp(uint64(evt.Timestamp.UnixNano()))
+		}
+		// the event has been skipped by the producer
+		if errors.Is(evt.Err, sdk.ErrSkip) {
+			if s.checkpointer != nil {
+				s.checkpointer.add(evt.Cursor)
 			}
-			n++
-		// timeout hits, so we flush a partial batch
-		case <-s.timeoutTicker.C:
-			return n, sdk.ErrTimeout
-		// context has been canceled, so we exit
-		case <-s.ctx.Done():
-			s.eof = true
-			return n, sdk.ErrEOF
+			atomic.AddUint64(&s.stats.Dropped, 1)
+			continue
+		}
+		// an error occurred, so we need to exit
+		if evt.Err != nil {
+			// in case of fatal error, we consider the event source ended
+			if sdk.IsFatal(evt.Err) {
+				s.eof = true
+			}
+			return n, evt.Err
+		}
+		// the event has been dropped due to its size
+		if m == 0 {
+			atomic.AddUint64(&s.stats.Dropped, 1)
+			continue
+		}
+		// event added to the batch successfully
+		ts := sdk.Timestamp(evt.Timestamp)
+		if ts == sdk.UnsetTimestamp {
+			ts = s.timestamp()
+		}
+		for i := n; i < n+m; i++ {
+			evts.Get(i).SetTimestamp(ts)
+			evts.Get(i).SetTID(sdk.UnsetTID)
+		}
+		if s.checkpointer != nil {
+			s.checkpointer.add(evt.Cursor)
+		}
+		if s.limiter != nil {
+			s.limiter.consume(m)
 		}
+		atomic.AddUint64(&s.stats.Emitted, uint64(m))
+		n += m
 	}
 	return n, nil
 }
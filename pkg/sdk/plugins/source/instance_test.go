@@ -20,12 +20,15 @@ package source
 import (
 	"bytes"
 	"context"
+	"errors"
+	"fmt"
 	"math/rand"
 	"testing"
 	"time"
+	"unsafe"
 
 	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk"
-	sdkint "github.com/falcosecurity/plugin-sdk-go/pkg/sdk/internal/sdk"
+	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk/sdktest"
 )
 
 const (
@@ -38,9 +41,9 @@ const (
 )
 
 func benchNextBatch(b *testing.B, inst Instance, batchSize uint32, evtCount int) {
-	batch := &sdkint.InMemoryEventWriters{}
+	batch := &sdktest.InMemoryEventWriters{}
 	for i := uint32(0); i < batchSize; i++ {
-		batch.Writers = append(batch.Writers, &sdkint.InMemoryEventWriter{})
+		batch.Writers = append(batch.Writers, &sdktest.InMemoryEventWriter{})
 	}
 	b.ResetTimer()
 	tot := 0
@@ -79,6 +82,22 @@ func benchPullInstance(b *testing.B, onEvt func() []byte) {
 	benchNextBatch(b, inst, benchEvtBatchSize, benchEvtCount)
 }
 
+func benchBatchPullInstance(b *testing.B, onEvt func() []byte) {
+	pull := func(c context.Context, e sdk.EventWriters) (int, error) {
+		for i := 0; i < e.Len(); i++ {
+			if _, err := e.Get(i).Writer().Write(onEvt()); err != nil {
+				return i, err
+			}
+		}
+		return e.Len(), nil
+	}
+	inst, err := NewBatchPullInstance(pull, WithInstanceTimeout(benchEvtTimeout))
+	if err != nil {
+		b.Fatal(err.Error())
+	}
+	benchNextBatch(b, inst, benchEvtBatchSize, benchEvtCount)
+}
+
 func benchPushInstance(b *testing.B, onEvt func() []byte) {
 	evtChan := make(chan PushEvent)
 	stopChan := make(chan bool)
@@ -116,6 +135,11 @@ func BenchmarkPullEmpty(b *testing.B) {
 	benchPullInstance(b, func() []byte { return data })
 }
 
+func BenchmarkBatchPullEmpty(b *testing.B) {
+	data := []byte{}
+	benchBatchPullInstance(b, func() []byte { return data })
+}
+
 func BenchmarkPushEmpty(b *testing.B) {
 	data := []byte{}
 	benchPushInstance(b, func() []byte { return data })
@@ -125,6 +149,10 @@ func BenchmarkPullFixed(b *testing.B) {
 	benchPullInstance(b, func() []byte { return createEventData(benchFixedEvtDataSize) })
 }
 
+func BenchmarkBatchPullFixed(b *testing.B) {
+	benchBatchPullInstance(b, func() []byte { return createEventData(benchFixedEvtDataSize) })
+}
+
 func BenchmarkPushFixed(b *testing.B) {
 	benchPushInstance(b, func() []byte { return createEventData(benchFixedEvtDataSize) })
 }
@@ -135,6 +163,12 @@ func BenchmarkPullRandom(b *testing.B) {
 	})
 }
 
+func BenchmarkBatchPullRandom(b *testing.B) {
+	benchBatchPullInstance(b, func() []byte {
+		return createEventData((rand.Uint32() % (benchMaxEvtDataSize - benchMinEvtDataSize)) + benchMinEvtDataSize)
+	})
+}
+
 func BenchmarkPushRandom(b *testing.B) {
 	benchPushInstance(b, func() []byte {
 		return createEventData((rand.Uint32() % (benchMaxEvtDataSize - benchMinEvtDataSize)) + benchMinEvtDataSize)
@@ -145,9 +179,9 @@ func TestPullInstance(t *testing.T) {
 	timeout := time.Millisecond * 10
 
 	// create batch
-	batch := &sdkint.InMemoryEventWriters{}
+	batch := &sdktest.InMemoryEventWriters{}
 	for i := uint32(0); i < sdk.DefaultBatchSize; i++ {
-		batch.Writers = append(batch.Writers, &sdkint.InMemoryEventWriter{})
+		batch.Writers = append(batch.Writers, &sdktest.InMemoryEventWriter{})
 	}
 
 	// setup evt generation callback
@@ -215,9 +249,9 @@ func TestPullInstance(t *testing.T) {
 
 func TestPullInstanceCtxCanceling(t *testing.T) {
 	// create batch
-	batch := &sdkint.InMemoryEventWriters{}
+	batch := &sdktest.InMemoryEventWriters{}
 	for i := uint32(0); i < sdk.DefaultBatchSize; i++ {
-		batch.Writers = append(batch.Writers, &sdkint.InMemoryEventWriter{})
+		batch.Writers = append(batch.Writers, &sdktest.InMemoryEventWriter{})
 	}
 
 	ctx, cancel := context.WithCancel(context.Background())
@@ -253,9 +287,9 @@ func TestPushInstance(t *testing.T) {
 	timeout := time.Millisecond * 100
 
 	// create batch
-	batch := &sdkint.InMemoryEventWriters{}
+	batch := &sdktest.InMemoryEventWriters{}
 	for i := uint32(0); i < sdk.DefaultBatchSize; i++ {
-		batch.Writers = append(batch.Writers, &sdkint.InMemoryEventWriter{})
+		batch.Writers = append(batch.Writers, &sdktest.InMemoryEventWriter{})
 	}
 
 	// setup evt generation worker
@@ -324,9 +358,9 @@ func TestPushInstance(t *testing.T) {
 
 func TestPushInstanceChanClosing(t *testing.T) {
 	// create batch
-	batch := &sdkint.InMemoryEventWriters{}
+	batch := &sdktest.InMemoryEventWriters{}
 	for i := uint32(0); i < sdk.DefaultBatchSize; i++ {
-		batch.Writers = append(batch.Writers, &sdkint.InMemoryEventWriter{})
+		batch.Writers = append(batch.Writers, &sdktest.InMemoryEventWriter{})
 	}
 
 	evtChan := make(chan PushEvent)
@@ -357,9 +391,9 @@ func TestPushInstanceChanClosing(t *testing.T) {
 
 func TestPushInstanceCtxCanceling(t *testing.T) {
 	// create batch
-	batch := &sdkint.InMemoryEventWriters{}
+	batch := &sdktest.InMemoryEventWriters{}
 	for i := uint32(0); i < sdk.DefaultBatchSize; i++ {
-		batch.Writers = append(batch.Writers, &sdkint.InMemoryEventWriter{})
+		batch.Writers = append(batch.Writers, &sdktest.InMemoryEventWriter{})
 	}
 
 	ctx, cancel := context.WithCancel(context.Background())
@@ -389,3 +423,524 @@ func TestPushInstanceCtxCanceling(t *testing.T) {
 		t.Fatalf("expected %d, but found %d", 0, n)
 	}
 }
+
+func TestBatchPullInstance(t *testing.T) {
+	timeout := time.Millisecond * 10
+
+	// create batch
+	batch := &sdktest.InMemoryEventWriters{}
+	for i := uint32(0); i < sdk.DefaultBatchSize; i++ {
+		batch.Writers = append(batch.Writers, &sdktest.InMemoryEventWriter{})
+	}
+
+	// setup evt generation callback
+	nCall := 0
+	pull := func(c context.Context, e sdk.EventWriters) (int, error) {
+		nCall++
+		switch nCall {
+		case 1:
+			time.Sleep(timeout * 10)
+			e.Get(0).Writer().Write([]byte{1})
+			e.Get(1).Writer().Write([]byte{2})
+			return 2, nil
+		case 2:
+			e.Get(0).Writer().Write([]byte{3})
+			return 1, nil
+		default:
+			e.Get(0).Writer().Write([]byte{4})
+			return 1, sdk.ErrEOF
+		}
+	}
+
+	// setup closing callback
+	closed := false
+	close := func() { closed = true }
+
+	// open instance
+	inst, err := NewBatchPullInstance(
+		pull,
+		WithInstanceTimeout(timeout),
+		WithInstanceClose(close),
+	)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// fist call to nextbatch should trigger the timeout and return 2 evts
+	n, err := inst.NextBatch(nil, batch)
+	if err != sdk.ErrTimeout {
+		t.Fatalf("expected sdk.ErrTimeout, but found error: %s ", err)
+	} else if n != 2 {
+		t.Fatalf("expected %d, but found %d", 2, n)
+	}
+
+	// second call to nextbatch should fill the batch until EOF and return 2 evts
+	n, err = inst.NextBatch(nil, batch)
+	if err != sdk.ErrEOF {
+		t.Fatalf("expected sdk.ErrEOF, but found error: %s ", err)
+	} else if n != 2 {
+		t.Fatalf("expected %d, but found %d", 2, n)
+	}
+	for i, b := range []byte{3, 4} {
+		data := batch.Writers[i].(*sdktest.InMemoryEventWriter).Buffer.Bytes()
+		if len(data) != 1 || data[0] != b {
+			t.Errorf("expected event #%d to be %v, but found %v", i, []byte{b}, data)
+		}
+	}
+
+	// close instance
+	closer, ok := inst.(sdk.Closer)
+	if !ok {
+		t.Fatalf("instance does not implement sdk.Closer")
+	}
+	closer.Close()
+	if !closed {
+		t.Fatalf("expected close callback to be invoked")
+	}
+
+	// every other call should return EOF
+	n, err = inst.NextBatch(nil, batch)
+	if err != sdk.ErrEOF {
+		t.Fatalf("expected sdk.ErrEOF, but found error: %s ", err)
+	} else if n != 0 {
+		t.Fatalf("expected %d, but found %d", 0, n)
+	}
+}
+
+func TestBatchPullInstanceCtxCanceling(t *testing.T) {
+	// create batch
+	batch := &sdktest.InMemoryEventWriters{}
+	for i := uint32(0); i < sdk.DefaultBatchSize; i++ {
+		batch.Writers = append(batch.Writers, &sdktest.InMemoryEventWriter{})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	pull := func(c context.Context, e sdk.EventWriters) (int, error) {
+		return 0, sdk.ErrTimeout
+	}
+	inst, err := NewBatchPullInstance(pull, WithInstanceContext(ctx))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// fist call to nextbatch should trigger the timeout and return no evts
+	n, err := inst.NextBatch(nil, batch)
+	if err != sdk.ErrTimeout {
+		t.Fatalf("expected sdk.ErrTimeout, but found error: %s ", err)
+	} else if n != 0 {
+		t.Fatalf("expected %d, but found %d", 0, n)
+	}
+
+	// cancel context
+	cancel()
+
+	// next call to nextbatch should trigger EOF
+	n, err = inst.NextBatch(nil, batch)
+	if err != sdk.ErrEOF {
+		t.Fatalf("expected sdk.ErrEOF, but found error: %s ", err)
+	} else if n != 0 {
+		t.Fatalf("expected %d, but found %d", 0, n)
+	}
+}
+
+func TestPushInstanceBuffer(t *testing.T) {
+	// create batch
+	batch := &sdktest.InMemoryEventWriters{}
+	for i := uint32(0); i < sdk.DefaultBatchSize; i++ {
+		batch.Writers = append(batch.Writers, &sdktest.InMemoryEventWriter{})
+	}
+
+	for _, tc := range []struct {
+		policy   BufferPolicy
+		expected []byte
+	}{
+		{policy: BufferDropNewest, expected: []byte{1, 2}},
+		{policy: BufferDropOldest, expected: []byte{3, 4}},
+	} {
+		evtChan := make(chan PushEvent, 4)
+		for i := byte(1); i <= 4; i++ {
+			evtChan <- PushEvent{Data: []byte{i}}
+		}
+		inst, err := NewPushInstance(evtChan, WithInstanceBuffer(2, tc.policy))
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		statser, ok := inst.(PushStatser)
+		if !ok {
+			t.Fatalf("instance does not implement PushStatser")
+		}
+
+		// wait for all the events to be received
+		for statser.PushStats().Received < 4 {
+			time.Sleep(time.Millisecond)
+		}
+		n, err := inst.NextBatch(nil, batch)
+		if err != sdk.ErrTimeout {
+			t.Fatalf("expected sdk.ErrTimeout, but found error: %s ", err)
+		} else if n != len(tc.expected) {
+			t.Fatalf("expected %d, but found %d", len(tc.expected), n)
+		}
+		for i, b := range tc.expected {
+			data := batch.Writers[i].(*sdktest.InMemoryEventWriter).Buffer.Bytes()
+			if len(data) != 1 || data[0] != b {
+				t.Errorf("expected event #%d to be %v, but found %v", i, []byte{b}, data)
+			}
+		}
+		expected := PushStats{Received: 4, Dropped: 2, Emitted: 2}
+		if statser.PushStats() != expected {
+			t.Errorf("expected %s, but found %s", expected, statser.PushStats())
+		}
+		if _, str := inst.(sdk.Progresser).Progress(nil); str != expected.String() {
+			t.Errorf("expected progress %s, but found %s", expected.String(), str)
+		}
+
+		// closing the channel still causes EOF
+		close(evtChan)
+		n, err = inst.NextBatch(nil, batch)
+		if err != sdk.ErrEOF {
+			t.Fatalf("expected sdk.ErrEOF, but found error: %s ", err)
+		} else if n != 0 {
+			t.Fatalf("expected %d, but found %d", 0, n)
+		}
+		inst.(sdk.Closer).Close()
+	}
+}
+
+func TestPushInstanceBufferBlock(t *testing.T) {
+	// create batch
+	batch := &sdktest.InMemoryEventWriters{}
+	for i := uint32(0); i < sdk.DefaultBatchSize; i++ {
+		batch.Writers = append(batch.Writers, &sdktest.InMemoryEventWriter{})
+	}
+
+	evtChan := make(chan PushEvent)
+	inst, err := NewPushInstance(evtChan, WithInstanceBuffer(2, BufferBlock))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer inst.(sdk.Closer).Close()
+
+	// the producer blocks once the buffer is full, and no event is dropped
+	go func() {
+		for i := byte(1); i <= 4; i++ {
+			evtChan <- PushEvent{Data: []byte{i}}
+		}
+		evtChan <- PushEvent{Err: sdk.ErrEOF}
+	}()
+	tot := 0
+	for {
+		n, err := inst.NextBatch(nil, batch)
+		tot += n
+		if err == sdk.ErrEOF {
+			break
+		}
+		if err != nil && err != sdk.ErrTimeout {
+			t.Fatal(err.Error())
+		}
+	}
+	expected := PushStats{Received: 4, Dropped: 0, Emitted: 4}
+	if tot != 4 || inst.(PushStatser).PushStats() != expected {
+		t.Errorf("expected %d events and %s, but found %d and %s", 4, expected, tot, inst.(PushStatser).PushStats())
+	}
+}
+
+func TestPushInstanceBufferErrors(t *testing.T) {
+	// create batch
+	batch := &sdktest.InMemoryEventWriters{}
+	for i := uint32(0); i < sdk.DefaultBatchSize; i++ {
+		batch.Writers = append(batch.Writers, &sdktest.InMemoryEventWriter{})
+	}
+
+	// the error at the head of the buffer is never dropped, even if the
+	// buffer gets full behind it
+	errNetwork := errors.New("network error")
+	evtChan := make(chan PushEvent, 4)
+	evtChan <- PushEvent{Err: sdk.Temporary(errNetwork)}
+	for i := byte(1); i <= 3; i++ {
+		evtChan <- PushEvent{Data: []byte{i}}
+	}
+	inst, err := NewPushInstance(evtChan, WithInstanceBuffer(2, BufferDropOldest))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer inst.(sdk.Closer).Close()
+	statser := inst.(PushStatser)
+	for statser.PushStats().Received < 3 || statser.PushStats().Dropped < 2 {
+		time.Sleep(time.Millisecond)
+	}
+	n, err := inst.NextBatch(nil, batch)
+	if !errors.Is(err, sdk.ErrTemporary) || n != 0 {
+		t.Fatalf("expected %d and sdk.ErrTemporary, but found %d and %v", 0, n, err)
+	}
+	n, err = inst.NextBatch(nil, batch)
+	if err != sdk.ErrTimeout || n != 1 {
+		t.Fatalf("expected %d and sdk.ErrTimeout, but found %d and %v", 1, n, err)
+	}
+	if data := batch.Writers[0].(*sdktest.InMemoryEventWriter).Buffer.Bytes(); !bytes.Equal(data, []byte{3}) {
+		t.Errorf("expected %v, but found %v", []byte{3}, data)
+	}
+
+	// once the buffer only contains errors, the received event is dropped
+	evtChan <- PushEvent{Err: sdk.Temporary(errNetwork)}
+	evtChan <- PushEvent{Err: sdk.Temporary(errNetwork)}
+	evtChan <- PushEvent{Data: []byte{4}}
+	for statser.PushStats().Dropped < 3 {
+		time.Sleep(time.Millisecond)
+	}
+	for i := 0; i < 2; i++ {
+		n, err = inst.NextBatch(nil, batch)
+		if !errors.Is(err, sdk.ErrTemporary) || n != 0 {
+			t.Fatalf("expected %d and sdk.ErrTemporary, but found %d and %v", 0, n, err)
+		}
+	}
+	expected := PushStats{Received: 4, Dropped: 3, Emitted: 1}
+	if statser.PushStats() != expected {
+		t.Errorf("expected %s, but found %s", expected, statser.PushStats())
+	}
+}
+
+func TestPushInstanceBufferInvalid(t *testing.T) {
+	for _, policy := range []BufferPolicy{-1, BufferDropNewest + 1} {
+		if _, err := NewPushInstance(make(chan PushEvent), WithInstanceBuffer(2, policy)); err == nil {
+			t.Errorf("expected error for buffer policy %d", policy)
+		}
+	}
+}
+
+func TestInstanceTimestamp(t *testing.T) {
+	newBatch := func() *sdktest.InMemoryEventWriters {
+		batch := &sdktest.InMemoryEventWriters{}
+		for i := 0; i < 4; i++ {
+			batch.Writers = append(batch.Writers, &sdktest.InMemoryEventWriter{})
+		}
+		return batch
+	}
+	timestamps := func(batch *sdktest.InMemoryEventWriters, n int) []uint64 {
+		var res []uint64
+		for i := 0; i < n; i++ {
+			res = append(res, batch.Writers[i].(*sdktest.InMemoryEventWriter).ValTimestamp)
+		}
+		return res
+	}
+
+	// events with a timestamp set by the plugin are left untouched, and
+	// the others are strictly increasing with the monotonic policy
+	nCall := 0
+	pull := func(c context.Context, e sdk.EventWriter) error {
+		nCall++
+		if nCall > 4 {
+			return sdk.ErrEOF
+		}
+		if nCall == 2 {
+			e.SetTimestamp(5)
+		}
+		return nil
+	}
+	start := uint64(time.Now().UnixNano())
+	inst, err := NewPullInstance(pull, WithInstanceTimestamp(TimestampMonotonic))
+	if err != nil {
+		t.Fatal(err)
+	}
+	batch := newBatch()
+	n, err := inst.NextBatch(nil, batch)
+	if err != nil || n != 4 {
+		t.Fatalf("expected %d and no error, but found %d and %v", 4, n, err)
+	}
+	ts := timestamps(batch, n)
+	if ts[1] != 5 {
+		t.Errorf("expected %d, but found %d", 5, ts[1])
+	}
+	if ts[0] < start || ts[2] <= ts[0] || ts[3] <= ts[2] {
+		t.Errorf("expected increasing timestamps since %d, but found %v", start, ts)
+	}
+	inst.(sdk.Closer).Close()
+
+	// same for batch pull with the wallclock policy
+	batchPull := func(c context.Context, e sdk.EventWriters) (int, error) {
+		e.Get(0).SetTimestamp(5)
+		return 2, sdk.ErrEOF
+	}
+	inst, err = NewBatchPullInstance(batchPull, WithInstanceTimestamp(TimestampWallclock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	batch = newBatch()
+	n, err = inst.NextBatch(nil, batch)
+	if err != sdk.ErrEOF || n != 2 {
+		t.Fatalf("expected %d and sdk.ErrEOF, but found %d and %v", 2, n, err)
+	}
+	ts = timestamps(batch, n)
+	if ts[0] != 5 || ts[1] < start {
+		t.Errorf("expected %d and a timestamp since %d, but found %v", 5, start, ts)
+	}
+	inst.(sdk.Closer).Close()
+
+	// push events without timestamp are left unset by default
+	evtChan := make(chan PushEvent, 2)
+	evtChan <- PushEvent{Data: []byte{1}}
+	evtChan <- PushEvent{Data: []byte{2}, Timestamp: time.Unix(0, 5)}
+	close(evtChan)
+	inst, err = NewPushInstance(evtChan)
+	if err != nil {
+		t.Fatal(err)
+	}
+	batch = newBatch()
+	n, _ = inst.NextBatch(nil, batch)
+	ts = timestamps(batch, n)
+	if n != 2 || ts[0] != sdk.UnsetTimestamp || ts[1] != 5 {
+		t.Errorf("expected %v, but found %v", []uint64{sdk.UnsetTimestamp, 5}, ts)
+	}
+	inst.(sdk.Closer).Close()
+}
+
+func TestInstanceWriterReuse(t *testing.T) {
+	batch := &sdktest.InMemoryEventWriters{Writers: []sdk.EventWriter{&sdktest.InMemoryEventWriter{}}}
+	check := func(inst Instance, name string) {
+		for i, expected := range []uint64{5, sdk.UnsetTID} {
+			if n, err := inst.NextBatch(nil, batch); err != nil || n != 1 {
+				t.Fatalf("(%s) expected %d and no error, but found %d and %v", name, 1, n, err)
+			}
+			w := batch.Writers[0].(*sdktest.InMemoryEventWriter)
+			if w.ValTID != expected || w.ValTimestamp != expected {
+				t.Errorf("(%s #%d) expected TID and timestamp %d, but found %d and %d", name, i, expected, w.ValTID, w.ValTimestamp)
+			}
+		}
+		inst.(sdk.Closer).Close()
+	}
+
+	// values set for an event don't leak in the next one written with
+	// the same writer
+	nCall := 0
+	pull := func(c context.Context, e sdk.EventWriter) error {
+		nCall++
+		if nCall == 1 {
+			e.SetTID(5)
+			e.SetTimestamp(5)
+		}
+		return nil
+	}
+	inst, err := NewPullInstance(pull)
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(inst, "pull")
+
+	nCall = 0
+	batchPull := func(c context.Context, e sdk.EventWriters) (int, error) {
+		return 1, pull(c, e.Get(0))
+	}
+	inst, err = NewBatchPullInstance(batchPull)
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(inst, "batch pull")
+}
+
+func TestBatchPullInstanceGrowableWriters(t *testing.T) {
+	batch, err := sdk.NewGrowableEventWriters(4, 8, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer batch.Free()
+	allocated := func(i int) bool {
+		return *(*unsafe.Pointer)(unsafe.Add(batch.ArrayPtr(), i*int(unsafe.Sizeof(uintptr(0))))) != nil
+	}
+
+	// only the events actually produced get allocated, even if the
+	// batches are partial and the writers are reused
+	pull := func(c context.Context, e sdk.EventWriters) (int, error) {
+		e.Get(0).SetTID(5)
+		_, err := e.Get(0).Writer().Write([]byte("hello"))
+		if err != nil {
+			return 0, err
+		}
+		return 1, sdk.ErrTimeout
+	}
+	inst, err := NewBatchPullInstance(pull)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer inst.(sdk.Closer).Close()
+	for i := 0; i < 2; i++ {
+		if n, err := inst.NextBatch(nil, batch); err != sdk.ErrTimeout || n != 1 {
+			t.Fatalf("expected %d and %v, but found %d and %v", 1, sdk.ErrTimeout, n, err)
+		}
+		if !allocated(0) {
+			t.Errorf("expected the first event to be allocated")
+		}
+		for j := 1; j < batch.Len(); j++ {
+			if allocated(j) {
+				t.Errorf("expected event #%d not to be allocated", j)
+			}
+		}
+	}
+}
+
+func TestInstanceErrorTaxonomy(t *testing.T) {
+	batch := &sdktest.InMemoryEventWriters{}
+	for i := 0; i < 4; i++ {
+		batch.Writers = append(batch.Writers, &sdktest.InMemoryEventWriter{})
+	}
+	errRecord := errors.New("malformed record")
+	errNetwork := errors.New("network error")
+
+	// malformed records are skipped, and transient errors do not end the
+	// event source
+	nCall := 0
+	pull := func(c context.Context, e sdk.EventWriter) error {
+		nCall++
+		switch nCall {
+		case 2:
+			return sdk.Skip(errRecord)
+		case 4:
+			return fmt.Errorf("wrapped: %w", sdk.Temporary(errNetwork))
+		case 6:
+			return sdk.ErrEOF
+		}
+		_, err := e.Writer().Write([]byte{byte(nCall)})
+		return err
+	}
+	inst, err := NewPullInstance(pull, WithInstanceTimeout(time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer inst.(sdk.Closer).Close()
+	n, err := inst.NextBatch(nil, batch)
+	if !errors.Is(err, sdk.ErrTemporary) || !errors.Is(err, errNetwork) || n != 2 {
+		t.Fatalf("expected %d and sdk.ErrTemporary, but found %d and %v", 2, n, err)
+	}
+	for i, b := range []byte{1, 3} {
+		if data := batch.Writers[i].(*sdktest.InMemoryEventWriter).Buffer.Bytes(); len(data) != 1 || data[0] != b {
+			t.Errorf("expected event #%d to be %v, but found %v", i, []byte{b}, data)
+		}
+	}
+	n, err = inst.NextBatch(nil, batch)
+	if err != sdk.ErrEOF || n != 1 {
+		t.Fatalf("expected %d and sdk.ErrEOF, but found %d and %v", 1, n, err)
+	}
+
+	// same for push instances
+	evtChan := make(chan PushEvent, 4)
+	evtChan <- PushEvent{Data: []byte{1}}
+	evtChan <- PushEvent{Err: sdk.Skip(errRecord)}
+	evtChan <- PushEvent{Err: sdk.Temporary(errNetwork)}
+	evtChan <- PushEvent{Data: []byte{2}}
+	close(evtChan)
+	inst, err = NewPushInstance(evtChan, WithInstanceTimeout(time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer inst.(sdk.Closer).Close()
+	n, err = inst.NextBatch(nil, batch)
+	if !errors.Is(err, sdk.ErrTemporary) || n != 1 {
+		t.Fatalf("expected %d and sdk.ErrTemporary, but found %d and %v", 1, n, err)
+	}
+	n, err = inst.NextBatch(nil, batch)
+	if err != sdk.ErrEOF || n != 1 {
+		t.Fatalf("expected %d and sdk.ErrEOF, but found %d and %v", 1, n, err)
+	}
+	expected := PushStats{Received: 3, Dropped: 1, Emitted: 2}
+	if stats := inst.(PushStatser).PushStats(); stats != expected {
+		t.Errorf("expected %s, but found %s", expected, stats)
+	}
+}
@@ -0,0 +1,502 @@
+// SPDX-License-Identifier: Apache-2.0
+/*
+Copyright (C) 2026 The Falco Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"sync"
+	"time"
+	"unsafe"
+
+	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk"
+)
+
+// MergePolicy represents how an event source opened with NewMergedInstance
+// reacts when one of the merged instances ends or fails.
+type MergePolicy int
+
+const (
+	// MergeContinue removes the ended or failed instance, and keeps producing
+	// events from the other ones. The merged event source ends once all the
+	// instances are removed.
+	MergeContinue MergePolicy = iota
+	//
+	// MergeStop ends the merged event source, and returns the error of the
+	// ended or failed instance once all the events received so far have
+	// been returned.
+	MergeStop
+)
+
+// WithInstanceMergeOrder sets the merged event source to return events ordered
+// by timestamp. Each event is held until all the merged instances produced
+// an event to compare it with, but no longer than the given window. Events
+// without a timestamp are considered as timestamped at their reception.
+// This only affects the event sources opened with NewMergedInstance.
+func WithInstanceMergeOrder(window time.Duration) func(*builtinInstance) {
+	return func(s *builtinInstance) {
+		s.mergeOrdered = true
+		s.mergeWindow = window
+	}
+}
+
+// WithInstanceMergeEOF sets the policy used when one of the merged instances
+// returns sdk.ErrEOF. By default, MergeContinue is used.
+// This only affects the event sources opened with NewMergedInstance.
+func WithInstanceMergeEOF(policy MergePolicy) func(*builtinInstance) {
+	return func(s *builtinInstance) {
+		s.mergeOnEOF = policy
+	}
+}
+
+// WithInstanceMergeError sets the policy used when one of the merged instances
+// returns a fatal error other than sdk.ErrEOF (see sdk.IsFatal). Non-fatal
+// errors never end the merged instances. By default, MergeStop is used.
+// This only affects the event sources opened with NewMergedInstance.
+func WithInstanceMergeError(policy MergePolicy) func(*builtinInstance) {
+	return func(s *builtinInstance) {
+		s.mergeOnError = policy
+	}
+}
+
+// capturedEvent is an event produced by one of the merged instances
+type capturedEvent struct {
+	data      []byte
+	timestamp uint64
+	tid       uint64
+	arrival   time.Time
+	cursor    []byte
+}
+
+// key returns the timestamp used to order the event
+func (c *capturedEvent) key() uint64 {
+	if c.timestamp == sdk.UnsetTimestamp {
+		return uint64(c.arrival.UnixNano())
+	}
+	return c.timestamp
+}
+
+// captureEventWriter is an sdk.EventWriter storing events in Go memory
+type captureEventWriter struct {
+	buf       bytes.Buffer
+	timestamp uint64
+	tid       uint64
+}
+
+func (c *captureEventWriter) Writer() io.Writer {
+	c.buf.Reset()
+	return &c.buf
+}
+
+func (c *captureEventWriter) SetTimestamp(value uint64) {
+	c.timestamp = value
+}
+
+func (c *captureEventWriter) SetTID(value uint64) {
+	c.tid = value
+}
+
+// captureEventWriters is an sdk.EventWriters storing events in Go memory
+type captureEventWriters []*captureEventWriter
+
+func (c captureEventWriters) Get(eventIndex int) sdk.EventWriter {
+	return c[eventIndex]
+}
+
+func (c captureEventWriters) Len() int {
+	return len(c)
+}
+
+func (c captureEventWriters) ArrayPtr() unsafe.Pointer {
+	return nil
+}
+
+func (c captureEventWriters) Free() {
+	// the memory is garbage collected
+}
+
+// mergedBatch is a batch of events produced by one of the merged instances
+type mergedBatch struct {
+	index int
+	evts  []capturedEvent
+	err   error
+}
+
+// mergeable is implemented by the pre-built event source instances, which
+// can be cancelled and checkpointed by the merged event source
+type mergeable interface {
+	stop()
+	checkpoint() *Checkpointer
+}
+
+func (s *builtinInstance) stop() {
+	s.cancel()
+}
+
+func (s *builtinInstance) checkpoint() *Checkpointer {
+	return s.checkpointer
+}
+
+type mergedInstance struct {
+	builtinInstance
+	instances     []Instance
+	checkpointers []*Checkpointer
+	cursors       [][]byte
+	batchC        chan mergedBatch
+	queues        [][]capturedEvent
+	active        []bool
+	nActive       int
+	next          int
+	err           error
+	started       bool
+	wg            sync.WaitGroup
+}
+
+// NewMergedInstance opens a new event source that merges the events produced
+// by the given instances, such as the ones returned by NewPullInstance and
+// NewPushInstance. This is suitable for plugins reading from many upstreams
+// at once.
+//
+// Each of the merged instances is driven in its own goroutine, starting from
+// the first invocation of NextBatch() on the merged event source, and
+// its events are copied in the batches of the merged event source. By
+// default, events are interleaved in the order in which they are received.
+// Ordering events by timestamp can be requested with the WithInstanceMergeOrder
+// option, and the handling of instances ending or failing can be configured
+// with the WithInstanceMergeEOF and WithInstanceMergeError options.
+//
+// Closing the merged event source closes all the merged instances, after
+// waiting for their ongoing NextBatch() calls to return. The instances
+// opened with the constructors of this package are cancelled for this to
+// happen promptly, but user-defined instances are not: Close blocks until
+// their NextBatch() returns, so they should not wait indefinitely for new
+// events. Cursors recorded by a Checkpointer of a merged instance are
+// committed once the framework consumed the batch of the merged event
+// source containing their events.
+func NewMergedInstance(instances []Instance, options ...func(*builtinInstance)) (Instance, error) {
+	res := &mergedInstance{
+		instances:     instances,
+		checkpointers: make([]*Checkpointer, len(instances)),
+		cursors:       make([][]byte, len(instances)),
+		batchC:        make(chan mergedBatch, len(instances)),
+		queues:        make([][]capturedEvent, len(instances)),
+		active:        make([]bool, len(instances)),
+		nActive:       len(instances),
+	}
+	for i, inst := range instances {
+		res.active[i] = true
+		if m, ok := inst.(mergeable); ok {
+			res.checkpointers[i] = m.checkpoint()
+		}
+	}
+	if err := res.init(options); err != nil {
+		return nil, err
+	}
+	if res.nActive == 0 {
+		res.err = sdk.ErrEOF
+	}
+	return res, nil
+}
+
+func (s *mergedInstance) Close() {
+	s.builtinInstance.Close()
+
+	// cancel the merged instances, so that the ones waiting for events
+	// return, and wait for them to be idle before closing them
+	for _, inst := range s.instances {
+		if m, ok := inst.(mergeable); ok {
+			m.stop()
+		}
+	}
+	s.wg.Wait()
+
+	// the last batch has been consumed, so we persist the cursors of its events
+	s.commit(true)
+	for _, inst := range s.instances {
+		if closer, ok := inst.(sdk.Closer); ok {
+			closer.Close()
+		}
+	}
+}
+
+// commit marks the cursors of the events returned so far as consumed
+func (s *mergedInstance) commit(force bool) {
+	for i, c := range s.checkpointers {
+		if c != nil {
+			c.commitCursor(s.cursors[i], force)
+			s.cursors[i] = nil
+		}
+	}
+}
+
+// mergeIdleBackoff is the policy of the wait time before invoking again a
+// merged instance that returned a non-fatal error with no events
+var mergeIdleBackoff = RetryPolicy{
+	InitialBackoff: time.Millisecond,
+	MaxBackoff:     100 * time.Millisecond,
+	Multiplier:     2,
+}
+
+// idleWait waits before invoking again a merged instance that returned no
+// events for the given number of consecutive times. Returns false if the
+// merged event source gets closed in the meantime.
+func (s *mergedInstance) idleWait(idle int) bool {
+	timer := time.NewTimer(mergeIdleBackoff.backoff(idle))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-s.ctx.Done():
+		return false
+	}
+}
+
+// run drives the merged instance at the given index, and sends its
+// events to the merged event source until it ends
+func (s *mergedInstance) run(pState sdk.PluginState, index int) {
+	defer s.wg.Done()
+	batch := make(captureEventWriters, s.batchSize)
+	for i := range batch {
+		batch[i] = &captureEventWriter{}
+	}
+	idle := 0
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		default:
+		}
+
+		for _, w := range batch {
+			w.timestamp = sdk.UnsetTimestamp
+			w.tid = sdk.UnsetTID
+		}
+		n, err := s.instances[index].NextBatch(pState, batch)
+		// the cursor is committed by the merged event source, once the
+		// framework consumed the last event of this batch
+		var cursor []byte
+		if c := s.checkpointers[index]; c != nil {
+			cursor = c.take()
+		}
+		// non-fatal errors do not end the merged instances, but the ones
+		// returned with no events are retried with a backoff, so that
+		// an instance failing right away is not invoked in a busy loop.
+		// Timeouts are not, as they are returned after waiting for events.
+		if !sdk.IsFatal(err) {
+			if n == 0 && err != nil && !errors.Is(err, sdk.ErrTimeout) {
+				if !s.idleWait(idle) {
+					return
+				}
+				idle++
+			} else {
+				idle = 0
+			}
+			err = nil
+		}
+		if n == 0 && err == nil {
+			continue
+		}
+
+		b := mergedBatch{index: index, evts: make([]capturedEvent, n), err: err}
+		now := time.Now()
+		for i := 0; i < n; i++ {
+			b.evts[i] = capturedEvent{
+				data:      append([]byte{}, batch[i].buf.Bytes()...),
+				timestamp: batch[i].timestamp,
+				tid:       batch[i].tid,
+				arrival:   now,
+			}
+		}
+		if n > 0 {
+			b.evts[n-1].cursor = cursor
+		}
+		select {
+		case s.batchC <- b:
+		case <-s.ctx.Done():
+			return
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// receive queues the events of the given batch, and handles the
+// ending or failure of the instance that produced it
+func (s *mergedInstance) receive(b mergedBatch) {
+	s.queues[b.index] = append(s.queues[b.index], b.evts...)
+	if b.err == nil {
+		return
+	}
+	s.active[b.index] = false
+	s.nActive--
+	policy := s.mergeOnError
+	if errors.Is(b.err, sdk.ErrEOF) {
+		policy = s.mergeOnEOF
+	}
+	if s.err == nil && (policy == MergeStop || s.nActive == 0) {
+		s.err = b.err
+		if policy == MergeContinue {
+			s.err = sdk.ErrEOF
+		}
+	}
+}
+
+// pick returns the index of the queue from which the next event should
+// be returned, or false if no event can be returned at the moment. In the
+// latter case, a non-zero duration indicates how long to wait before
+// an event can be returned in any case.
+func (s *mergedInstance) pick() (int, bool, time.Duration) {
+	if !s.mergeOrdered {
+		for i := range s.queues {
+			index := (s.next + i) % len(s.queues)
+			if len(s.queues[index]) > 0 {
+				s.next = index + 1
+				return index, true, 0
+			}
+		}
+		return 0, false, 0
+	}
+
+	// find the event with the lowest timestamp and the one received first,
+	// and check if all the active instances have an event to be compared
+	min, oldest := -1, -1
+	complete := true
+	for i, q := range s.queues {
+		if len(q) == 0 {
+			complete = complete && !s.active[i]
+			continue
+		}
+		if min < 0 || q[0].key() < s.queues[min][0].key() {
+			min = i
+		}
+		if oldest < 0 || q[0].arrival.Before(s.queues[oldest][0].arrival) {
+			oldest = i
+		}
+	}
+	if min < 0 {
+		return 0, false, 0
+	}
+	if complete || s.err != nil {
+		return min, true, 0
+	}
+	wait := time.Until(s.queues[oldest][0].arrival.Add(s.mergeWindow))
+	if wait <= 0 {
+		return min, true, 0
+	}
+	return 0, false, wait
+}
+
+func (s *mergedInstance) NextBatch(pState sdk.PluginState, evts sdk.EventWriters) (n int, err error) {
+	// once EOF has been hit, we should return it at each new call of NextBatch
+	if s.eof {
+		return 0, sdk.ErrEOF
+	}
+
+	// the previous batch has been consumed, so we commit its cursors
+	s.commit(false)
+
+	// start driving the merged instances
+	if !s.started {
+		s.started = true
+		for i := range s.instances {
+			s.wg.Add(1)
+			go s.run(pState, i)
+		}
+	}
+
+	// timeout needs to be resetted for this batch
+	s.timeoutTicker.Reset(s.timeout)
+
+	// attempt filling the event batch
+	n = 0
+	for n < evts.Len() {
+		// receive all the batches available without blocking
+		for received := true; received; {
+			select {
+			case b := <-s.batchC:
+				s.receive(b)
+			default:
+				received = false
+			}
+		}
+
+		// return the next event, if any
+		index, ok, wait := s.pick()
+		if ok {
+			evt := s.queues[index][0]
+			m, err := s.writeEvent(evts, n, evt.data)
+			// the event does not fit in this batch, so we flush it
+			if err == errBatchFull {
+				return n, nil
+			}
+			if err != nil {
+				s.eof = true
+				return n, err
+			}
+			s.queues[index] = s.queues[index][1:]
+			if evt.cursor != nil {
+				s.cursors[index] = evt.cursor
+			}
+			ts := evt.timestamp
+			if ts == sdk.UnsetTimestamp {
+				ts = s.timestamp()
+			}
+			for i := n; i < n+m; i++ {
+				evts.Get(i).SetTimestamp(ts)
+				evts.Get(i).SetTID(evt.tid)
+			}
+			n += m
+			continue
+		}
+
+		// all the received events have been returned
+		if s.err != nil && wait == 0 {
+			s.eof = true
+			return n, s.err
+		}
+
+		// wait for new events, or for the reorder window to expire
+		var waitC <-chan time.Time
+		var timer *time.Timer
+		if wait > 0 {
+			timer = time.NewTimer(wait)
+			waitC = timer.C
+		}
+		select {
+		case b := <-s.batchC:
+			s.receive(b)
+		case <-waitC:
+		// timeout hits, so we flush a partial batch
+		case <-s.timeoutTicker.C:
+			err = sdk.ErrTimeout
+		// context has been canceled, so we exit
+		case <-s.ctx.Done():
+			s.eof = true
+			err = sdk.ErrEOF
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
@@ -0,0 +1,305 @@
+// SPDX-License-Identifier: Apache-2.0
+/*
+Copyright (C) 2026 The Falco Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk"
+	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk/sdktest"
+)
+
+// newSamplePushInstance opens a push instance producing the given events,
+// each with a single-byte payload equal to its timestamp
+func newSamplePushInstance(t *testing.T, timestamps []byte, last error) Instance {
+	evtChan := make(chan PushEvent, len(timestamps)+1)
+	for _, ts := range timestamps {
+		evtChan <- PushEvent{Data: []byte{ts}, Timestamp: time.Unix(0, int64(ts))}
+	}
+	if last != nil {
+		evtChan <- PushEvent{Err: last}
+	}
+	close(evtChan)
+	inst, err := NewPushInstance(evtChan, WithInstanceTimeout(time.Millisecond))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	return inst
+}
+
+// collectMerged invokes NextBatch until a non-timeout error is returned,
+// and returns the payloads of all the events received
+func collectMerged(t *testing.T, inst Instance) ([]byte, error) {
+	batch := &sdktest.InMemoryEventWriters{}
+	for i := 0; i < 2; i++ {
+		batch.Writers = append(batch.Writers, &sdktest.InMemoryEventWriter{})
+	}
+	var res []byte
+	for start := time.Now(); time.Since(start) < 5*time.Second; {
+		n, err := inst.NextBatch(nil, batch)
+		for i := 0; i < n; i++ {
+			w := batch.Writers[i].(*sdktest.InMemoryEventWriter)
+			if w.Buffer.Len() != 1 || uint64(w.Buffer.Bytes()[0]) != w.ValTimestamp {
+				t.Errorf("unexpected event with data %v and timestamp %d", w.Buffer.Bytes(), w.ValTimestamp)
+			}
+			res = append(res, w.Buffer.Bytes()...)
+		}
+		if err != nil && err != sdk.ErrTimeout {
+			return res, err
+		}
+	}
+	t.Fatalf("merged instance did not end")
+	return nil, nil
+}
+
+func TestMergedInstance(t *testing.T) {
+	closed := false
+	inst, err := NewMergedInstance(
+		[]Instance{
+			newSamplePushInstance(t, []byte{1, 2, 3}, nil),
+			newSamplePushInstance(t, []byte{4, 5}, nil),
+		},
+		WithInstanceClose(func() { closed = true }),
+	)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	res, err := collectMerged(t, inst)
+	if err != sdk.ErrEOF {
+		t.Fatalf("expected sdk.ErrEOF, but found error: %s ", err)
+	}
+	if len(res) != 5 {
+		t.Fatalf("expected %d events, but found %v", 5, res)
+	}
+
+	// events of the same instance keep their order
+	pos := make(map[byte]int)
+	for i, b := range res {
+		pos[b] = i
+	}
+	if pos[1] > pos[2] || pos[2] > pos[3] || pos[4] > pos[5] {
+		t.Errorf("unexpected event order: %v", res)
+	}
+
+	inst.(sdk.Closer).Close()
+	if !closed {
+		t.Fatalf("expected close callback to be invoked")
+	}
+	if n, err := inst.NextBatch(nil, &sdktest.InMemoryEventWriters{}); err != sdk.ErrEOF || n != 0 {
+		t.Fatalf("expected %d and sdk.ErrEOF, but found %d and %v", 0, n, err)
+	}
+}
+
+func TestMergedInstanceOrder(t *testing.T) {
+	inst, err := NewMergedInstance(
+		[]Instance{
+			newSamplePushInstance(t, []byte{1, 4, 5, 8}, nil),
+			newSamplePushInstance(t, []byte{2, 3, 6}, nil),
+			newSamplePushInstance(t, []byte{7}, nil),
+		},
+		WithInstanceMergeOrder(time.Minute),
+	)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer inst.(sdk.Closer).Close()
+	res, err := collectMerged(t, inst)
+	if err != sdk.ErrEOF {
+		t.Fatalf("expected sdk.ErrEOF, but found error: %s ", err)
+	}
+	for i, b := range res {
+		if b != byte(i+1) {
+			t.Fatalf("unexpected event order: %v", res)
+		}
+	}
+	if len(res) != 8 {
+		t.Fatalf("expected %d events, but found %v", 8, res)
+	}
+}
+
+func TestMergedInstancePolicies(t *testing.T) {
+	errTest := errors.New("test error")
+
+	// failed instances are removed
+	inst, err := NewMergedInstance(
+		[]Instance{
+			newSamplePushInstance(t, []byte{1}, errTest),
+			newSamplePushInstance(t, []byte{2, 3}, nil),
+		},
+		WithInstanceMergeError(MergeContinue),
+	)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	res, err := collectMerged(t, inst)
+	if err != sdk.ErrEOF || len(res) != 3 {
+		t.Errorf("expected %d events and sdk.ErrEOF, but found %v and %v", 3, res, err)
+	}
+	inst.(sdk.Closer).Close()
+
+	// failed instances end the merged event source
+	inst, err = NewMergedInstance(
+		[]Instance{
+			newSamplePushInstance(t, []byte{1}, errTest),
+			newSamplePushInstance(t, []byte{2, 3}, nil),
+		},
+	)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	_, err = collectMerged(t, inst)
+	if err != errTest {
+		t.Errorf("expected %v, but found %v", errTest, err)
+	}
+	inst.(sdk.Closer).Close()
+
+	// ended instances end the merged event source
+	inst, err = NewMergedInstance(
+		[]Instance{
+			newSamplePushInstance(t, nil, nil),
+			newSamplePushInstance(t, nil, sdk.ErrTimeout),
+		},
+		WithInstanceMergeEOF(MergeStop),
+	)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	_, err = collectMerged(t, inst)
+	if err != sdk.ErrEOF {
+		t.Errorf("expected sdk.ErrEOF, but found %v", err)
+	}
+	inst.(sdk.Closer).Close()
+}
+
+func TestMergedInstanceClose(t *testing.T) {
+	// instances waiting for their context to be cancelled don't hang Close
+	pull := func(ctx context.Context, e sdk.EventWriter) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	sub, err := NewPullInstance(pull)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	inst, err := NewMergedInstance([]Instance{sub}, WithInstanceTimeout(time.Millisecond))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	batch := &sdktest.InMemoryEventWriters{Writers: []sdk.EventWriter{&sdktest.InMemoryEventWriter{}}}
+	if _, err := inst.NextBatch(nil, batch); err != sdk.ErrTimeout {
+		t.Fatalf("expected sdk.ErrTimeout, but found %v", err)
+	}
+	done := make(chan struct{})
+	go func() {
+		inst.(sdk.Closer).Close()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("merged instance did not close")
+	}
+}
+
+// failingInstance is a user-defined instance failing with a non-fatal
+// error right away, counting the calls to NextBatch
+type failingInstance struct {
+	BaseInstance
+	calls int32
+}
+
+func (f *failingInstance) NextBatch(pState sdk.PluginState, evts sdk.EventWriters) (int, error) {
+	atomic.AddInt32(&f.calls, 1)
+	return 0, sdk.Temporary(errors.New("not ready"))
+}
+
+func TestMergedInstanceIdleBackoff(t *testing.T) {
+	// instances failing right away are not invoked in a busy loop
+	sub := &failingInstance{}
+	inst, err := NewMergedInstance([]Instance{sub}, WithInstanceTimeout(100*time.Millisecond))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	batch := &sdktest.InMemoryEventWriters{Writers: []sdk.EventWriter{&sdktest.InMemoryEventWriter{}}}
+	if n, err := inst.NextBatch(nil, batch); err != sdk.ErrTimeout || n != 0 {
+		t.Fatalf("expected %d and sdk.ErrTimeout, but found %d and %v", 0, n, err)
+	}
+	inst.(sdk.Closer).Close()
+	if calls := atomic.LoadInt32(&sub.calls); calls < 2 || calls > 20 {
+		t.Errorf("expected the instance to be invoked with a backoff, but found %d calls", calls)
+	}
+}
+
+func TestMergedInstanceCheckpointer(t *testing.T) {
+	batch := &sdktest.InMemoryEventWriters{}
+	for i := 0; i < 2; i++ {
+		batch.Writers = append(batch.Writers, &sdktest.InMemoryEventWriter{})
+	}
+
+	store := NewKVCheckpointStore(sampleCheckpointKV{}, "test")
+	cp, err := NewCheckpointer(store, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	evtChan := make(chan PushEvent, 2)
+	evtChan <- PushEvent{Data: []byte{1}, Cursor: []byte("1")}
+	evtChan <- PushEvent{Data: []byte{2}, Cursor: []byte("2")}
+	sub, err := NewPushInstance(evtChan, WithInstanceCheckpointer(cp), WithInstanceTimeout(time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	inst, err := NewMergedInstance([]Instance{sub}, WithInstanceTimeout(time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// cursors are committed only once the merged batches are consumed
+	nEvt := 0
+	for start := time.Now(); nEvt < 2 && time.Since(start) < 5*time.Second; {
+		n, err := inst.NextBatch(nil, batch)
+		if err != nil && err != sdk.ErrTimeout {
+			t.Fatal(err)
+		}
+		nEvt += n
+	}
+	if nEvt != 2 {
+		t.Fatalf("expected %d events, but found %d", 2, nEvt)
+	}
+	if c := cp.Cursor(); c != nil {
+		t.Errorf("expected nil cursor, but found %s", string(c))
+	}
+	if n, err := inst.NextBatch(nil, batch); err != sdk.ErrTimeout || n != 0 {
+		t.Fatalf("expected %d and sdk.ErrTimeout, but found %d and %v", 0, n, err)
+	}
+	if c := cp.Cursor(); string(c) != "2" {
+		t.Errorf("expected %s, but found %s", "2", string(c))
+	}
+
+	// closing the merged event source persists the committed cursors
+	if c, _ := store.Load(); c != nil {
+		t.Errorf("expected nil cursor, but found %s", string(c))
+	}
+	inst.(sdk.Closer).Close()
+	if c, _ := store.Load(); string(c) != "2" {
+		t.Errorf("expected %s, but found %s", "2", string(c))
+	}
+}
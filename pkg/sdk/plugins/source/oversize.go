@@ -0,0 +1,169 @@
+// SPDX-License-Identifier: Apache-2.0
+/*
+Copyright (C) 2026 The Falco Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"errors"
+	"io"
+	"sync/atomic"
+
+	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk"
+)
+
+// OversizePolicy represents how an event source handles events with a
+// data payload exceeding the maximum event size.
+type OversizePolicy int
+
+const (
+	// OversizeFail makes NextBatch() return io.ErrShortWrite, which
+	// ends the event source
+	OversizeFail OversizePolicy = iota
+	//
+	// OversizeTruncate truncates the event data to the maximum event size,
+	// and replaces its trailing bytes with the truncation marker
+	OversizeTruncate
+	//
+	// OversizeSplit splits the event data across many consecutive events
+	// of the batch, all with the same timestamp. Event data needing more
+	// events than the batch size is truncated as for OversizeTruncate,
+	// and events are dropped if the maximum event size is zero.
+	OversizeSplit
+	//
+	// OversizeDrop drops the event
+	OversizeDrop
+	//
+	// OversizeGrow reallocates the memory of the event to fit the event data.
+	// This requires the sdk.EventWriter to implement sdk.ResizableEventWriter,
+	// otherwise OversizeFail is used.
+	OversizeGrow
+)
+
+// DefaultTruncateMarker is the default truncation marker used by the
+// OversizeTruncate policy.
+var DefaultTruncateMarker = []byte("...")
+
+// errBatchFull is returned by writeEvent when the batch has not enough
+// room left for an event
+var errBatchFull = errors.New("event batch is full")
+
+// WithInstanceOversize sets the policy used in the opened event source
+// for events with a data payload exceeding the maximum event size.
+// By default, OversizeFail is used. The events affected by the policy
+// are counted in the Oversized counter of PushStats.
+// This only affects the event sources opened with NewPushInstance and
+// NewMergedInstance.
+func WithInstanceOversize(policy OversizePolicy) func(*builtinInstance) {
+	return func(s *builtinInstance) {
+		s.oversizePolicy = policy
+	}
+}
+
+// WithInstanceTruncateMarker sets the truncation marker used in the opened
+// event source with the OversizeTruncate policy, which is DefaultTruncateMarker
+// by default. An empty marker truncates event data with no mark.
+func WithInstanceTruncateMarker(marker []byte) func(*builtinInstance) {
+	return func(s *builtinInstance) {
+		s.truncateMarker = marker
+	}
+}
+
+// writeEvent writes data in the batch starting at the n-th event, applying
+// the oversize policy if needed, and returns the number of events written.
+// A zero count with a nil error means that the event has been dropped, and
+// errBatchFull is returned if the batch has not enough events left to split
+// the data across.
+func (s *builtinInstance) writeEvent(evts sdk.EventWriters, n int, data []byte) (int, error) {
+	w := evts.Get(n)
+	l, err := w.Writer().Write(data)
+	if err == nil && l < len(data) {
+		err = io.ErrShortWrite
+	}
+	if err != io.ErrShortWrite {
+		if err != nil {
+			return 0, err
+		}
+		return 1, nil
+	}
+
+	// the event is oversized, and l is the maximum event size. In case
+	// it needs to be split but does not fit in the batch, we'll retry
+	// later without counting it twice
+	if s.oversizePolicy == OversizeSplit && l > 0 {
+		m := (len(data) + l - 1) / l
+		if m <= evts.Len() && n+m > evts.Len() {
+			return 0, errBatchFull
+		}
+	}
+	atomic.AddUint64(&s.oversized, 1)
+	switch s.oversizePolicy {
+	case OversizeTruncate:
+		return s.truncate(w, data, l)
+	case OversizeSplit:
+		if l == 0 {
+			return 0, nil
+		}
+		m := (len(data) + l - 1) / l
+		if m > evts.Len() {
+			return s.truncate(w, data, l)
+		}
+		for i := 0; i < m; i++ {
+			chunk := data[i*l:]
+			if len(chunk) > l {
+				chunk = chunk[:l]
+			}
+			if l, err := evts.Get(n + i).Writer().Write(chunk); err != nil || l < len(chunk) {
+				return 0, io.ErrShortWrite
+			}
+		}
+		return m, nil
+	case OversizeDrop:
+		return 0, nil
+	case OversizeGrow:
+		if r, ok := w.(sdk.ResizableEventWriter); ok {
+			if err = r.Resize(int64(len(data))); err != nil {
+				return 0, err
+			}
+			if l, err = r.Writer().Write(data); err == nil && l < len(data) {
+				err = io.ErrShortWrite
+			}
+			if err != nil {
+				return 0, err
+			}
+			return 1, nil
+		}
+	}
+	return 0, io.ErrShortWrite
+}
+
+// truncate writes data truncated to the maximum event size l in w, with
+// its trailing bytes replaced by the truncation marker
+func (s *builtinInstance) truncate(w sdk.EventWriter, data []byte, l int) (int, error) {
+	marker := s.truncateMarker
+	if len(marker) > l {
+		marker = marker[:l]
+	}
+	wr := w.Writer()
+	_, err := wr.Write(data[:l-len(marker)])
+	if err == nil {
+		_, err = wr.Write(marker)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return 1, nil
+}
@@ -0,0 +1,222 @@
+// SPDX-License-Identifier: Apache-2.0
+/*
+Copyright (C) 2026 The Falco Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk"
+	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk/sdktest"
+)
+
+// sampleResizableEventWriter is an in-memory sdk.ResizableEventWriter
+// that can't hold more data than its size
+type sampleResizableEventWriter struct {
+	sdktest.InMemoryEventWriter
+	size int
+}
+
+func (s *sampleResizableEventWriter) Writer() io.Writer {
+	s.Buffer.Reset()
+	return s
+}
+
+func (s *sampleResizableEventWriter) Write(p []byte) (int, error) {
+	if room := s.size - s.Buffer.Len(); len(p) > room {
+		s.Buffer.Write(p[:room])
+		return room, io.ErrShortWrite
+	}
+	return s.Buffer.Write(p)
+}
+
+func (s *sampleResizableEventWriter) DataSize() int64 {
+	return int64(s.size)
+}
+
+func (s *sampleResizableEventWriter) Resize(dataSize int64) error {
+	s.size = int(dataSize)
+	s.Buffer.Reset()
+	return nil
+}
+
+func TestPushInstanceOversize(t *testing.T) {
+	data := []byte("0123456789")
+	for _, tc := range []struct {
+		name     string
+		options  []func(*builtinInstance)
+		err      error
+		expected [][]byte
+		stats    PushStats
+	}{
+		{
+			name:  "fail",
+			err:   io.ErrShortWrite,
+			stats: PushStats{Received: 1, Oversized: 1},
+		},
+		{
+			name:     "truncate",
+			options:  []func(*builtinInstance){WithInstanceOversize(OversizeTruncate)},
+			err:      sdk.ErrEOF,
+			expected: [][]byte{[]byte("0..."), []byte("ab")},
+			stats:    PushStats{Received: 2, Emitted: 2, Oversized: 1},
+		},
+		{
+			name:     "truncate with marker",
+			options:  []func(*builtinInstance){WithInstanceOversize(OversizeTruncate), WithInstanceTruncateMarker([]byte("!"))},
+			err:      sdk.ErrEOF,
+			expected: [][]byte{[]byte("012!"), []byte("ab")},
+			stats:    PushStats{Received: 2, Emitted: 2, Oversized: 1},
+		},
+		{
+			name:     "split",
+			options:  []func(*builtinInstance){WithInstanceOversize(OversizeSplit)},
+			err:      sdk.ErrEOF,
+			expected: [][]byte{[]byte("0123"), []byte("4567"), []byte("89"), []byte("ab")},
+			stats:    PushStats{Received: 2, Emitted: 4, Oversized: 1},
+		},
+		{
+			name:     "drop",
+			options:  []func(*builtinInstance){WithInstanceOversize(OversizeDrop)},
+			err:      sdk.ErrEOF,
+			expected: [][]byte{[]byte("ab")},
+			stats:    PushStats{Received: 2, Dropped: 1, Emitted: 1, Oversized: 1},
+		},
+		{
+			name:     "grow",
+			options:  []func(*builtinInstance){WithInstanceOversize(OversizeGrow)},
+			err:      sdk.ErrEOF,
+			expected: [][]byte{data, []byte("ab")},
+			stats:    PushStats{Received: 2, Emitted: 2, Oversized: 1},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// the split event does not fit in the batch after the first event
+			batch := &sdktest.InMemoryEventWriters{}
+			for i := 0; i < 3; i++ {
+				batch.Writers = append(batch.Writers, &sampleResizableEventWriter{size: 4})
+			}
+			evtChan := make(chan PushEvent, 3)
+			evtChan <- PushEvent{Data: []byte("x")}
+			evtChan <- PushEvent{Data: data}
+			evtChan <- PushEvent{Data: []byte("ab")}
+			close(evtChan)
+			inst, err := NewPushInstance(evtChan, tc.options...)
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+			defer inst.(sdk.Closer).Close()
+
+			res := [][]byte{}
+			for {
+				n, err := inst.NextBatch(nil, batch)
+				for i := 0; i < n; i++ {
+					res = append(res, append([]byte{}, batch.Writers[i].(*sampleResizableEventWriter).Buffer.Bytes()...))
+				}
+				if err != nil {
+					if err != tc.err {
+						t.Errorf("expected %v, but found %v", tc.err, err)
+					}
+					break
+				}
+			}
+			tc.expected = append([][]byte{[]byte("x")}, tc.expected...)
+			if len(res) != len(tc.expected) {
+				t.Fatalf("expected %q, but found %q", tc.expected, res)
+			}
+			for i := range res {
+				if !bytes.Equal(res[i], tc.expected[i]) {
+					t.Errorf("expected %q, but found %q", tc.expected, res)
+				}
+			}
+
+			// the first event is not counted in the expected stats
+			tc.stats.Received++
+			tc.stats.Emitted++
+			if stats := inst.(PushStatser).PushStats(); stats != tc.stats {
+				t.Errorf("expected %s, but found %s", tc.stats, stats)
+			}
+		})
+	}
+}
+
+func TestPushInstanceOversizeSplitFallback(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		size     int
+		expected [][]byte
+		stats    PushStats
+	}{
+		// the event needs more events than the batch size, so it's truncated
+		{
+			name:     "truncate",
+			size:     4,
+			expected: [][]byte{[]byte("0..."), []byte("ab")},
+			stats:    PushStats{Received: 2, Emitted: 2, Oversized: 1},
+		},
+		// the maximum event size is zero, so the event is dropped
+		{
+			name:     "drop",
+			size:     0,
+			expected: [][]byte{},
+			stats:    PushStats{Received: 2, Dropped: 2, Oversized: 2},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			batch := &sdktest.InMemoryEventWriters{}
+			for i := 0; i < 3; i++ {
+				batch.Writers = append(batch.Writers, &sampleResizableEventWriter{size: tc.size})
+			}
+			evtChan := make(chan PushEvent, 2)
+			evtChan <- PushEvent{Data: []byte("0123456789abcdefghij")}
+			evtChan <- PushEvent{Data: []byte("ab")}
+			close(evtChan)
+			inst, err := NewPushInstance(evtChan, WithInstanceOversize(OversizeSplit))
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+			defer inst.(sdk.Closer).Close()
+
+			res := [][]byte{}
+			for {
+				n, err := inst.NextBatch(nil, batch)
+				for i := 0; i < n; i++ {
+					res = append(res, append([]byte{}, batch.Writers[i].(*sampleResizableEventWriter).Buffer.Bytes()...))
+				}
+				if err != nil {
+					if err != sdk.ErrEOF {
+						t.Errorf("expected %v, but found %v", sdk.ErrEOF, err)
+					}
+					break
+				}
+			}
+			if len(res) != len(tc.expected) {
+				t.Fatalf("expected %q, but found %q", tc.expected, res)
+			}
+			for i := range res {
+				if !bytes.Equal(res[i], tc.expected[i]) {
+					t.Errorf("expected %q, but found %q", tc.expected, res)
+				}
+			}
+			if stats := inst.(PushStatser).PushStats(); stats != tc.stats {
+				t.Errorf("expected %s, but found %s", tc.stats, stats)
+			}
+		})
+	}
+}
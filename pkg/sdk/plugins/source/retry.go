@@ -0,0 +1,237 @@
+// SPDX-License-Identifier: Apache-2.0
+/*
+Copyright (C) 2026 The Falco Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk"
+)
+
+// RetryPolicy represents how an event source retries the transient errors
+// returned by a pull function. Failed pulls are retried with an exponential
+// backoff, without ending the capture session.
+type RetryPolicy struct {
+	// MaxRetries is the maximum number of consecutive retries, after
+	// which the error ends the event source. Zero means that there is
+	// no limit.
+	MaxRetries int
+	//
+	// InitialBackoff is the wait time before the first retry.
+	InitialBackoff time.Duration
+	//
+	// MaxBackoff is the upper bound of the wait time between retries.
+	MaxBackoff time.Duration
+	//
+	// Multiplier is the factor by which the wait time grows at each
+	// consecutive retry.
+	Multiplier float64
+	//
+	// Jitter is the fraction of the wait time, in the [0, 1] range, that
+	// is randomized to avoid retrying in lockstep with other clients.
+	Jitter float64
+	//
+	// Retryable reports whether an error is transient and should be
+	// retried. If nil, only the errors matching sdk.ErrTemporary with
+	// errors.Is are considered transient.
+	// Errors matching sdk.ErrEOF, sdk.ErrTimeout, or sdk.ErrSkip are
+	// never retried.
+	Retryable func(error) bool
+}
+
+// DefaultRetryPolicy is a suggested retry policy, which can be passed to
+// WithInstanceRetry as-is or used as a base for a custom one.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries:     5,
+	InitialBackoff: 100 * time.Millisecond,
+	MaxBackoff:     10 * time.Second,
+	Multiplier:     2,
+	Jitter:         0.2,
+}
+
+// WithInstanceRetry sets the policy used in the opened event source to
+// retry the transient errors returned by the pull function. By default,
+// errors are not retried, and any error matching sdk.ErrTemporary is
+// returned to the framework without ending the event source.
+// This only affects the event sources opened with NewPullInstance and
+// NewBatchPullInstance.
+//
+// A non-positive InitialBackoff or MaxBackoff, and a Multiplier lower than 1,
+// are replaced with the ones of DefaultRetryPolicy. The other fields are used
+// as-is: a zero MaxRetries retries errors with no limit, and a zero Jitter
+// disables the randomization of the wait time. A nil Retryable retries only
+// the errors matching sdk.ErrTemporary.
+func WithInstanceRetry(policy RetryPolicy) func(*builtinInstance) {
+	return func(s *builtinInstance) {
+		if policy.InitialBackoff <= 0 {
+			policy.InitialBackoff = DefaultRetryPolicy.InitialBackoff
+		}
+		if policy.MaxBackoff <= 0 {
+			policy.MaxBackoff = DefaultRetryPolicy.MaxBackoff
+		}
+		if policy.Multiplier < 1 {
+			policy.Multiplier = DefaultRetryPolicy.Multiplier
+		}
+		s.retryPolicy = &policy
+	}
+}
+
+// WithInstanceRateLimit limits the rate at which the opened event source
+// produces events, with a token bucket refilled with eventsPerSec tokens
+// per second and holding at most burst tokens. A non-positive rate disables
+// the limit, which is the default.
+// This only affects the event sources opened with NewPullInstance,
+// NewBatchPullInstance, and NewPushInstance.
+func WithInstanceRateLimit(eventsPerSec float64, burst int) func(*builtinInstance) {
+	return func(s *builtinInstance) {
+		s.limiter = nil
+		if eventsPerSec > 0 {
+			s.limiter = newRateLimiter(eventsPerSec, burst)
+		}
+	}
+}
+
+// backoff returns the wait time before the given retry attempt, starting
+// from zero
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	d := float64(p.InitialBackoff) * math.Pow(p.Multiplier, float64(attempt))
+	if d > float64(p.MaxBackoff) {
+		d = float64(p.MaxBackoff)
+	}
+	if p.Jitter > 0 {
+		d *= 1 + math.Min(p.Jitter, 1)*(2*rand.Float64()-1)
+	}
+	return time.Duration(d)
+}
+
+// rateLimiter is a token bucket, in which each token allows
+// producing one event
+type rateLimiter struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newRateLimiter(rate float64, burst int) *rateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &rateLimiter{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// available returns the number of events that can be produced at the
+// given time. If none can be, it also returns the wait time before
+// the next one can.
+func (r *rateLimiter) available(now time.Time) (int, time.Duration) {
+	if now.After(r.last) {
+		r.tokens = math.Min(r.burst, r.tokens+now.Sub(r.last).Seconds()*r.rate)
+		r.last = now
+	}
+	if r.tokens < 1 {
+		return 0, time.Duration((1 - r.tokens) / r.rate * float64(time.Second))
+	}
+	return int(r.tokens), 0
+}
+
+// consume removes the tokens of n events produced
+func (r *rateLimiter) consume(n int) {
+	r.tokens -= float64(n)
+}
+
+// retry returns true if the error returned by a pull function should be
+// retried according to the retry policy, in which case the next attempt
+// is scheduled after the backoff wait time
+func (s *builtinInstance) retry(err error) bool {
+	p := s.retryPolicy
+	if p == nil || s.ctx.Err() != nil ||
+		errors.Is(err, sdk.ErrEOF) ||
+		errors.Is(err, sdk.ErrTimeout) ||
+		errors.Is(err, sdk.ErrSkip) {
+		return false
+	}
+	if p.Retryable == nil {
+		if !errors.Is(err, sdk.ErrTemporary) {
+			return false
+		}
+	} else if !p.Retryable(err) {
+		return false
+	}
+	if p.MaxRetries > 0 && s.retries >= p.MaxRetries {
+		return false
+	}
+	s.retryAt = time.Now().Add(p.backoff(s.retries))
+	s.retries++
+	return true
+}
+
+// wait blocks for the given duration. It returns sdk.ErrTimeout if the
+// batch timeout hits first, and sdk.ErrEOF if the context gets cancelled.
+func (s *builtinInstance) wait(d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-s.timeoutTicker.C:
+		return sdk.ErrTimeout
+	case <-s.ctx.Done():
+		s.eof = true
+		return sdk.ErrEOF
+	}
+}
+
+// ready waits until the next event can be produced, according to the
+// scheduled retry and the rate limit, and returns how many events can be
+// produced, up to n. The wait is interrupted if the batch timeout hits or
+// the context gets cancelled, in which case the corresponding error is
+// returned as in wait.
+func (s *builtinInstance) ready(n int) (int, error) {
+	if !s.retryAt.IsZero() {
+		if err := s.wait(time.Until(s.retryAt)); err != nil {
+			return 0, err
+		}
+		s.retryAt = time.Time{}
+	}
+	if s.limiter == nil {
+		return n, nil
+	}
+	for {
+		m, d := s.limiter.available(time.Now())
+		if m > 0 {
+			if m < n {
+				return m, nil
+			}
+			return n, nil
+		}
+		if err := s.wait(d); err != nil {
+			return 0, err
+		}
+	}
+}
@@ -0,0 +1,221 @@
+// SPDX-License-Identifier: Apache-2.0
+/*
+Copyright (C) 2026 The Falco Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk"
+	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk/sdktest"
+)
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	p := RetryPolicy{
+		InitialBackoff: 10 * time.Millisecond,
+		MaxBackoff:     50 * time.Millisecond,
+		Multiplier:     2,
+	}
+	for i, expected := range []time.Duration{10, 20, 40, 50, 50} {
+		expected *= time.Millisecond
+		if d := p.backoff(i); d != expected {
+			t.Errorf("(#%d): expected %s, but found %s", i, expected, d)
+		}
+	}
+	p.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		if d := p.backoff(0); d < 5*time.Millisecond || d > 15*time.Millisecond {
+			t.Fatalf("expected backoff within %s and %s, but found %s", 5*time.Millisecond, 15*time.Millisecond, d)
+		}
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	now := time.Now()
+	r := newRateLimiter(10, 3)
+	r.last = now
+	if m, _ := r.available(now); m != 3 {
+		t.Fatalf("expected %d, but found %d", 3, m)
+	}
+	r.consume(3)
+	m, d := r.available(now)
+	if m != 0 || d != 100*time.Millisecond {
+		t.Fatalf("expected %d and %s, but found %d and %s", 0, 100*time.Millisecond, m, d)
+	}
+	if m, _ := r.available(now.Add(200 * time.Millisecond)); m != 2 {
+		t.Fatalf("expected %d, but found %d", 2, m)
+	}
+	if m, _ := r.available(now.Add(time.Hour)); m != 3 {
+		t.Fatalf("expected %d, but found %d", 3, m)
+	}
+}
+
+func TestPullInstanceRetry(t *testing.T) {
+	batch := &sdktest.InMemoryEventWriters{}
+	for i := 0; i < 4; i++ {
+		batch.Writers = append(batch.Writers, &sdktest.InMemoryEventWriter{})
+	}
+
+	// every other pull fails with a transient error
+	errTransient := errors.New("transient")
+	errFatal := errors.New("fatal")
+	nCall := 0
+	pull := func(c context.Context, e sdk.EventWriter) error {
+		nCall++
+		if nCall > 8 {
+			return errFatal
+		}
+		if nCall%2 == 1 {
+			return errTransient
+		}
+		_, err := e.Writer().Write([]byte{byte(nCall)})
+		return err
+	}
+	inst, err := NewPullInstance(
+		pull,
+		WithInstanceTimeout(time.Second),
+		WithInstanceRetry(RetryPolicy{
+			InitialBackoff: time.Millisecond,
+			Retryable:      func(err error) bool { return err == errTransient },
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer inst.(sdk.Closer).Close()
+
+	n, err := inst.NextBatch(nil, batch)
+	if err != nil || n != 4 {
+		t.Fatalf("expected %d and no error, but found %d and %v", 4, n, err)
+	}
+	for i, w := range batch.Writers {
+		if data := w.(*sdktest.InMemoryEventWriter).Buffer.Bytes(); len(data) != 1 || data[0] != byte(2*(i+1)) {
+			t.Errorf("expected event #%d to be %v, but found %v", i, []byte{byte(2 * (i + 1))}, data)
+		}
+	}
+
+	// non-transient errors end the event source
+	n, err = inst.NextBatch(nil, batch)
+	if err != errFatal || n != 0 {
+		t.Fatalf("expected %d and %v, but found %d and %v", 0, errFatal, n, err)
+	}
+	if _, err = inst.NextBatch(nil, batch); err != sdk.ErrEOF {
+		t.Fatalf("expected sdk.ErrEOF, but found %v", err)
+	}
+}
+
+func TestBatchPullInstanceRetryLimit(t *testing.T) {
+	batch := &sdktest.InMemoryEventWriters{}
+	for i := 0; i < 4; i++ {
+		batch.Writers = append(batch.Writers, &sdktest.InMemoryEventWriter{})
+	}
+	errTransient := sdk.Temporary(errors.New("transient"))
+	nCall := 0
+	pull := func(c context.Context, e sdk.EventWriters) (int, error) {
+		nCall++
+		return 0, errTransient
+	}
+	inst, err := NewBatchPullInstance(
+		pull,
+		WithInstanceTimeout(time.Second),
+		WithInstanceRetry(RetryPolicy{MaxRetries: 3, InitialBackoff: time.Millisecond}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer inst.(sdk.Closer).Close()
+
+	n, err := inst.NextBatch(nil, batch)
+	if err != errTransient || n != 0 {
+		t.Fatalf("expected %d and %v, but found %d and %v", 0, errTransient, n, err)
+	}
+	if nCall != 4 {
+		t.Errorf("expected %d calls, but found %d", 4, nCall)
+	}
+}
+
+func TestBatchPullInstanceRateLimit(t *testing.T) {
+	batch := &sdktest.InMemoryEventWriters{}
+	for i := 0; i < 8; i++ {
+		batch.Writers = append(batch.Writers, &sdktest.InMemoryEventWriter{})
+	}
+	var sizes []int
+	pull := func(c context.Context, e sdk.EventWriters) (int, error) {
+		sizes = append(sizes, e.Len())
+		return e.Len(), nil
+	}
+	timeout := 50 * time.Millisecond
+	inst, err := NewBatchPullInstance(
+		pull,
+		WithInstanceTimeout(timeout),
+		WithInstanceRateLimit(1, 3),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer inst.(sdk.Closer).Close()
+
+	// the burst is produced at once, then we wait for the next token
+	// until the timeout hits
+	start := time.Now()
+	n, err := inst.NextBatch(nil, batch)
+	if err != sdk.ErrTimeout || n != 3 {
+		t.Fatalf("expected %d and sdk.ErrTimeout, but found %d and %v", 3, n, err)
+	}
+	if len(sizes) != 1 || sizes[0] != 3 {
+		t.Errorf("expected pull sizes %v, but found %v", []int{3}, sizes)
+	}
+	if time.Since(start) < timeout {
+		t.Errorf("expected to wait for at least %s", timeout)
+	}
+}
+
+func TestPullInstanceRetryDefault(t *testing.T) {
+	batch := &sdktest.InMemoryEventWriters{}
+	batch.Writers = append(batch.Writers, &sdktest.InMemoryEventWriter{})
+
+	// with no Retryable, only temporary errors are retried
+	errFatal := errors.New("fatal")
+	nCall := 0
+	pull := func(c context.Context, e sdk.EventWriter) error {
+		nCall++
+		if nCall == 1 {
+			return sdk.Temporary(errors.New("transient"))
+		}
+		return errFatal
+	}
+	inst, err := NewPullInstance(
+		pull,
+		WithInstanceTimeout(time.Second),
+		WithInstanceRetry(RetryPolicy{InitialBackoff: time.Millisecond}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer inst.(sdk.Closer).Close()
+
+	n, err := inst.NextBatch(nil, batch)
+	if err != errFatal || n != 0 {
+		t.Fatalf("expected %d and %v, but found %d and %v", 0, errFatal, n, err)
+	}
+	if nCall != 2 {
+		t.Errorf("expected %d calls, but found %d", 2, nCall)
+	}
+}
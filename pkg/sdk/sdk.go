@@ -20,6 +20,7 @@ package sdk
 
 import (
 	"errors"
+	"fmt"
 )
 
 // ErrEOF is the error returned by next_batch when no new events
@@ -31,6 +32,86 @@ var ErrEOF = errors.New("eof")
 // next one.
 var ErrTimeout = errors.New("timeout")
 
+// ErrTemporary indicates a transient failure, such as a network error,
+// after which new events may still be available. When returned by
+// NextBatch, either as-is or wrapped in another error, the events produced
+// so far are returned as in the case of ErrTimeout, and the capture session
+// continues. See Temporary.
+var ErrTemporary = errors.New("temporary failure")
+
+// ErrSkip indicates that a single event could not be produced, for example
+// because its source record is malformed, and that it should be skipped
+// without ending the capture session. When returned by NextBatch, either
+// as-is or wrapped in another error, it is handled as ErrTemporary. See Skip.
+var ErrSkip = errors.New("skipped event")
+
+// taggedError is an error that matches a given sentinel error with
+// errors.Is, in addition to the errors it wraps
+type taggedError struct {
+	err error
+	tag error
+}
+
+func (t *taggedError) Error() string {
+	return t.tag.Error() + ": " + t.err.Error()
+}
+
+func (t *taggedError) Unwrap() error {
+	return t.err
+}
+
+func (t *taggedError) Is(target error) bool {
+	return target == t.tag
+}
+
+// Temporary returns an error wrapping err that also matches ErrTemporary
+// with errors.Is. Returns nil if err is nil.
+func Temporary(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &taggedError{err: err, tag: ErrTemporary}
+}
+
+// Skip returns an error wrapping err that also matches ErrSkip with
+// errors.Is. Returns nil if err is nil.
+func Skip(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &taggedError{err: err, tag: ErrSkip}
+}
+
+// IsFatal returns true if err is non-nil and does not match any of ErrTimeout,
+// ErrTemporary, and ErrSkip with errors.Is, meaning that it ends the
+// capture session when returned by NextBatch.
+func IsFatal(err error) bool {
+	return err != nil &&
+		!errors.Is(err, ErrTimeout) &&
+		!errors.Is(err, ErrTemporary) &&
+		!errors.Is(err, ErrSkip)
+}
+
+// ErrPoisoned is the error returned by the prebuilt C symbols when they are
+// invoked on a plugin state that has been poisoned by a previous panic.
+// See the Poisoner interface.
+var ErrPoisoned = errors.New("plugin poisoned by a previous panic")
+
+// PanicError is the error recorded through the LastError interface when
+// the prebuilt C symbols recover from a panic raised by the plugin code.
+type PanicError struct {
+	// Value is the value passed to panic.
+	Value interface{}
+	//
+	// Stack is the stack trace of the goroutine that panicked, as
+	// formatted by runtime/debug.Stack.
+	Stack []byte
+}
+
+func (p *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v\n\n%s", p.Value, p.Stack)
+}
+
 // Functions that return or update a rc (e.g. plugin_init,
 // plugin_open) should return one of these values.
 const (
@@ -49,6 +130,11 @@ const DefaultEvtSize uint32 = 256 * 1024
 // interface used by the SDK.
 const DefaultBatchSize uint32 = 128
 
+// MaxExtractBatchEvents is the maximum number of events that can be passed
+// to the plugin_extract_fields_batch symbol exported by plugins built with
+// the SDK in a single call.
+const MaxExtractBatchEvents = 128
+
 // The full set of values that can be returned in the ftype
 // member of ss_plugin_extract_field structs (ppm_events_public.h).
 const (
@@ -68,26 +154,85 @@ const (
 	FieldTypeIPNet uint32 = 41
 )
 
+// FieldProperty is a property that can be set in the Properties member
+// of a FieldEntry.
+type FieldProperty = string
+
+// The values of the properties of a FieldEntry known by the framework.
+// Other values are ignored by the framework, and the SDK warns about them.
+const (
+	// The field is not shown in the list of fields printed by the framework.
+	FieldPropertyHidden FieldProperty = "hidden"
+	// The field carries general information about the event.
+	FieldPropertyInfo FieldProperty = "info"
+	// The field identifies a conversation (e.g. a network flow), which is
+	// used by tools like wireshark to group related events.
+	FieldPropertyConversation FieldProperty = "conversation"
+	// The framework is suggested to append the field to the output string
+	// of the events of compatible event sources.
+	FieldPropertyAddOutput FieldProperty = "addOutput"
+)
+
 // FieldEntry represents a single field entry that a plugin with field extraction
 // capability can expose.
 // Should be used when implementing plugin_get_fields().
 type FieldEntry struct {
-	Name       string        `json:"name"`
-	Type       string        `json:"type"`
-	IsList     bool          `json:"isList"`
-	Arg        FieldEntryArg `json:"arg"`
-	Display    string        `json:"display"`
-	Desc       string        `json:"desc"`
-	Properties []string      `json:"properties"`
+	Name       string          `json:"name"`
+	Type       string          `json:"type"`
+	IsList     bool            `json:"isList"`
+	Arg        FieldEntryArg   `json:"arg"`
+	Display    string          `json:"display"`
+	Desc       string          `json:"desc"`
+	Properties []FieldProperty `json:"properties"`
+	//
+	// Group is the name of the group under which the field is displayed
+	// by the tools listing the fields of the plugin.
+	Group string `json:"group,omitempty"`
+	//
+	// Deprecated is a notice describing why the field is deprecated and
+	// what to use instead. Deprecated fields can still be extracted,
+	// but the SDK warns about their usage once for each plugin state,
+	// through the logger of the plugin's owner.
+	Deprecated string `json:"deprecated,omitempty"`
+	//
+	// Aliases is a list of alternative names for the field. Each alias is
+	// exported as a distinct field entry, but extraction requests for an
+	// alias are passed to the plugin as requests for this field.
+	Aliases []string `json:"-"`
 }
 
 // FieldEntryArg describes the argument of a single field entry that
 // an plugin with field extraction capability can expose.
 // Should be used when implementing plugin_get_fields().
+//
+// The optional AllowedKeys, KeyPattern, and IndexRange members constrain
+// the values accepted for the argument. They are exported along with the
+// other members, and the SDK checks them with Validate before requesting
+// the extraction to the plugin.
 type FieldEntryArg struct {
 	IsRequired bool `json:"isRequired"`
 	IsIndex    bool `json:"isIndex"`
 	IsKey      bool `json:"isKey"`
+	//
+	// AllowedKeys is the set of keys accepted by a field with
+	// the IsKey flag enabled. An empty set accepts any key.
+	AllowedKeys []string `json:"allowedKeys,omitempty"`
+	//
+	// KeyPattern is a regular expression (RE2 syntax) that must match
+	// the whole key of a field with the IsKey flag enabled.
+	// An empty pattern accepts any key.
+	KeyPattern string `json:"keyPattern,omitempty"`
+	//
+	// IndexRange is the closed interval of indexes accepted by a field with
+	// the IsIndex flag enabled. A nil range accepts any index.
+	IndexRange *FieldEntryArgRange `json:"indexRange,omitempty"`
+}
+
+// FieldEntryArgRange represents a closed interval of indexes accepted
+// as the argument of a field entry.
+type FieldEntryArgRange struct {
+	Min uint64 `json:"min"`
+	Max uint64 `json:"max"`
 }
 
 // OpenParam represents a valid parameter for plugin_open().
@@ -0,0 +1,51 @@
+// SPDX-License-Identifier: Apache-2.0
+/*
+Copyright (C) 2026 The Falco Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdk
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestErrorTaxonomy(t *testing.T) {
+	errTest := errors.New("test")
+	if Temporary(nil) != nil || Skip(nil) != nil {
+		t.Errorf("expected nil")
+	}
+
+	tmp := fmt.Errorf("wrapped: %w", Temporary(errTest))
+	if !errors.Is(tmp, ErrTemporary) || !errors.Is(tmp, errTest) || errors.Is(tmp, ErrSkip) {
+		t.Errorf("unexpected matching for error: %v", tmp)
+	}
+	skip := Skip(errTest)
+	if !errors.Is(skip, ErrSkip) || !errors.Is(skip, errTest) || errors.Is(skip, ErrTemporary) {
+		t.Errorf("unexpected matching for error: %v", skip)
+	}
+
+	for _, err := range []error{nil, ErrTimeout, tmp, skip, ErrSkip, fmt.Errorf("wrapped: %w", ErrTimeout)} {
+		if IsFatal(err) {
+			t.Errorf("expected error not to be fatal: %v", err)
+		}
+	}
+	for _, err := range []error{errTest, ErrEOF} {
+		if !IsFatal(err) {
+			t.Errorf("expected error to be fatal: %v", err)
+		}
+	}
+}
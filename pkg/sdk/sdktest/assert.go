@@ -0,0 +1,54 @@
+// SPDX-License-Identifier: Apache-2.0
+/*
+Copyright (C) 2026 The Falco Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdktest
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk"
+	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk/sdktest/internal/values"
+)
+
+// AssertField makes the plugin extract the given field from evt as with
+// ExtractField, and fails the test if the extraction fails, or if the
+// extracted value is not equal to expected. The expected value can be of
+// any of the types accepted by the SetValue method of sdk.ExtractRequest
+// for the type of the field, and is converted in the canonical form of
+// ExtractField before the comparison. A nil expected value asserts that
+// the plugin does not set any value for the field.
+func AssertField(t testing.TB, p Extractor, evt sdk.EventReader, field string, expected interface{}) {
+	t.Helper()
+	req, err := newFieldRequest(p, field)
+	if err != nil {
+		t.Fatalf("can't extract field '%s': %s", field, err.Error())
+	}
+	if expected != nil {
+		if err := values.Check(req.ValFieldType, req.ValIsList, expected); err != nil {
+			t.Fatalf("invalid expected value for field '%s': %s", field, err.Error())
+		}
+	}
+	actual, err := ExtractField(p, evt, field)
+	if err != nil {
+		t.Fatalf("can't extract field '%s': %s", field, err.Error())
+	}
+	expected = values.Canonical(req.ValFieldType, req.ValIsList, expected)
+	if !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("field '%s': expected %v (%T), but extracted %v (%T)", field, expected, expected, actual, actual)
+	}
+}
@@ -0,0 +1,147 @@
+// SPDX-License-Identifier: Apache-2.0
+/*
+Copyright (C) 2026 The Falco Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdktest
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk"
+)
+
+// fakeT records the failure of the test without failing the real test
+type fakeT struct {
+	testing.TB
+	msg string
+}
+
+func (f *fakeT) Helper() {}
+
+func (f *fakeT) Errorf(format string, args ...interface{}) {
+	f.msg = fmt.Sprintf(format, args...)
+}
+
+func (f *fakeT) Fatalf(format string, args ...interface{}) {
+	f.msg = fmt.Sprintf(format, args...)
+	runtime.Goexit()
+}
+
+func assertFails(t *testing.T, contains string, f func(testing.TB)) {
+	ft := &fakeT{TB: t}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		f(ft)
+	}()
+	<-done
+	if !strings.Contains(ft.msg, contains) {
+		t.Fatalf("expected failure containing '%s', but found '%s'", contains, ft.msg)
+	}
+}
+
+func sampleEvent(values ...uint64) *InMemoryEventReader {
+	evt := &InMemoryEventReader{Buffer: make([]byte, len(values)*8)}
+	for i, v := range values {
+		binary.LittleEndian.PutUint64(evt.Buffer[i*8:], v)
+	}
+	return evt
+}
+
+func TestExtractField(t *testing.T) {
+	p := &samplePlugin{}
+	evt := sampleEvent(0x0a000001, 255)
+
+	v, err := ExtractField(p, evt, "sample.value[1]")
+	if err != nil || v != uint64(255) {
+		t.Fatalf("unexpected result: %v, %v", v, err)
+	}
+
+	// values are returned in canonical form
+	v, err = ExtractField(p, evt, "sample.addr")
+	if err != nil || !net.ParseIP("10.0.0.1").Equal(v.(net.IP)) || len(v.(net.IP)) != net.IPv6len {
+		t.Fatalf("unexpected result: %v, %v", v, err)
+	}
+	v, err = ExtractField(p, evt, "sample.hex")
+	if err != nil || strings.Join(v.([]string), ",") != "a000001,ff" {
+		t.Fatalf("unexpected result: %v, %v", v, err)
+	}
+
+	// no value is nil
+	v, err = ExtractField(p, sampleEvent(), "sample.ip")
+	if err != nil || v != nil {
+		t.Fatalf("unexpected result: %v, %v", v, err)
+	}
+
+	// invalid fields and arguments
+	for _, f := range []string{"sample.unknown", "sample.value", "sample.value[8]", "sample.value[x]", "sample.count[0]", "sample.key[other]"} {
+		if _, err := ExtractField(p, evt, f); err == nil {
+			t.Fatalf("expected error for field '%s'", f)
+		}
+	}
+	if _, err := ExtractField(p, evt, "sample.value[0]"); errors.Is(err, sdk.ErrInvalidFieldArg) {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	if _, err := ExtractField(p, evt, "sample.value[8]"); !errors.Is(err, sdk.ErrInvalidFieldArg) {
+		t.Fatalf("expected invalid argument error, but found: %v", err)
+	}
+
+	// extraction errors and contract violations
+	if _, err := ExtractField(p, evt, "sample.value[5]"); err == nil || !strings.Contains(err.Error(), "out of bounds") {
+		t.Fatalf("expected extraction error, but found: %v", err)
+	}
+	if _, err := ExtractField(&samplePlugin{badType: true}, evt, "sample.duration"); err == nil {
+		t.Fatalf("expected invalid value error")
+	}
+	if _, err := ExtractField(&samplePlugin{panicOnShort: true}, &InMemoryEventReader{Buffer: []byte{1}}, "sample.count"); err == nil {
+		t.Fatalf("expected panic error")
+	}
+}
+
+func TestAssertField(t *testing.T) {
+	p := &samplePlugin{}
+	evt := sampleEvent(0x0a000001, 255)
+	AssertField(t, p, evt, "sample.count", uint64(2))
+	AssertField(t, p, evt, "sample.values", []uint64{0x0a000001, 255})
+	AssertField(t, p, evt, "sample.key[hex]", []byte("hex"))
+	AssertField(t, p, evt, "sample.duration", time.Duration(2))
+	AssertField(t, p, evt, "sample.ip", net.ParseIP("10.0.0.1"))
+	AssertField(t, p, evt, "sample.hex", []string{"a000001", "ff"})
+	AssertField(t, p, sampleEvent(), "sample.ip", nil)
+
+	assertFails(t, "expected 3", func(t testing.TB) {
+		AssertField(t, p, evt, "sample.count", uint64(3))
+	})
+	assertFails(t, "invalid expected value", func(t testing.TB) {
+		AssertField(t, p, evt, "sample.count", 2)
+	})
+	assertFails(t, "unknown field", func(t testing.TB) {
+		AssertField(t, p, evt, "sample.unknown", nil)
+	})
+	assertFails(t, "out of bounds", func(t testing.TB) {
+		AssertField(t, p, evt, "sample.value[3]", uint64(0))
+	})
+	assertFails(t, "expected <nil>", func(t testing.TB) {
+		AssertField(t, p, evt, "sample.ip", nil)
+	})
+}
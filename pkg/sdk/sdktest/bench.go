@@ -0,0 +1,56 @@
+// SPDX-License-Identifier: Apache-2.0
+/*
+Copyright (C) 2026 The Falco Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdktest
+
+import (
+	"testing"
+
+	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk"
+	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk/sdktest/internal/bench"
+)
+
+// errNoHarness is reported by the benchmarks if the sdktest/harness package
+// is not imported
+const errNoHarness = "sdktest: benchmarks require importing the sdktest/harness package"
+
+// BenchmarkExtract benchmarks the field extraction of the plugin through
+// the C symbols it exports, in both the sync and async extraction modes.
+// This forwards to the BenchmarkExtract function of the sdktest/harness
+// package, which documents the sub-benchmarks and metrics reported, and
+// which must be imported by the benchmark, for example with:
+//
+//	import _ "github.com/falcosecurity/plugin-sdk-go/pkg/sdk/sdktest/harness"
+func BenchmarkExtract(b *testing.B, config string, evts []sdk.EventReader, fields ...string) {
+	b.Helper()
+	if bench.Extract == nil {
+		b.Fatal(errNoHarness)
+	}
+	bench.Extract(b, config, evts, fields...)
+}
+
+// BenchmarkNextBatch benchmarks the event production of the plugin through
+// the plugin_next_batch C symbol. This forwards to the BenchmarkNextBatch
+// function of the sdktest/harness package, which must be imported by the
+// benchmark as for BenchmarkExtract.
+func BenchmarkNextBatch(b *testing.B, config, params string) {
+	b.Helper()
+	if bench.NextBatch == nil {
+		b.Fatal(errNoHarness)
+	}
+	bench.NextBatch(b, config, params)
+}
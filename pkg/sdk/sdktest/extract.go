@@ -0,0 +1,242 @@
+// SPDX-License-Identifier: Apache-2.0
+/*
+Copyright (C) 2026 The Falco Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdktest
+
+import (
+	"fmt"
+	"math/rand"
+	"runtime/debug"
+	"strconv"
+	"strings"
+
+	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk"
+	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk/sdktest/internal/values"
+)
+
+// maxRandomKeyLen is the max length of the keys generated as random
+// arguments of the fields with the IsKey flag enabled
+const maxRandomKeyLen = 32
+
+// newExtractRequest returns a request for the i-th field of the given list
+func newExtractRequest(fields []sdk.FieldEntry, i int) (*InMemoryExtractRequest, error) {
+	ftype, err := values.FieldType(&fields[i])
+	if err != nil {
+		return nil, err
+	}
+	return &InMemoryExtractRequest{
+		ValFieldID:   uint64(i),
+		ValFieldType: ftype,
+		ValField:     fields[i].Name,
+		ValIsList:    fields[i].IsList,
+	}, nil
+}
+
+// extract makes the plugin extract the value of req from evt. The error
+// returned by the plugin is returned as err. If the plugin panics or sets
+// a value of an invalid type, the contract violation is returned as bad.
+func extract(p Extractor, req *InMemoryExtractRequest, evt sdk.EventReader) (err, bad error) {
+	defer func() {
+		if r := recover(); r != nil {
+			bad = fmt.Errorf("panic while extracting field '%s' (arg key: '%s', arg index: %d, arg present: %v): %v\n%s",
+				req.ValField, req.ValArgKey, req.ValArgIndex, req.ValArgPresent, r, debug.Stack())
+		}
+	}()
+	req.ValValue = nil
+	if err = p.Extract(req, evt); err != nil || req.ValValue == nil {
+		return err, nil
+	}
+	if err := values.Check(req.ValFieldType, req.ValIsList, req.ValValue); err != nil {
+		return nil, fmt.Errorf("invalid value extracted for field '%s': %s", req.ValField, err.Error())
+	}
+	return nil, nil
+}
+
+// setRandomArg sets a random argument in req, compatibly with the argument
+// flags of the given field. The argument might still be rejected by
+// the Validate method of sdk.FieldEntryArg.
+func setRandomArg(f *sdk.FieldEntry, req *InMemoryExtractRequest, rnd *rand.Rand) {
+	if !f.Arg.IsIndex && !f.Arg.IsKey {
+		return
+	}
+	req.ValArgPresent = f.Arg.IsRequired || rnd.Intn(2) == 0
+	if !req.ValArgPresent {
+		return
+	}
+	if f.Arg.IsIndex {
+		switch {
+		case f.Arg.IndexRange != nil && rnd.Intn(2) == 0:
+			// note: n is zero if the range covers all the possible indexes
+			n := f.Arg.IndexRange.Max - f.Arg.IndexRange.Min + 1
+			req.ValArgIndex = rnd.Uint64()
+			if n != 0 {
+				req.ValArgIndex = f.Arg.IndexRange.Min + req.ValArgIndex%n
+			}
+		case rnd.Intn(2) == 0:
+			req.ValArgIndex = uint64(rnd.Intn(16))
+		default:
+			req.ValArgIndex = rnd.Uint64()
+		}
+		return
+	}
+	if len(f.Arg.AllowedKeys) > 0 && rnd.Intn(4) != 0 {
+		req.ValArgKey = f.Arg.AllowedKeys[rnd.Intn(len(f.Arg.AllowedKeys))]
+		return
+	}
+	// keys are passed as C strings, so they can't contain null characters
+	key := make([]byte, rnd.Intn(maxRandomKeyLen+1))
+	for i := range key {
+		key[i] = byte(1 + rnd.Intn(255))
+	}
+	req.ValArgKey = string(key)
+}
+
+// checkEvent requests every field of the given plugin from the given event,
+// with random arguments generated from rnd, and makes the plugin produce the
+// string representation of the event if it implements sdk.Stringer. An error
+// is returned if the plugin panics or sets a value of an invalid type.
+// Extraction errors are not reported, as the event might be malformed.
+func checkEvent(p Extractor, evt sdk.EventReader, rnd *rand.Rand) error {
+	fields := p.Fields()
+	for i := range fields {
+		req, err := newExtractRequest(fields, i)
+		if err != nil {
+			return err
+		}
+		setRandomArg(&fields[i], req, rnd)
+		// the SDK does not pass requests with invalid arguments to the plugin
+		if fields[i].Arg.Validate(req) != nil {
+			continue
+		}
+		if _, bad := extract(p, req, evt); bad != nil {
+			return bad
+		}
+	}
+	if s, ok := p.(sdk.Stringer); ok {
+		return checkString(s, evt)
+	}
+	return nil
+}
+
+func checkString(s sdk.Stringer, evt sdk.EventReader) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic while converting event to string: %v\n%s", r, debug.Stack())
+		}
+	}()
+	s.String(evt)
+	return nil
+}
+
+// Field is a field of a plugin, along with its optional argument, as
+// resolved by ParseField.
+type Field struct {
+	// Index is the index of the field in the fields of the plugin
+	Index      int
+	Name       string
+	ArgKey     string
+	ArgIndex   uint64
+	ArgPresent bool
+}
+
+// ParseField resolves the given field among the given fields of a plugin.
+// The field is expressed as in ExtractField, in the form name or name[arg],
+// where name is either the name or an alias of a field. Name is set to the
+// name of the field in the result, even if an alias is used. An error
+// wrapping sdk.ErrInvalidFieldArg is returned if the argument is not
+// accepted by the field, and the argument is not validated otherwise.
+func ParseField(fields []sdk.FieldEntry, field string) (*Field, error) {
+	name, arg, hasArg := field, "", false
+	if i := strings.IndexByte(field, '['); i >= 0 && strings.HasSuffix(field, "]") {
+		name, arg, hasArg = field[:i], field[i+1:len(field)-1], true
+	}
+	for i := range fields {
+		match := fields[i].Name == name
+		for _, a := range fields[i].Aliases {
+			match = match || a == name
+		}
+		if !match {
+			continue
+		}
+		res := &Field{Index: i, Name: fields[i].Name, ArgPresent: hasArg}
+		if hasArg {
+			switch {
+			case fields[i].Arg.IsIndex:
+				idx, err := strconv.ParseUint(arg, 10, 64)
+				if err != nil {
+					return nil, fmt.Errorf("%w: field '%s' requires an index argument", sdk.ErrInvalidFieldArg, name)
+				}
+				res.ArgIndex = idx
+			case fields[i].Arg.IsKey:
+				res.ArgKey = arg
+			default:
+				return nil, fmt.Errorf("%w: field '%s' does not accept arguments", sdk.ErrInvalidFieldArg, name)
+			}
+		}
+		return res, nil
+	}
+	return nil, fmt.Errorf("unknown field: %s", name)
+}
+
+// newFieldRequest returns a request for the given field of the plugin,
+// with the field expressed in the form name or name[arg]
+func newFieldRequest(p Extractor, field string) (*InMemoryExtractRequest, error) {
+	fields := p.Fields()
+	f, err := ParseField(fields, field)
+	if err != nil {
+		return nil, err
+	}
+	req, err := newExtractRequest(fields, f.Index)
+	if err != nil {
+		return nil, err
+	}
+	req.ValArgPresent = f.ArgPresent
+	req.ValArgKey = f.ArgKey
+	req.ValArgIndex = f.ArgIndex
+	if err := fields[f.Index].Arg.Validate(req); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// ExtractField makes the plugin extract the given field from evt, as the
+// SDK would do in plugin_extract_fields. The field is either a field name
+// or an alias, optionally followed by an argument in square brackets, such
+// as "my.field", "my.field[0]", or "my.field[key]". Arguments are validated
+// as described by sdk.FieldEntryArg before invoking the plugin.
+//
+// ExtractField returns nil if the plugin did not set any value. Otherwise,
+// the value is returned in a canonical form that depends on the field type:
+// uint64, bool, string, time.Duration, time.Time, net.IP, or net.IPNet, or
+// a slice of them for list fields. Times are in UTC, IP addresses are in
+// their 16-byte form, and IP networks have no mask, as the mask is not
+// passed to the framework. An error is returned if the extraction fails,
+// or if the plugin panics or sets a value of an invalid type.
+func ExtractField(p Extractor, evt sdk.EventReader, field string) (interface{}, error) {
+	req, err := newFieldRequest(p, field)
+	if err != nil {
+		return nil, err
+	}
+	err, bad := extract(p, req, evt)
+	if bad != nil {
+		return nil, bad
+	}
+	if err != nil {
+		return nil, err
+	}
+	return values.Canonical(req.ValFieldType, req.ValIsList, req.ValValue), nil
+}
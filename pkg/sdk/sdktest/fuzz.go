@@ -0,0 +1,69 @@
+//go:build go1.18
+
+// SPDX-License-Identifier: Apache-2.0
+/*
+Copyright (C) 2026 The Falco Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdktest
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk"
+)
+
+// FuzzExtractor fuzzes the field extraction of the plugins returned by
+// newPlugin, with Go native fuzzing. For each input of the fuzzing corpus,
+// a new plugin is created and an InMemoryEventReader is built using the
+// input as the event payload. Then, every field returned by Fields is
+// requested with random arguments compatible with the field definition,
+// and the event is converted to a string if the plugin implements
+// sdk.Stringer. The test fails if the plugin panics or sets values of an
+// invalid type. Errors returned by the plugin are not considered failures.
+//
+// The given seeds are added as event payloads to the fuzzing corpus, along
+// with the corpus stored in testdata/fuzz. New plugins are created for each
+// input so that failures can be reproduced, and are destroyed afterwards if
+// they implement sdk.Destroyer. This is meant to be called from a fuzz
+// target, and works with `go test -fuzz`:
+//
+//	func FuzzMyPlugin(f *testing.F) {
+//		sdktest.FuzzExtractor(f, func() sdktest.Extractor {
+//			p := &MyPlugin{}
+//			p.Init("")
+//			return p
+//		}, []byte("sample event"))
+//	}
+func FuzzExtractor(f *testing.F, newPlugin func() Extractor, seeds ...[]byte) {
+	for i, s := range seeds {
+		f.Add(s, uint64(i+1), uint64(0), int64(i))
+	}
+	f.Fuzz(func(t *testing.T, data []byte, evtNum, timestamp uint64, seed int64) {
+		p := newPlugin()
+		if d, ok := p.(sdk.Destroyer); ok {
+			defer d.Destroy()
+		}
+		evt := &InMemoryEventReader{
+			Buffer:       data,
+			ValEventNum:  evtNum,
+			ValTimestamp: timestamp,
+		}
+		if err := checkEvent(p, evt, rand.New(rand.NewSource(seed))); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
@@ -0,0 +1,286 @@
+// SPDX-License-Identifier: Apache-2.0
+/*
+Copyright (C) 2026 The Falco Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdktest
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk"
+)
+
+var updateGolden = flag.Bool("sdktest.update", false, "regenerate the golden files instead of comparing against them")
+
+// maxGoldenDiffs is the max number of differences reported when comparing
+// against a golden file
+const maxGoldenDiffs = 10
+
+// goldenFile is the content of a golden file. For event streams, each event
+// has its data. For extracted fields, each event has the values of the
+// fields, and the data is omitted as it is an input of the test.
+type goldenFile struct {
+	Fields []string      `json:"fields,omitempty"`
+	Events []goldenEvent `json:"events"`
+}
+
+type goldenEvent struct {
+	Num       uint64                     `json:"num"`
+	Timestamp uint64                     `json:"timestamp"`
+	Data      []byte                     `json:"data,omitempty"`
+	Values    map[string]json.RawMessage `json:"values,omitempty"`
+}
+
+// AssertGoldenEvents compares the given events, such as the ones returned
+// by the NextBatch method of harness.Instance once collected in a slice of
+// sdk.EventReader, with the ones recorded in the golden file
+// at path, and fails the test if they differ. If the test runs with the
+// -sdktest.update flag, the golden file is created or overwritten with
+// the given events instead.
+//
+// Golden files are JSON documents, in which the data of the events is
+// encoded in base64. The events of a golden file can be read with
+// ReadGoldenEvents, and used as the recorded input of other tests.
+func AssertGoldenEvents(t testing.TB, path string, evts []sdk.EventReader) {
+	t.Helper()
+	got := &goldenFile{Events: make([]goldenEvent, len(evts))}
+	for i, evt := range evts {
+		got.Events[i] = goldenEvent{
+			Num:       evt.EventNum(),
+			Timestamp: evt.Timestamp(),
+			Data:      append([]byte(nil), evt.Bytes()...),
+		}
+	}
+	assertGolden(t, path, got)
+}
+
+// ReadGoldenEvents returns the events recorded in the golden file at path,
+// as written by AssertGoldenEvents.
+func ReadGoldenEvents(path string) ([]sdk.EventReader, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var f goldenFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("malformed golden file %s: %s", path, err.Error())
+	}
+	res := make([]sdk.EventReader, len(f.Events))
+	for i, e := range f.Events {
+		res[i] = &InMemoryEventReader{Buffer: e.Data, ValEventNum: e.Num, ValTimestamp: e.Timestamp}
+	}
+	return res, nil
+}
+
+// AssertGoldenFields extracts the given fields from each of the given
+// events with ExtractField, and compares the extracted values with the ones
+// recorded in the golden file at path. The test fails if the values differ,
+// or if any extraction fails. If the test runs with the -sdktest.update
+// flag, the golden file is created or overwritten with the extracted
+// values instead.
+//
+// Values are recorded in JSON in a readable form that depends on the field
+// type: durations and IP addresses are strings as returned by their String
+// method, times are strings in the RFC 3339 format, and fields with no value
+// are null.
+func AssertGoldenFields(t testing.TB, path string, p Extractor, evts []sdk.EventReader, fields ...string) {
+	t.Helper()
+	values := make([][]interface{}, len(evts))
+	for i, evt := range evts {
+		values[i] = make([]interface{}, len(fields))
+		for j, f := range fields {
+			v, err := ExtractField(p, evt, f)
+			if err != nil {
+				t.Fatalf("can't extract field '%s' from event %d: %s", f, i, err.Error())
+			}
+			values[i][j] = v
+		}
+	}
+	AssertGoldenValues(t, path, evts, fields, values)
+}
+
+// AssertGoldenValues is like AssertGoldenFields, but compares values
+// already extracted from the given events by other means, such as by a
+// plugin loaded as a shared library. The j-th value of the i-th element
+// of values is the one of the j-th field extracted from the i-th event,
+// as returned by ExtractField or by the ExtractBatch method of loader.Plugin.
+func AssertGoldenValues(t testing.TB, path string, evts []sdk.EventReader, fields []string, values [][]interface{}) {
+	t.Helper()
+	if len(values) != len(evts) {
+		t.Fatalf("there are %d events, but values for %d", len(evts), len(values))
+	}
+	got := &goldenFile{Fields: fields, Events: make([]goldenEvent, len(evts))}
+	for i, evt := range evts {
+		if len(values[i]) != len(fields) {
+			t.Fatalf("there are %d fields, but %d values for event %d", len(fields), len(values[i]), i)
+		}
+		got.Events[i] = newGoldenEvent(t, evt, fields, values[i])
+	}
+	assertGolden(t, path, got)
+}
+
+// newGoldenEvent returns the golden representation of the given values
+// extracted from evt
+func newGoldenEvent(t testing.TB, evt sdk.EventReader, fields []string, values []interface{}) goldenEvent {
+	t.Helper()
+	res := goldenEvent{
+		Num:       evt.EventNum(),
+		Timestamp: evt.Timestamp(),
+		Values:    make(map[string]json.RawMessage, len(fields)),
+	}
+	for i, f := range fields {
+		b, err := json.Marshal(goldenValue(values[i]))
+		if err != nil {
+			t.Fatalf("can't encode the value of field '%s': %s", f, err.Error())
+		}
+		res.Values[f] = b
+	}
+	return res
+}
+
+// goldenValue converts an extracted value in a form that is readable once
+// encoded in JSON
+func goldenValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case nil, uint64, bool, string:
+		return v
+	case []byte:
+		return string(v)
+	case time.Duration:
+		return v.String()
+	case time.Time:
+		return v.UTC().Format(time.RFC3339Nano)
+	case net.IP:
+		return v.String()
+	case net.IPNet:
+		// the mask is not passed to the framework
+		return v.IP.String()
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice {
+		return v
+	}
+	res := make([]interface{}, rv.Len())
+	for i := range res {
+		res[i] = goldenValue(rv.Index(i).Interface())
+	}
+	return res
+}
+
+// assertGolden compares got with the content of the golden file at path,
+// or overwrites the golden file if the -sdktest.update flag is set
+func assertGolden(t testing.TB, path string, got *goldenFile) {
+	t.Helper()
+	data, err := json.MarshalIndent(got, "", "  ")
+	if err != nil {
+		t.Fatalf("can't encode golden file: %s", err.Error())
+	}
+	data = append(data, '\n')
+
+	if *updateGolden {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("can't create golden file: %s", err.Error())
+		}
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Fatalf("can't write golden file: %s", err.Error())
+		}
+		t.Logf("updated golden file %s", path)
+		return
+	}
+
+	expected, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("can't read golden file: %s (run the test with -sdktest.update to create it)", err.Error())
+	}
+	if bytes.Equal(expected, data) {
+		return
+	}
+	var want goldenFile
+	if err := json.Unmarshal(expected, &want); err != nil {
+		t.Fatalf("malformed golden file %s: %s", path, err.Error())
+	}
+	if diffs := diffGolden(&want, got); len(diffs) > 0 {
+		t.Errorf("results differ from golden file %s (run the test with -sdktest.update to regenerate it):\n%s", path, strings.Join(diffs, "\n"))
+	}
+}
+
+// diffGolden returns the differences between the content of two golden
+// files, up to maxGoldenDiffs
+func diffGolden(want, got *goldenFile) []string {
+	var diffs []string
+	if strings.Join(want.Fields, ",") != strings.Join(got.Fields, ",") {
+		diffs = append(diffs, fmt.Sprintf("fields are %v, but golden ones are %v", got.Fields, want.Fields))
+	}
+	if len(want.Events) != len(got.Events) {
+		diffs = append(diffs, fmt.Sprintf("there are %d events, but golden ones are %d", len(got.Events), len(want.Events)))
+	}
+	for i := 0; i < len(want.Events) && i < len(got.Events); i++ {
+		w, g := &want.Events[i], &got.Events[i]
+		if w.Num != g.Num {
+			diffs = append(diffs, fmt.Sprintf("event %d: num is %d, but golden one is %d", i, g.Num, w.Num))
+		}
+		if w.Timestamp != g.Timestamp {
+			diffs = append(diffs, fmt.Sprintf("event %d: timestamp is %d, but golden one is %d", i, g.Timestamp, w.Timestamp))
+		}
+		if !bytes.Equal(w.Data, g.Data) {
+			diffs = append(diffs, fmt.Sprintf("event %d: data is %q, but golden one is %q", i, g.Data, w.Data))
+		}
+		var names []string
+		for f := range g.Values {
+			names = append(names, f)
+		}
+		for f := range w.Values {
+			if _, ok := g.Values[f]; !ok {
+				names = append(names, f)
+			}
+		}
+		sort.Strings(names)
+		for _, f := range names {
+			gv, wv := compactJSON(g.Values[f]), compactJSON(w.Values[f])
+			if gv != wv {
+				diffs = append(diffs, fmt.Sprintf("event %d: field '%s' is %s, but golden one is %s", i, f, gv, wv))
+			}
+		}
+	}
+	if len(diffs) > maxGoldenDiffs {
+		diffs = append(diffs[:maxGoldenDiffs], fmt.Sprintf("... and %d more differences", len(diffs)-maxGoldenDiffs))
+	}
+	return diffs
+}
+
+// compactJSON returns the compact form of a JSON value, or "missing" if
+// the value is empty
+func compactJSON(v json.RawMessage) string {
+	if len(v) == 0 {
+		return "missing"
+	}
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, v); err != nil {
+		return string(v)
+	}
+	return buf.String()
+}
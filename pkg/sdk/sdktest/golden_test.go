@@ -0,0 +1,149 @@
+// SPDX-License-Identifier: Apache-2.0
+/*
+Copyright (C) 2026 The Falco Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdktest
+
+import (
+	"net"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk"
+)
+
+// setUpdateGolden sets the -sdktest.update flag for the duration of the test
+func setUpdateGolden(t *testing.T, update bool) {
+	prev := *updateGolden
+	*updateGolden = update
+	t.Cleanup(func() { *updateGolden = prev })
+}
+
+func sampleEvents() []sdk.EventReader {
+	var res []sdk.EventReader
+	for i, values := range [][]uint64{{0x0a000001, 255}, {}, {1, 2, 3}} {
+		evt := sampleEvent(values...)
+		evt.ValEventNum = uint64(i + 1)
+		evt.ValTimestamp = uint64(i * 1000)
+		res = append(res, evt)
+	}
+	return res
+}
+
+func TestGoldenEvents(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "testdata", "events.json")
+	evts := sampleEvents()
+
+	assertFails(t, "run the test with -sdktest.update to create it", func(t testing.TB) {
+		AssertGoldenEvents(t, path, evts)
+	})
+
+	setUpdateGolden(t, true)
+	AssertGoldenEvents(t, path, evts)
+	setUpdateGolden(t, false)
+	AssertGoldenEvents(t, path, evts)
+
+	read, err := ReadGoldenEvents(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	if len(read) != len(evts) {
+		t.Fatalf("expected %d events, but found %d", len(evts), len(read))
+	}
+	AssertGoldenEvents(t, path, read)
+
+	evts[2].(*InMemoryEventReader).Buffer[0] = 9
+	evts[1].(*InMemoryEventReader).ValTimestamp = 5
+	assertFails(t, "event 1: timestamp is 5, but golden one is 1000", func(t testing.TB) {
+		AssertGoldenEvents(t, path, evts)
+	})
+	assertFails(t, "event 2: data is", func(t testing.TB) {
+		AssertGoldenEvents(t, path, evts)
+	})
+	assertFails(t, "there are 2 events, but golden ones are 3", func(t testing.TB) {
+		AssertGoldenEvents(t, path, evts[:2])
+	})
+}
+
+func TestGoldenFields(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fields.json")
+	p := &samplePlugin{}
+	evts := sampleEvents()
+	fields := []string{"sample.count", "sample.values", "sample.addr", "sample.key[hex]", "sample.duration"}
+
+	setUpdateGolden(t, true)
+	AssertGoldenFields(t, path, p, evts, fields...)
+	setUpdateGolden(t, false)
+	AssertGoldenFields(t, path, p, evts, fields...)
+
+	evts[0].(*InMemoryEventReader).Buffer[0] = 2
+	assertFails(t, `event 0: field 'sample.addr' is "10.0.0.2", but golden one is "10.0.0.1"`, func(t testing.TB) {
+		AssertGoldenFields(t, path, p, evts, fields...)
+	})
+	assertFails(t, "fields are [sample.count], but golden ones are", func(t testing.TB) {
+		AssertGoldenFields(t, path, p, evts, fields[0])
+	})
+	assertFails(t, "can't extract field 'sample.value' from event 0", func(t testing.TB) {
+		AssertGoldenFields(t, path, p, evts, "sample.value")
+	})
+}
+
+func TestGoldenValues(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fields.json")
+	evts := sampleEvents()[:2]
+	fields := []string{"test.num", "test.ip"}
+	values := [][]interface{}{
+		{uint64(1), net.IPv4(10, 0, 0, 1)},
+		{uint64(2), nil},
+	}
+
+	setUpdateGolden(t, true)
+	AssertGoldenValues(t, path, evts, fields, values)
+	setUpdateGolden(t, false)
+	AssertGoldenValues(t, path, evts, fields, values)
+
+	values[1][1] = net.IPv4(10, 0, 0, 2)
+	assertFails(t, `event 1: field 'test.ip' is "10.0.0.2", but golden one is null`, func(t testing.TB) {
+		AssertGoldenValues(t, path, evts, fields, values)
+	})
+	assertFails(t, "there are 2 fields, but 1 values for event 0", func(t testing.TB) {
+		AssertGoldenValues(t, path, evts, fields, [][]interface{}{{nil}, {nil}})
+	})
+	assertFails(t, "there are 2 events, but values for 1", func(t testing.TB) {
+		AssertGoldenValues(t, path, evts, fields, values[:1])
+	})
+}
+
+func TestGoldenValue(t *testing.T) {
+	evt := sampleEvent(0x0a000001, 255)
+	for field, expected := range map[string]interface{}{
+		"sample.count":    uint64(2),
+		"sample.addr":     "10.0.0.1",
+		"sample.duration": "2ns",
+		"sample.key[hex]": "hex",
+		"sample.hex":      []interface{}{"a000001", "ff"},
+		"sample.values":   []interface{}{uint64(0x0a000001), uint64(255)},
+	} {
+		v, err := ExtractField(&samplePlugin{}, evt, field)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err.Error())
+		}
+		if got := goldenValue(v); !reflect.DeepEqual(got, expected) {
+			t.Errorf("field %s: expected %v, but found %v", field, expected, got)
+		}
+	}
+}
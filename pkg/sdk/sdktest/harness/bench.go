@@ -0,0 +1,171 @@
+// SPDX-License-Identifier: Apache-2.0
+/*
+Copyright (C) 2026 The Falco Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package harness
+
+import (
+	"errors"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk"
+	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk/sdktest/internal/bench"
+	sdkextract "github.com/falcosecurity/plugin-sdk-go/pkg/sdk/symbols/extract"
+)
+
+// maxIdleBatches is the max number of consecutive calls to plugin_next_batch
+// returning no events tolerated by BenchmarkNextBatch
+const maxIdleBatches = 1000
+
+func init() {
+	bench.Extract = BenchmarkExtract
+	bench.NextBatch = BenchmarkNextBatch
+}
+
+// BenchmarkExtract benchmarks the field extraction of the plugin through
+// the C symbols it exports, as with Harness, by extracting fields from each
+// of the given events in turn. The benchmark runs once with the sync
+// extraction mode and once with the async one, and the plugin is
+// initialized with the given config in each of them. Each mode has the
+// following sub-benchmarks:
+//   - crossing: invokes plugin_extract_fields with no fields, which measures
+//     the overhead of the C -> Go calls and of the SDK
+//   - field=<field>: extracts each of the given fields alone
+//   - fields: extracts all the given fields at once, and reports the
+//     ns/field metric
+//
+// Each operation is the extraction from a single event, so ns/op and
+// allocs/op are per event. Allocations include the ones of the SDK and of
+// the plugin code. The sub-benchmarks are named only after the extraction
+// mode and the given fields, so that the results of different versions of
+// the SDK or of the plugin can be compared with tools like benchstat.
+//
+// This is also available as BenchmarkExtract in the sdktest package once
+// this package is imported. Usage example:
+//
+//	func BenchmarkExtract(b *testing.B) {
+//		evts, err := sdktest.ReadGoldenEvents("testdata/events.json")
+//		if err != nil {
+//			b.Fatal(err)
+//		}
+//		sdktest.BenchmarkExtract(b, "", evts, "my.field", "my.other[key]")
+//	}
+//
+// The async mode is skipped if GOMAXPROCS is 1, as the SDK does not use the
+// async extraction optimization in that case. Plugins calling the SetAsync
+// function of the sdk/symbols/extract package in their Init method override
+// the mode of the benchmark.
+func BenchmarkExtract(b *testing.B, config string, evts []sdk.EventReader, fields ...string) {
+	b.Helper()
+	for _, async := range []bool{false, true} {
+		mode := "sync"
+		if async {
+			mode = "async"
+		}
+		b.Run(mode, func(b *testing.B) {
+			if async && runtime.GOMAXPROCS(0) < 2 {
+				b.Skip("async extraction requires GOMAXPROCS > 1")
+			}
+			prev := sdkextract.Async()
+			sdkextract.SetAsync(async)
+			h := New(b, config)
+			sdkextract.SetAsync(prev)
+
+			b.Run("crossing", func(b *testing.B) {
+				benchmarkExtract(b, h, evts, nil)
+			})
+			for _, f := range fields {
+				f := f
+				b.Run("field="+f, func(b *testing.B) {
+					benchmarkExtract(b, h, evts, []string{f})
+				})
+			}
+			if len(fields) > 1 {
+				b.Run("fields", func(b *testing.B) {
+					benchmarkExtract(b, h, evts, fields)
+				})
+			}
+		})
+	}
+}
+
+func benchmarkExtract(b *testing.B, h *Harness, evts []sdk.EventReader, fields []string) {
+	run, free, err := h.extractLoop(evts, fields)
+	defer free()
+	if err != nil {
+		b.Fatalf("can't prepare extraction: %s", err.Error())
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	start := time.Now()
+	err = run(b.N)
+	elapsed := time.Since(start)
+	b.StopTimer()
+	if err != nil {
+		b.Fatalf("extraction failed: %s", err.Error())
+	}
+	if len(fields) > 1 {
+		b.ReportMetric(float64(elapsed.Nanoseconds())/float64(b.N*len(fields)), "ns/field")
+	}
+}
+
+// BenchmarkNextBatch benchmarks the event production of the plugin through
+// the plugin_next_batch C symbol, as with Harness, after initializing the
+// plugin with the given config and opening a capture instance with the
+// given params. The benchmark runs in the "next_batch" sub-benchmark.
+//
+// Each operation is a produced event, so ns/op and allocs/op are per event,
+// and the events/s metric is also reported. Events are not copied nor
+// checked. If the plugin reports the end of the capture, the capture
+// instance is opened again outside of the measured time. The benchmark
+// fails if the plugin returns no events for 1000 consecutive calls.
+func BenchmarkNextBatch(b *testing.B, config, params string) {
+	b.Helper()
+	h := New(b, config)
+	b.Run("next_batch", func(b *testing.B) {
+		var count uint64
+		var elapsed time.Duration
+		b.ReportAllocs()
+		b.ResetTimer()
+		for count < uint64(b.N) {
+			b.StopTimer()
+			inst, err := h.Open(params)
+			if err != nil {
+				b.Fatalf("can't open capture instance: %s", err.Error())
+			}
+			b.StartTimer()
+			start := time.Now()
+			n, err := inst.nextBatchLoop(uint64(b.N)-count, maxIdleBatches)
+			elapsed += time.Since(start)
+			b.StopTimer()
+			inst.Close()
+			count += n
+			switch {
+			case errors.Is(err, sdk.ErrEOF):
+				if n == 0 {
+					b.Fatalf("capture ended with no events")
+				}
+			case errors.Is(err, sdk.ErrTimeout):
+				b.Fatalf("plugin returned no events for %d consecutive calls", maxIdleBatches)
+			case err != nil:
+				b.Fatalf("can't read events: %s", err.Error())
+			}
+		}
+		b.ReportMetric(float64(count)/elapsed.Seconds(), "events/s")
+	})
+}
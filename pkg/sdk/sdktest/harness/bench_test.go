@@ -0,0 +1,87 @@
+// SPDX-License-Identifier: Apache-2.0
+/*
+Copyright (C) 2026 The Falco Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package harness
+
+import (
+	"encoding/binary"
+	"errors"
+	"testing"
+
+	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk"
+	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk/sdktest"
+)
+
+func harnessEvents(n int) []sdk.EventReader {
+	res := make([]sdk.EventReader, n)
+	for i := range res {
+		evt := &sdktest.InMemoryEventReader{Buffer: make([]byte, 8), ValEventNum: uint64(i + 1)}
+		binary.LittleEndian.PutUint64(evt.Buffer, uint64(i))
+		res[i] = evt
+	}
+	return res
+}
+
+func TestBenchmarkLoops(t *testing.T) {
+	setHarnessFactory(false)
+	h := New(t, "")
+
+	for _, fields := range [][]string{nil, {"test.num", "test.ips"}} {
+		run, free, err := h.extractLoop(harnessEvents(3), fields)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := run(10); err != nil {
+			t.Fatalf("unexpected error: %s", err.Error())
+		}
+		free()
+	}
+	run, free, err := h.extractLoop(harnessEvents(3), []string{"test.fail"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := run(10); err == nil || err.Error() != "extraction failure" {
+		t.Fatalf("expected extraction failure, but found: %v", err)
+	}
+	free()
+	if _, _, err := h.extractLoop(nil, nil); err == nil {
+		t.Fatalf("expected error with no events")
+	}
+
+	inst, err := h.Open("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, err := inst.nextBatchLoop(100, maxIdleBatches)
+	if err != nil || n < 100 {
+		t.Fatalf("unexpected result: %d, %v", n, err)
+	}
+	m, err := inst.nextBatchLoop(testHarnessNumEvents, maxIdleBatches)
+	if !errors.Is(err, sdk.ErrEOF) || n+m != testHarnessNumEvents {
+		t.Fatalf("unexpected result: %d, %v", n+m, err)
+	}
+}
+
+func BenchmarkHarnessExtract(b *testing.B) {
+	setHarnessFactory(false)
+	sdktest.BenchmarkExtract(b, "", harnessEvents(16), "test.num", "test.str", "test.ips")
+}
+
+func BenchmarkHarnessNextBatch(b *testing.B) {
+	setHarnessFactory(false)
+	sdktest.BenchmarkNextBatch(b, "", "")
+}
@@ -0,0 +1,753 @@
+// SPDX-License-Identifier: Apache-2.0
+/*
+Copyright (C) 2026 The Falco Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package harness drives plugins built with this SDK through the C symbols
+// they export, as a plugin framework would do after loading them as shared
+// libraries, but within the process of a regular `go test`. It also
+// measures the performance of field extraction and event production
+// through those symbols.
+//
+// Importing this package links the C symbols of all the sdk/symbols
+// packages in the test binary.
+package harness
+
+/*
+#include <stdlib.h>
+#include "../../plugin_api.h"
+
+// Defined in the sdk/symbols packages. Plugin states and capture instances
+// are cgo.Handle values, so they are declared as integers here to not keep
+// them in Go pointer types.
+extern uintptr_t plugin_init(const ss_plugin_init_input *in, ss_plugin_rc *rc);
+extern void plugin_destroy(uintptr_t s);
+extern const char* plugin_get_last_error(uintptr_t s);
+extern const char* plugin_get_fields();
+extern uint32_t plugin_get_id();
+extern const char* plugin_get_event_source();
+extern uintptr_t plugin_open(uintptr_t s, const char* params, ss_plugin_rc* rc);
+extern void plugin_close(uintptr_t s, uintptr_t h);
+extern ss_plugin_rc plugin_next_batch(uintptr_t s, uintptr_t h, uint32_t *nevts, ss_plugin_event ***evts);
+extern ss_plugin_rc plugin_extract_fields(uintptr_t s, const ss_plugin_event_input *evt, const ss_plugin_field_extract_input* in);
+extern const char* plugin_event_to_string(uintptr_t s, const ss_plugin_event_input *evt);
+
+// Defined in log.go
+extern void harnessLog(uintptr_t o, char* component, char* msg, int sev);
+
+// The owner simulated by the harness is identified by an integer, which
+// the plugin passes back as an opaque pointer
+static const char* harness_get_owner_last_error(ss_plugin_owner_t* o)
+{
+	return NULL;
+}
+
+static void harness_log(ss_plugin_owner_t* o, const char* component, const char* msg, ss_plugin_log_severity sev)
+{
+	harnessLog((uintptr_t) o, (char*) component, (char*) msg, (int) sev);
+}
+
+static uintptr_t harness_init(uintptr_t owner, const char* config, ss_plugin_rc* rc)
+{
+	ss_plugin_init_input in = {0};
+	in.config = config;
+	in.owner = (ss_plugin_owner_t*) owner;
+	in.get_owner_last_error = harness_get_owner_last_error;
+	in.log_fn = harness_log;
+	return plugin_init(&in, rc);
+}
+
+static ss_plugin_rc harness_extract(uintptr_t owner, uintptr_t s, const ss_plugin_event_input *evt, uint32_t num_fields, ss_plugin_extract_field *fields)
+{
+	ss_plugin_field_extract_input in = {0};
+	in.owner = (ss_plugin_owner_t*) owner;
+	in.get_owner_last_error = harness_get_owner_last_error;
+	in.num_fields = num_fields;
+	in.fields = fields;
+	return plugin_extract_fields(s, evt, &in);
+}
+
+// Extracts the given fields n times, from each of the given events in turn,
+// as the framework would do. This runs in C so that benchmarks only measure
+// the C -> Go calls.
+static ss_plugin_rc harness_extract_loop(uintptr_t owner, uintptr_t s, const ss_plugin_event_input *evts, uint32_t nevts, uint32_t num_fields, ss_plugin_extract_field *fields, uint64_t n)
+{
+	ss_plugin_rc rc;
+	for (uint64_t i = 0; i < n; i++)
+	{
+		rc = harness_extract(owner, s, &evts[i % nevts], num_fields, fields);
+		if (rc != SS_PLUGIN_SUCCESS)
+		{
+			return rc;
+		}
+	}
+	return SS_PLUGIN_SUCCESS;
+}
+
+// Calls plugin_next_batch until at least n events are returned, and adds
+// their number to count. Stops and returns SS_PLUGIN_TIMEOUT if no events
+// are returned max_idle consecutive times, or the code of the last call if
+// it is neither a success nor a timeout.
+static ss_plugin_rc harness_next_batch_loop(uintptr_t s, uintptr_t h, uint64_t n, uint64_t max_idle, uint64_t *count)
+{
+	ss_plugin_rc rc;
+	uint32_t nevts;
+	ss_plugin_event **evts;
+	uint64_t idle = 0;
+	while (*count < n)
+	{
+		nevts = 0;
+		rc = plugin_next_batch(s, h, &nevts, &evts);
+		*count += nevts;
+		if (rc != SS_PLUGIN_SUCCESS && rc != SS_PLUGIN_TIMEOUT)
+		{
+			return rc;
+		}
+		idle = nevts == 0 ? idle + 1 : 0;
+		if (idle >= max_idle)
+		{
+			return SS_PLUGIN_TIMEOUT;
+		}
+	}
+	return SS_PLUGIN_SUCCESS;
+}
+*/
+import "C"
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+	"unsafe"
+
+	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk"
+	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk/sdktest"
+	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk/sdktest/internal/values"
+	_ "github.com/falcosecurity/plugin-sdk-go/pkg/sdk/symbols/evtstr"
+	_ "github.com/falcosecurity/plugin-sdk-go/pkg/sdk/symbols/extract"
+	_ "github.com/falcosecurity/plugin-sdk-go/pkg/sdk/symbols/fields"
+	_ "github.com/falcosecurity/plugin-sdk-go/pkg/sdk/symbols/info"
+	_ "github.com/falcosecurity/plugin-sdk-go/pkg/sdk/symbols/initialize"
+	_ "github.com/falcosecurity/plugin-sdk-go/pkg/sdk/symbols/lasterr"
+	_ "github.com/falcosecurity/plugin-sdk-go/pkg/sdk/symbols/nextbatch"
+	_ "github.com/falcosecurity/plugin-sdk-go/pkg/sdk/symbols/open"
+)
+
+// errConcurrentUse is returned when a plugin state or a capture instance
+// is used concurrently
+var errConcurrentUse = errors.New("harness: the harness must not be used concurrently with the same plugin state or capture instance")
+
+// errClosed is returned when a closed capture instance is used
+var errClosed = errors.New("harness: NextBatch called on a closed instance")
+
+// Harness drives a plugin through the C symbols exported by the packages
+// of sdk/symbols, as a plugin framework would do after loading the plugin
+// as a shared library, but within the process of a regular `go test`.
+// The plugin must be registered with plugins.SetFactory, which is usually
+// done in the init function of the plugin main package.
+//
+// The harness plays the role of the owner of the plugin: it passes real C
+// structures to the plugin symbols, and follows the threading rules of the
+// plugin API. The messages sent by the plugin to the logger of its owner
+// are recorded, and returned by Logs. Each Harness is a distinct plugin
+// state, and methods of a
+// Harness or Instance must not be invoked concurrently, in the same way
+// as the framework never invokes plugin functions concurrently with the
+// same parameter values. Concurrent calls are reported as test errors, and
+// fail with an error. Distinct plugin states and capture instances can be
+// used concurrently.
+//
+// Whenever the plugin does not respect the contract of the plugin API,
+// such as by returning malformed events or results, or by failing without
+// setting an error, the violation is reported as a test error.
+//
+// Note that the async extraction optimization synchronizes with the
+// plugin through C atomic operations, which are not visible to the Go race
+// detector. As such, false data races might be reported when running with
+// -race on multiple CPUs, unless the optimization is disabled with the
+// SetAsync function of the sdk/symbols/extract package.
+type Harness struct {
+	t       testing.TB
+	owner   C.uintptr_t
+	state   C.uintptr_t
+	fields  []sdk.FieldEntry
+	id      uint32
+	source  *C.char
+	busy    int32
+	evts    sdk.EventWriters
+	evtsCap int
+	logsMu  sync.Mutex
+	logs    []Log
+}
+
+// New initializes a new plugin state through plugin_init with the
+// given init config, and fails the test if the initialization fails. The
+// plugin state is destroyed through plugin_destroy at the end of the test.
+func New(t testing.TB, config string) *Harness {
+	t.Helper()
+	cConfig := C.CString(config)
+	defer C.free(unsafe.Pointer(cConfig))
+
+	h := &Harness{t: t}
+	rc := C.ss_plugin_rc(C.SS_PLUGIN_FAILURE)
+	h.owner = newOwner(h)
+	h.state = C.harness_init(h.owner, cConfig, &rc)
+	if h.state == 0 {
+		deleteOwner(h.owner)
+		h.violation("plugin_init returned a NULL state")
+		t.FailNow()
+	}
+	if rc != C.SS_PLUGIN_SUCCESS {
+		err := h.failure("plugin_init", rc)
+		C.plugin_destroy(h.state)
+		deleteOwner(h.owner)
+		t.Fatalf("plugin_init failed: %s", err)
+	}
+	t.Cleanup(h.destroy)
+
+	if str := C.plugin_get_fields(); str != nil {
+		if err := json.Unmarshal([]byte(C.GoString(str)), &h.fields); err != nil {
+			h.violation("plugin_get_fields returned malformed JSON: %s", err.Error())
+		}
+	}
+	h.id = uint32(C.plugin_get_id())
+	h.source = C.plugin_get_event_source()
+	return h
+}
+
+func (h *Harness) destroy() {
+	if h.evts != nil {
+		h.evts.Free()
+	}
+	C.plugin_destroy(h.state)
+	deleteOwner(h.owner)
+}
+
+// violation reports a violation of the plugin API contract
+func (h *Harness) violation(format string, args ...interface{}) {
+	h.t.Helper()
+	h.t.Errorf("plugin API contract violation: "+format, args...)
+}
+
+// enter marks the plugin state as in use, and reports a test error and
+// returns errConcurrentUse if it is already in use by a concurrent call.
+// The test is not stopped, as the call can come from any goroutine.
+func (h *Harness) enter(busy *int32) error {
+	if !atomic.CompareAndSwapInt32(busy, 0, 1) {
+		h.t.Errorf("%s", errConcurrentUse.Error())
+		return errConcurrentUse
+	}
+	return nil
+}
+
+func (h *Harness) leave(busy *int32) {
+	atomic.StoreInt32(busy, 0)
+}
+
+// failure returns the last error of the plugin after a call of fn that
+// returned rc, and reports a violation if no error is set on a failure.
+func (h *Harness) failure(fn string, rc C.ss_plugin_rc) error {
+	h.t.Helper()
+	msg := C.GoString(C.plugin_get_last_error(h.state))
+	if rc == C.SS_PLUGIN_FAILURE && len(msg) == 0 {
+		h.violation("%s failed without setting an error", fn)
+	}
+	return errors.New(msg)
+}
+
+// LastError returns the last error of the plugin, as of
+// plugin_get_last_error.
+func (h *Harness) LastError() string {
+	return C.GoString(C.plugin_get_last_error(h.state))
+}
+
+// Fields returns the fields of the plugin, as of plugin_get_fields.
+func (h *Harness) Fields() []sdk.FieldEntry {
+	return h.fields
+}
+
+// Instance is a capture instance opened with the Open method of
+// Harness.
+type Instance struct {
+	h      *Harness
+	inst   C.uintptr_t
+	busy   int32
+	closed bool
+	evtNum uint64
+}
+
+// Open opens a new capture instance through plugin_open with the given
+// params, and returns an error if the plugin fails to open it. The plugin
+// must have the event sourcing capability registered with source.Register.
+// The instance is closed through plugin_close at the end of the test, if
+// not closed before.
+func (h *Harness) Open(params string) (*Instance, error) {
+	h.t.Helper()
+	cParams := C.CString(params)
+	defer C.free(unsafe.Pointer(cParams))
+
+	if err := h.enter(&h.busy); err != nil {
+		return nil, err
+	}
+	defer h.leave(&h.busy)
+	rc := C.ss_plugin_rc(C.SS_PLUGIN_FAILURE)
+	inst := C.plugin_open(h.state, cParams, &rc)
+	if rc != C.SS_PLUGIN_SUCCESS {
+		return nil, h.failure("plugin_open", rc)
+	}
+	if inst == 0 {
+		h.violation("plugin_open returned a NULL instance")
+		return nil, errors.New("plugin_open returned a NULL instance")
+	}
+	i := &Instance{h: h, inst: inst}
+	h.t.Cleanup(i.Close)
+	return i, nil
+}
+
+// Close closes the capture instance through plugin_close. Calling Close
+// more than once has no effect.
+func (i *Instance) Close() {
+	if !i.closed {
+		i.closed = true
+		C.plugin_close(i.h.state, i.inst)
+	}
+}
+
+// NextBatch returns the next batch of events through plugin_next_batch.
+// The returned error is sdk.ErrTimeout or sdk.ErrEOF if the plugin reports
+// a timeout or the end of the capture, in which case events can still be
+// returned, or an error with the last error of the plugin on failure.
+// The returned events are copied in Go memory, and are numbered
+// sequentially starting from 1.
+func (i *Instance) NextBatch() ([]*sdktest.InMemoryEventReader, error) {
+	i.h.t.Helper()
+	if i.closed {
+		i.h.t.Errorf("%s", errClosed.Error())
+		return nil, errClosed
+	}
+	if err := i.h.enter(&i.busy); err != nil {
+		return nil, err
+	}
+	defer i.h.leave(&i.busy)
+
+	var nevts C.uint32_t
+	var evts **C.ss_plugin_event
+	rc := C.plugin_next_batch(i.h.state, i.inst, &nevts, &evts)
+	var err error
+	switch rc {
+	case C.SS_PLUGIN_SUCCESS:
+	case C.SS_PLUGIN_TIMEOUT:
+		err = sdk.ErrTimeout
+	case C.SS_PLUGIN_EOF:
+		err = sdk.ErrEOF
+	case C.SS_PLUGIN_FAILURE:
+		return nil, i.h.failure("plugin_next_batch", rc)
+	default:
+		i.h.violation("plugin_next_batch returned an unexpected code %d", int(rc))
+		return nil, fmt.Errorf("unexpected return code %d", int(rc))
+	}
+	if nevts > 0 && evts == nil {
+		i.h.violation("plugin_next_batch returned %d events with a NULL array", int(nevts))
+		return nil, err
+	}
+
+	var res []*sdktest.InMemoryEventReader
+	for n, evt := range unsafe.Slice(evts, int(nevts)) {
+		data, verr := sdk.ReadPluginEvent(unsafe.Pointer(evt), i.h.id)
+		if verr != nil {
+			i.h.violation("plugin_next_batch returned a malformed event at index %d: %s", n, verr.Error())
+			continue
+		}
+		i.evtNum++
+		res = append(res, &sdktest.InMemoryEventReader{
+			Buffer:       data,
+			ValEventNum:  i.evtNum,
+			ValTimestamp: uint64(evt.ts),
+		})
+	}
+	return res, err
+}
+
+// nextBatchLoop reads at least n events through harness_next_batch_loop,
+// without copying them, and returns the number of events read. The returned
+// error is sdk.ErrEOF if the capture ends before, or sdk.ErrTimeout if the
+// plugin returns no events maxIdle consecutive times.
+func (i *Instance) nextBatchLoop(n, maxIdle uint64) (uint64, error) {
+	i.h.t.Helper()
+	if err := i.h.enter(&i.busy); err != nil {
+		return 0, err
+	}
+	defer i.h.leave(&i.busy)
+
+	var count C.uint64_t
+	rc := C.harness_next_batch_loop(i.h.state, i.inst, C.uint64_t(n), C.uint64_t(maxIdle), &count)
+	switch rc {
+	case C.SS_PLUGIN_SUCCESS:
+		return uint64(count), nil
+	case C.SS_PLUGIN_TIMEOUT:
+		return uint64(count), sdk.ErrTimeout
+	case C.SS_PLUGIN_EOF:
+		return uint64(count), sdk.ErrEOF
+	case C.SS_PLUGIN_FAILURE:
+		return uint64(count), i.h.failure("plugin_next_batch", rc)
+	default:
+		i.h.violation("plugin_next_batch returned an unexpected code %d", int(rc))
+		return uint64(count), fmt.Errorf("unexpected return code %d", int(rc))
+	}
+}
+
+// eventInput writes evt as a plugin event in C memory, and returns
+// an ss_plugin_event_input for it
+func (h *Harness) eventInput(evt sdk.EventReader) (*C.ss_plugin_event_input, error) {
+	data := evt.Bytes()
+	if h.evts == nil || len(data) > h.evtsCap {
+		if h.evts != nil {
+			h.evts.Free()
+			h.evts = nil
+		}
+		h.evtsCap = len(data)
+		if h.evtsCap < int(sdk.DefaultEvtSize) {
+			h.evtsCap = int(sdk.DefaultEvtSize)
+		}
+		evts, err := sdk.NewEventWriters(1, int64(h.evtsCap))
+		if err != nil {
+			return nil, err
+		}
+		h.evts = evts
+	}
+	w := h.evts.Get(0)
+	if _, err := w.Writer().Write(data); err != nil {
+		return nil, err
+	}
+	w.SetTimestamp(evt.Timestamp())
+	in := (*C.ss_plugin_event_input)(C.calloc(1, C.sizeof_ss_plugin_event_input))
+	in.evt = *(**C.ss_plugin_event)(h.evts.ArrayPtr())
+	in.evtnum = C.uint64_t(evt.EventNum())
+	in.evtsrc = h.source
+	return in, nil
+}
+
+// String returns the string representation of the given event, as of
+// plugin_event_to_string.
+func (h *Harness) String(evt sdk.EventReader) (string, error) {
+	h.t.Helper()
+	if err := h.enter(&h.busy); err != nil {
+		return "", err
+	}
+	defer h.leave(&h.busy)
+	in, err := h.eventInput(evt)
+	if err != nil {
+		return "", err
+	}
+	defer C.free(unsafe.Pointer(in))
+	str := C.plugin_event_to_string(h.state, in)
+	if str == nil {
+		h.violation("plugin_event_to_string returned NULL")
+		return "", errors.New("plugin_event_to_string returned NULL")
+	}
+	return C.GoString(str), nil
+}
+
+// Extract extracts the given fields from evt in a single call of
+// plugin_extract_fields, and returns their values in the same order.
+// Fields are expressed as in sdktest.ExtractField, and values are returned in
+// the same canonical form, or nil if not extracted. An error is returned
+// if the plugin fails, with the last error of the plugin.
+func (h *Harness) Extract(evt sdk.EventReader, fields ...string) ([]interface{}, error) {
+	h.t.Helper()
+	if err := h.enter(&h.busy); err != nil {
+		return nil, err
+	}
+	defer h.leave(&h.busy)
+
+	in, err := h.eventInput(evt)
+	if err != nil {
+		return nil, err
+	}
+	defer C.free(unsafe.Pointer(in))
+	reqs, free, err := h.extractRequests(fields)
+	defer free()
+	if err != nil {
+		return nil, err
+	}
+	input := make([]C.ss_plugin_extract_field, len(reqs))
+	copy(input, reqs)
+
+	rc := C.harness_extract(h.owner, h.state, in, C.uint32_t(len(reqs)), &reqs[0])
+	for i := range reqs {
+		r, o := &reqs[i], &input[i]
+		if r.field_id != o.field_id || r.field != o.field || r.arg_key != o.arg_key || r.arg_index != o.arg_index ||
+			r.arg_present != o.arg_present || r.ftype != o.ftype || r.flist != o.flist {
+			h.violation("plugin_extract_fields modified the input of field '%s'", fields[i])
+		}
+	}
+	switch rc {
+	case C.SS_PLUGIN_SUCCESS:
+	case C.SS_PLUGIN_FAILURE:
+		return nil, h.failure("plugin_extract_fields", rc)
+	default:
+		h.violation("plugin_extract_fields returned an unexpected code %d", int(rc))
+		return nil, fmt.Errorf("unexpected return code %d", int(rc))
+	}
+
+	res := make([]interface{}, len(reqs))
+	for i := range reqs {
+		v, verr := readResult(&reqs[i])
+		if verr != nil {
+			h.violation("plugin_extract_fields returned a malformed result for field '%s': %s", fields[i], verr.Error())
+		}
+		res[i] = v
+	}
+	return res, nil
+}
+
+// extractRequests returns the C structures requesting the given fields,
+// and a function to free the C memory they use
+func (h *Harness) extractRequests(fields []string) ([]C.ss_plugin_extract_field, func(), error) {
+	var cStrs []*C.char
+	var reqs []C.ss_plugin_extract_field
+	free := func() {
+		for _, s := range cStrs {
+			C.free(unsafe.Pointer(s))
+		}
+		if len(reqs) > 0 {
+			C.free(unsafe.Pointer(&reqs[0]))
+		}
+	}
+	if len(fields) == 0 {
+		return nil, free, errors.New("no field requested")
+	}
+	reqs = unsafe.Slice((*C.ss_plugin_extract_field)(C.calloc(C.size_t(len(fields)), C.sizeof_ss_plugin_extract_field)), len(fields))
+	for i, field := range fields {
+		f, err := sdktest.ParseField(h.fields, field)
+		if err != nil {
+			return nil, free, err
+		}
+		ftype, err := values.FieldType(&h.fields[f.Index])
+		if err != nil {
+			return nil, free, err
+		}
+		cStrs = append(cStrs, C.CString(f.Name))
+		reqs[i].field_id = C.uint32_t(f.Index)
+		reqs[i].field = cStrs[len(cStrs)-1]
+		reqs[i].ftype = C.uint32_t(ftype)
+		if h.fields[f.Index].IsList {
+			reqs[i].flist = 1
+		}
+		if f.ArgPresent {
+			reqs[i].arg_present = 1
+			reqs[i].arg_index = C.uint64_t(f.ArgIndex)
+			if len(f.ArgKey) > 0 {
+				cStrs = append(cStrs, C.CString(f.ArgKey))
+				reqs[i].arg_key = cStrs[len(cStrs)-1]
+			}
+		}
+	}
+	return reqs, free, nil
+}
+
+// extractLoop returns a function that extracts the given fields n times,
+// from each of the given events in turn, through harness_extract_loop,
+// and a function to free the C memory they use. If no field is given, the
+// plugin is invoked with no fields to extract.
+func (h *Harness) extractLoop(evts []sdk.EventReader, fields []string) (func(n int) error, func(), error) {
+	if len(evts) == 0 {
+		return nil, func() {}, errors.New("no event given")
+	}
+	var reqs []C.ss_plugin_extract_field
+	var cReqs *C.ss_plugin_extract_field
+	var freeReqs func()
+	if len(fields) > 0 {
+		var err error
+		reqs, freeReqs, err = h.extractRequests(fields)
+		if err != nil {
+			freeReqs()
+			return nil, func() {}, err
+		}
+		cReqs = &reqs[0]
+	} else {
+		// the SDK expects a non-NULL array even with no fields
+		cReqs = (*C.ss_plugin_extract_field)(C.calloc(1, C.sizeof_ss_plugin_extract_field))
+		freeReqs = func() { C.free(unsafe.Pointer(cReqs)) }
+	}
+
+	// write all the events in C memory
+	maxDataSize := int(sdk.DefaultEvtSize)
+	for _, evt := range evts {
+		if len(evt.Bytes()) > maxDataSize {
+			maxDataSize = len(evt.Bytes())
+		}
+	}
+	writers, err := sdk.NewEventWriters(int64(len(evts)), int64(maxDataSize))
+	if err != nil {
+		freeReqs()
+		return nil, func() {}, err
+	}
+	ins := unsafe.Slice((*C.ss_plugin_event_input)(C.calloc(C.size_t(len(evts)), C.sizeof_ss_plugin_event_input)), len(evts))
+	free := func() {
+		C.free(unsafe.Pointer(&ins[0]))
+		writers.Free()
+		freeReqs()
+	}
+	evtPtrs := unsafe.Slice((**C.ss_plugin_event)(writers.ArrayPtr()), len(evts))
+	for i, evt := range evts {
+		w := writers.Get(i)
+		if _, err := w.Writer().Write(evt.Bytes()); err != nil {
+			free()
+			return nil, func() {}, err
+		}
+		w.SetTimestamp(evt.Timestamp())
+		ins[i] = C.ss_plugin_event_input{evt: evtPtrs[i], evtnum: C.uint64_t(evt.EventNum()), evtsrc: h.source}
+	}
+
+	run := func(n int) error {
+		if err := h.enter(&h.busy); err != nil {
+			return err
+		}
+		defer h.leave(&h.busy)
+		rc := C.harness_extract_loop(h.owner, h.state, &ins[0], C.uint32_t(len(ins)), C.uint32_t(len(reqs)), cReqs, C.uint64_t(n))
+		if rc != C.SS_PLUGIN_SUCCESS {
+			return h.failure("plugin_extract_fields", rc)
+		}
+		return nil
+	}
+	return run, free, nil
+}
+
+// readResult copies the result of the given request in Go memory, in the
+// canonical form of sdktest.ExtractField, and checks that it is well-formed
+func readResult(req *C.ss_plugin_extract_field) (interface{}, error) {
+	n := int(req.res_len)
+	if n == 0 {
+		return nil, nil
+	}
+	if req.flist == 0 && n != 1 {
+		return nil, fmt.Errorf("non-list field has %d values", n)
+	}
+	res := *(*unsafe.Pointer)(unsafe.Pointer(&req.res))
+	if res == nil {
+		return nil, fmt.Errorf("%d values are set with a NULL pointer", n)
+	}
+	ftype := uint32(req.ftype)
+	list := reflect.MakeSlice(reflect.SliceOf(values.CanonicalTypes[ftype]), n, n)
+	for i := 0; i < n; i++ {
+		var v interface{}
+		switch ftype {
+		case sdk.FieldTypeUint64:
+			v = *(*uint64)(unsafe.Add(res, i*8))
+		case sdk.FieldTypeRelTime:
+			v = time.Duration(*(*uint64)(unsafe.Add(res, i*8)))
+		case sdk.FieldTypeAbsTime:
+			v = time.Unix(0, int64(*(*uint64)(unsafe.Add(res, i*8)))).UTC()
+		case sdk.FieldTypeBool:
+			v = *(*uint32)(unsafe.Add(res, i*4)) != 0
+		case sdk.FieldTypeCharBuf:
+			str := *(**C.char)(unsafe.Add(res, i*C.sizeof_uintptr_t))
+			if str == nil {
+				return nil, fmt.Errorf("string value %d is NULL", i)
+			}
+			v = C.GoString(str)
+		case sdk.FieldTypeIPAddr, sdk.FieldTypeIPNet:
+			buf := (*C.ss_plugin_byte_buffer)(unsafe.Add(res, i*C.sizeof_ss_plugin_byte_buffer))
+			if buf.len != net.IPv4len && buf.len != net.IPv6len {
+				return nil, fmt.Errorf("IP value %d has length %d", i, int(buf.len))
+			}
+			if buf.ptr == nil {
+				return nil, fmt.Errorf("IP value %d is NULL", i)
+			}
+			ip := values.CanonicalIP(net.IP(C.GoBytes(buf.ptr, C.int(buf.len))))
+			if ftype == sdk.FieldTypeIPNet {
+				v = net.IPNet{IP: ip}
+			} else {
+				v = ip
+			}
+		}
+		list.Index(i).Set(reflect.ValueOf(v))
+	}
+	if req.flist == 0 {
+		return list.Index(0).Interface(), nil
+	}
+	return list.Interface(), nil
+}
+
+// AssertField extracts the given field from evt with Extract, and fails
+// the test if the extraction fails, or if the extracted value is not equal
+// to expected, as in sdktest.AssertField.
+func (h *Harness) AssertField(evt sdk.EventReader, field string, expected interface{}) {
+	h.t.Helper()
+	vals, err := h.Extract(evt, field)
+	if err != nil {
+		h.t.Fatalf("can't extract field '%s': %s", field, err.Error())
+	}
+	if f, err := sdktest.ParseField(h.fields, field); err == nil && expected != nil {
+		ftype, _ := values.FieldType(&h.fields[f.Index])
+		if err := values.Check(ftype, h.fields[f.Index].IsList, expected); err != nil {
+			h.t.Fatalf("invalid expected value for field '%s': %s", field, err.Error())
+		}
+		expected = values.Canonical(ftype, h.fields[f.Index].IsList, expected)
+	}
+	if !reflect.DeepEqual(vals[0], expected) {
+		h.t.Fatalf("field '%s': expected %v (%T), but extracted %v (%T)", field, expected, expected, vals[0], vals[0])
+	}
+}
+
+// ExtractConcurrently checks that the plugin supports concurrent field
+// extraction, as allowed by the plugin API for distinct plugin states.
+// The given fields are first extracted from all the given events with a
+// reference plugin state. Then, the same extractions are performed
+// concurrently by the given number of plugin states, each initialized
+// with config and used by its own goroutine. Differences from the
+// reference results are reported as errors. This also exercises the async
+// extraction optimization, if enabled and supported.
+func ExtractConcurrently(t testing.TB, config string, workers int, evts []sdk.EventReader, fields ...string) {
+	t.Helper()
+	type result struct {
+		values []interface{}
+		err    error
+	}
+	extractAll := func(h *Harness) []result {
+		res := make([]result, len(evts))
+		for i, evt := range evts {
+			res[i].values, res[i].err = h.Extract(evt, fields...)
+		}
+		return res
+	}
+
+	expected := extractAll(New(t, config))
+	harnesses := make([]*Harness, workers)
+	for i := range harnesses {
+		harnesses[i] = New(t, config)
+	}
+	var wg sync.WaitGroup
+	for w, h := range harnesses {
+		wg.Add(1)
+		go func(w int, h *Harness) {
+			defer wg.Done()
+			for i, r := range extractAll(h) {
+				if (r.err == nil) != (expected[i].err == nil) || !reflect.DeepEqual(r.values, expected[i].values) {
+					t.Errorf("concurrent extraction from event %d in plugin state %d returned %v (error: %v), but expected %v (error: %v)",
+						i, w, r.values, r.err, expected[i].values, expected[i].err)
+					return
+				}
+			}
+		}(w, h)
+	}
+	wg.Wait()
+}
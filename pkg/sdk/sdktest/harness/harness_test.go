@@ -0,0 +1,267 @@
+// SPDX-License-Identifier: Apache-2.0
+/*
+Copyright (C) 2026 The Falco Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package harness
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk"
+	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk/plugins"
+	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk/plugins/extractor"
+	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk/plugins/source"
+	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk/sdktest"
+)
+
+const testHarnessNumEvents = 300
+
+// harnessPlugin produces events containing a little-endian uint64 counter
+type harnessPlugin struct {
+	plugins.BasePlugin
+	emptyErr bool
+}
+
+func (h *harnessPlugin) Info() *plugins.Info {
+	return &plugins.Info{
+		ID:          999,
+		Name:        "test",
+		EventSource: "test",
+	}
+}
+
+func (h *harnessPlugin) Init(config string) error {
+	if config == "fail" {
+		return errors.New("init failure")
+	}
+	return nil
+}
+
+func (h *harnessPlugin) Fields() []sdk.FieldEntry {
+	return []sdk.FieldEntry{
+		{Type: "uint64", Name: "test.num"},
+		{Type: "string", Name: "test.str"},
+		{Type: "ipaddr", Name: "test.ips", IsList: true},
+		{Type: "uint64", Name: "test.fail"},
+		{Type: "uint64", Name: "test.old", Deprecated: "use test.num instead"},
+	}
+}
+
+func (h *harnessPlugin) Extract(req sdk.ExtractRequest, evt sdk.EventReader) error {
+	n := binary.LittleEndian.Uint64(evt.Bytes())
+	switch req.FieldID() {
+	case 0, 4:
+		req.SetValue(n)
+	case 1:
+		req.SetValue(fmt.Sprintf("evt%d", n))
+	case 2:
+		req.SetValue([]net.IP{net.IPv4(10, 0, 0, byte(n)).To4(), net.IPv6loopback})
+	case 3:
+		if h.emptyErr {
+			return errors.New("")
+		}
+		return errors.New("extraction failure")
+	}
+	return nil
+}
+
+func (h *harnessPlugin) Open(params string) (source.Instance, error) {
+	n := uint64(0)
+	return source.NewPullInstance(func(ctx context.Context, evt sdk.EventWriter) error {
+		if n == testHarnessNumEvents {
+			return sdk.ErrEOF
+		}
+		n++
+		evt.SetTimestamp(n * 10)
+		return binary.Write(evt.Writer(), binary.LittleEndian, n)
+	})
+}
+
+func (h *harnessPlugin) String(evt sdk.EventReader) (string, error) {
+	return fmt.Sprintf("evt%d", binary.LittleEndian.Uint64(evt.Bytes())), nil
+}
+
+func setHarnessFactory(emptyErr bool) {
+	plugins.SetFactory(func() plugins.Plugin {
+		p := &harnessPlugin{emptyErr: emptyErr}
+		source.Register(p)
+		extractor.Register(p)
+		return p
+	})
+}
+
+// fakeT records the failure of the test without failing the real test
+type fakeT struct {
+	testing.TB
+	msg string
+}
+
+func (f *fakeT) Helper() {}
+
+func (f *fakeT) Errorf(format string, args ...interface{}) {
+	f.msg = fmt.Sprintf(format, args...)
+}
+
+func (f *fakeT) Fatalf(format string, args ...interface{}) {
+	f.msg = fmt.Sprintf(format, args...)
+	runtime.Goexit()
+}
+
+func assertFails(t *testing.T, contains string, f func(testing.TB)) {
+	ft := &fakeT{TB: t}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		f(ft)
+	}()
+	<-done
+	if !strings.Contains(ft.msg, contains) {
+		t.Fatalf("expected failure containing '%s', but found '%s'", contains, ft.msg)
+	}
+}
+
+func TestHarness(t *testing.T) {
+	setHarnessFactory(false)
+	h := New(t, "")
+	if len(h.Fields()) != 5 {
+		t.Fatalf("expected %d fields, but found %d", 5, len(h.Fields()))
+	}
+
+	// read all the events
+	inst, err := h.Open("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var evts []*sdktest.InMemoryEventReader
+	for !errors.Is(err, sdk.ErrEOF) {
+		var batch []*sdktest.InMemoryEventReader
+		batch, err = inst.NextBatch()
+		if err != nil && !errors.Is(err, sdk.ErrEOF) && !errors.Is(err, sdk.ErrTimeout) {
+			t.Fatal(err)
+		}
+		evts = append(evts, batch...)
+	}
+	inst.Close()
+	if len(evts) != testHarnessNumEvents {
+		t.Fatalf("expected %d events, but found %d", testHarnessNumEvents, len(evts))
+	}
+
+	// extract fields from them
+	for i, evt := range evts {
+		n := uint64(i + 1)
+		if evt.EventNum() != n || evt.Timestamp() != n*10 {
+			t.Fatalf("unexpected event number %d and timestamp %d", evt.EventNum(), evt.Timestamp())
+		}
+		vals, err := h.Extract(evt, "test.num", "test.str")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if vals[0] != n || vals[1] != fmt.Sprintf("evt%d", n) {
+			t.Fatalf("unexpected values: %v", vals)
+		}
+		h.AssertField(evt, "test.ips", []net.IP{net.IPv4(10, 0, 0, byte(n)), net.IPv6loopback})
+		if s, err := h.String(evt); err != nil || s != fmt.Sprintf("evt%d", n) {
+			t.Fatalf("unexpected string: %s, %v", s, err)
+		}
+	}
+
+	// failures
+	if _, err := h.Extract(evts[0], "test.fail"); err == nil || err.Error() != "extraction failure" {
+		t.Fatalf("expected extraction failure, but found: %v", err)
+	}
+	if _, err := h.Extract(evts[0], "test.unknown"); err == nil {
+		t.Fatalf("expected error for unknown field")
+	}
+	if h.LastError() != "extraction failure" {
+		t.Fatalf("unexpected last error: %s", h.LastError())
+	}
+}
+
+func TestHarnessLogs(t *testing.T) {
+	setHarnessFactory(false)
+	evt := &sdktest.InMemoryEventReader{Buffer: make([]byte, 8)}
+
+	// each plugin state warns once about the usage of a deprecated field,
+	// through the logger of its owner
+	for i := 0; i < 2; i++ {
+		h := New(t, "")
+		for j := 0; j < 3; j++ {
+			if _, err := h.Extract(evt, "test.num", "test.old"); err != nil {
+				t.Fatal(err)
+			}
+		}
+		logs := h.Logs()
+		if len(logs) != 1 {
+			t.Fatalf("expected %d log, but found %d", 1, len(logs))
+		}
+		if logs[0].Severity != 4 || !strings.Contains(logs[0].Message, "'test.old' is deprecated: use test.num instead") {
+			t.Errorf("unexpected log: %+v", logs[0])
+		}
+	}
+}
+
+func TestHarnessViolations(t *testing.T) {
+	setHarnessFactory(true)
+	assertFails(t, "plugin_init failed: init failure", func(t testing.TB) {
+		New(t, "fail")
+	})
+	assertFails(t, "plugin_extract_fields failed without setting an error", func(t testing.TB) {
+		h := New(t, "")
+		h.Extract(&sdktest.InMemoryEventReader{Buffer: make([]byte, 8)}, "test.fail")
+	})
+}
+
+func TestExtractConcurrently(t *testing.T) {
+	setHarnessFactory(false)
+	var evts []sdk.EventReader
+	for i := 0; i < 100; i++ {
+		evt := &sdktest.InMemoryEventReader{Buffer: make([]byte, 8), ValEventNum: uint64(i + 1)}
+		binary.LittleEndian.PutUint64(evt.Buffer, uint64(i))
+		evts = append(evts, evt)
+	}
+	ExtractConcurrently(t, "", 4, evts, "test.num", "test.str", "test.ips", "test.fail")
+}
+
+func TestHarnessConcurrentUse(t *testing.T) {
+	setHarnessFactory(false)
+	evt := &sdktest.InMemoryEventReader{Buffer: make([]byte, 8)}
+	assertFails(t, "must not be used concurrently", func(t testing.TB) {
+		h := New(t, "")
+		// simulates a call in progress in another goroutine
+		h.busy = 1
+		if _, err := h.Extract(evt, "test.num"); err != errConcurrentUse {
+			t.Fatalf("expected %v, but found %v", errConcurrentUse, err)
+		}
+	})
+	assertFails(t, "called on a closed instance", func(t testing.TB) {
+		h := New(t, "")
+		inst, err := h.Open("")
+		if err != nil {
+			t.Fatalf("%s", err.Error())
+		}
+		inst.Close()
+		if _, err := inst.NextBatch(); err != errClosed {
+			t.Fatalf("expected %v, but found %v", errClosed, err)
+		}
+	})
+}
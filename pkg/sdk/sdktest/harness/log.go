@@ -0,0 +1,83 @@
+// SPDX-License-Identifier: Apache-2.0
+/*
+Copyright (C) 2026 The Falco Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package harness
+
+/*
+#include <stdint.h>
+*/
+import "C"
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Log is a message sent by the plugin to the logger of its owner
+type Log struct {
+	// Component is the name of the component that sent the message, or
+	// an empty string if not set by the plugin
+	Component string
+	//
+	// Message is the content of the message
+	Message string
+	//
+	// Severity is the severity of the message, as of the values of
+	// ss_plugin_log_severity in plugin_types.h
+	Severity int
+}
+
+var (
+	// owners maps the ID of the owner simulated by each harness to it
+	owners    sync.Map
+	lastOwner uint64
+)
+
+// newOwner returns a new owner ID for h
+func newOwner(h *Harness) C.uintptr_t {
+	o := C.uintptr_t(atomic.AddUint64(&lastOwner, 1))
+	owners.Store(o, h)
+	return o
+}
+
+func deleteOwner(o C.uintptr_t) {
+	owners.Delete(o)
+}
+
+//export harnessLog
+func harnessLog(o C.uintptr_t, component *C.char, msg *C.char, sev C.int) {
+	v, ok := owners.Load(o)
+	if !ok {
+		return
+	}
+	h := v.(*Harness)
+	l := Log{Message: C.GoString(msg), Severity: int(sev)}
+	if component != nil {
+		l.Component = C.GoString(component)
+	}
+	h.logsMu.Lock()
+	defer h.logsMu.Unlock()
+	h.logs = append(h.logs, l)
+}
+
+// Logs returns the messages sent by the plugin to the logger of its owner
+// so far. This is safe to be invoked concurrently, as the plugin can send
+// messages from any thread.
+func (h *Harness) Logs() []Log {
+	h.logsMu.Lock()
+	defer h.logsMu.Unlock()
+	return append([]Log{}, h.logs...)
+}
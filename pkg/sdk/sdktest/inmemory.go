@@ -1,6 +1,6 @@
 // SPDX-License-Identifier: Apache-2.0
 /*
-Copyright (C) 2023 The Falco Authors.
+Copyright (C) 2026 The Falco Authors.
 
 Licensed under the Apache License, Version 2.0 (the "License");
 you may not use this file except in compliance with the License.
@@ -15,7 +15,7 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-package sdk
+package sdktest
 
 import (
 	"bytes"
@@ -25,18 +25,32 @@ import (
 	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk"
 )
 
+var (
+	_ sdk.StringBytesExtractRequest = &InMemoryExtractRequest{}
+	_ sdk.ExtractRequestPool        = &InMemoryExtractRequestPool{}
+	_ sdk.EventWriter               = &InMemoryEventWriter{}
+	_ sdk.EventWriters              = &InMemoryEventWriters{}
+	_ sdk.EventReader               = &InMemoryEventReader{}
+)
+
 // InMemoryExtractRequest is an in-memory implementation of
-// sdk.ExtractRequest that allows changing its internal values.
+// sdk.StringBytesExtractRequest that allows changing its internal values.
+// The values set by the plugin are recorded in ValValue, ValOffsetStart,
+// and ValOffsetLength, without being converted to their C representation.
 type InMemoryExtractRequest struct {
-	ValFieldID    uint64
-	ValFieldType  uint32
-	ValField      string
-	ValArgKey     string
-	ValArgIndex   uint64
-	ValArgPresent bool
-	ValIsList     bool
-	ValValue      interface{}
-	ValPtr        unsafe.Pointer
+	ValFieldID      uint64
+	ValFieldType    uint32
+	ValField        string
+	ValArgKey       string
+	ValArgIndex     uint64
+	ValArgPresent   bool
+	ValIsList       bool
+	ValValue        interface{}
+	ValPtr          unsafe.Pointer
+	ValEventPtr     unsafe.Pointer
+	ValWantOffset   bool
+	ValOffsetStart  uint32
+	ValOffsetLength uint32
 }
 
 func (i *InMemoryExtractRequest) FieldID() uint64 {
@@ -71,10 +85,37 @@ func (i *InMemoryExtractRequest) SetValue(v interface{}) {
 	i.ValValue = v
 }
 
+func (i *InMemoryExtractRequest) SetStringBytes(v []byte) {
+	i.ValValue = v
+}
+
+// SetValueOffset records the given offset only if WantOffset returns
+// true, as in the implementation of sdk.ExtractRequest of the SDK.
+func (i *InMemoryExtractRequest) SetValueOffset(start, length uint32) {
+	if i.ValWantOffset {
+		i.ValOffsetStart = start
+		i.ValOffsetLength = length
+	}
+}
+
 func (i *InMemoryExtractRequest) SetPtr(ptr unsafe.Pointer) {
 	i.ValPtr = ptr
 }
 
+func (i *InMemoryExtractRequest) SetEventPtr(ptr unsafe.Pointer) {
+	i.ValEventPtr = ptr
+}
+
+// SetOffsetPtrs makes WantOffset return true if both the pointers are
+// not nil. The pointers are not used, as offsets are recorded in-memory.
+func (i *InMemoryExtractRequest) SetOffsetPtrs(startPtr, lengthPtr unsafe.Pointer) {
+	i.ValWantOffset = startPtr != nil && lengthPtr != nil
+}
+
+func (i *InMemoryExtractRequest) WantOffset() bool {
+	return i.ValWantOffset
+}
+
 // InMemoryExtractRequestPool is an in-memory implementation of
 // sdk.ExtractRequestPool that allows changing its internal values.
 type InMemoryExtractRequestPool struct {
@@ -85,7 +126,11 @@ func (i *InMemoryExtractRequestPool) Get(requestIndex int) sdk.ExtractRequest {
 	return i.Requests[requestIndex]
 }
 
-func (i *InMemoryExtractRequest) Free() {
+func (i *InMemoryExtractRequestPool) MakeOffsetArrayPtrs(extractValueOffsets unsafe.Pointer, cap uint32) {
+	// do nothing
+}
+
+func (i *InMemoryExtractRequestPool) Free() {
 	// do nothing
 }
 
@@ -94,6 +139,7 @@ func (i *InMemoryExtractRequest) Free() {
 type InMemoryEventWriter struct {
 	Buffer       bytes.Buffer
 	ValTimestamp uint64
+	ValTID       uint64
 }
 
 func (i *InMemoryEventWriter) Writer() io.Writer {
@@ -105,6 +151,10 @@ func (i *InMemoryEventWriter) SetTimestamp(value uint64) {
 	i.ValTimestamp = value
 }
 
+func (i *InMemoryEventWriter) SetTID(value uint64) {
+	i.ValTID = value
+}
+
 // InMemoryEventWriters is an in-memory implementation of
 // sdk.EventWriters that allows changing its internal values.
 type InMemoryEventWriters struct {
@@ -113,6 +163,16 @@ type InMemoryEventWriters struct {
 	OnFree      func()
 }
 
+// NewInMemoryEventWriters returns a new InMemoryEventWriters containing
+// size instances of InMemoryEventWriter.
+func NewInMemoryEventWriters(size int) *InMemoryEventWriters {
+	res := &InMemoryEventWriters{}
+	for i := 0; i < size; i++ {
+		res.Writers = append(res.Writers, &InMemoryEventWriter{})
+	}
+	return res
+}
+
 func (i *InMemoryEventWriters) Get(eventIndex int) sdk.EventWriter {
 	return i.Writers[eventIndex]
 }
@@ -150,3 +210,7 @@ func (i *InMemoryEventReader) Timestamp() uint64 {
 func (i *InMemoryEventReader) Reader() io.ReadSeeker {
 	return bytes.NewReader(i.Buffer)
 }
+
+func (i *InMemoryEventReader) Bytes() []byte {
+	return i.Buffer
+}
@@ -0,0 +1,34 @@
+// SPDX-License-Identifier: Apache-2.0
+/*
+Copyright (C) 2026 The Falco Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package bench connects the benchmark entry points of the sdktest package
+// to their implementation in the sdktest/harness package, which can't be
+// imported by sdktest as it links the C symbols of the plugin.
+package bench
+
+import (
+	"testing"
+
+	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk"
+)
+
+// Extract and NextBatch are set to harness.BenchmarkExtract and
+// harness.BenchmarkNextBatch when the sdktest/harness package is imported
+var (
+	Extract   func(b *testing.B, config string, evts []sdk.EventReader, fields ...string)
+	NextBatch func(b *testing.B, config, params string)
+)
@@ -0,0 +1,213 @@
+// SPDX-License-Identifier: Apache-2.0
+/*
+Copyright (C) 2026 The Falco Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package values implements the validation and the canonical form of the
+// field values handled by sdktest and its subpackages.
+package values
+
+import (
+	"fmt"
+	"net"
+	"reflect"
+	"time"
+
+	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk"
+)
+
+// FieldType returns the type code of the given field
+func FieldType(f *sdk.FieldEntry) (uint32, error) {
+	t, ok := sdk.FieldTypeCode(f.Type)
+	if !ok {
+		return 0, fmt.Errorf("field '%s' has unsupported type '%s'", f.Name, f.Type)
+	}
+	return t, nil
+}
+
+// Check returns an error if v can't be set as the value of a field of
+// the given type with the SetValue method of sdk.ExtractRequest, or if
+// doing so would make it panic.
+func Check(ftype uint32, isList bool, v interface{}) error {
+	ok := false
+	nilPtr := false
+	switch ftype {
+	case sdk.FieldTypeBool:
+		if isList {
+			_, ok = v.([]bool)
+		} else {
+			_, ok = v.(bool)
+		}
+	case sdk.FieldTypeUint64:
+		if isList {
+			_, ok = v.([]uint64)
+		} else {
+			_, ok = v.(uint64)
+		}
+	case sdk.FieldTypeCharBuf:
+		if isList {
+			switch v.(type) {
+			case []string, [][]byte:
+				ok = true
+			}
+		} else {
+			switch v.(type) {
+			case string, []byte:
+				ok = true
+			}
+		}
+	case sdk.FieldTypeRelTime:
+		if isList {
+			switch val := v.(type) {
+			case []time.Duration:
+				ok = true
+			case []*time.Duration:
+				ok = true
+				for _, p := range val {
+					nilPtr = nilPtr || p == nil
+				}
+			}
+		} else {
+			switch val := v.(type) {
+			case time.Duration:
+				ok = true
+			case *time.Duration:
+				ok, nilPtr = true, val == nil
+			}
+		}
+	case sdk.FieldTypeAbsTime:
+		if isList {
+			switch val := v.(type) {
+			case []time.Time:
+				ok = true
+			case []*time.Time:
+				ok = true
+				for _, p := range val {
+					nilPtr = nilPtr || p == nil
+				}
+			}
+		} else {
+			switch val := v.(type) {
+			case time.Time:
+				ok = true
+			case *time.Time:
+				ok, nilPtr = true, val == nil
+			}
+		}
+	case sdk.FieldTypeIPAddr:
+		if isList {
+			switch val := v.(type) {
+			case []net.IP:
+				ok = true
+			case []*net.IP:
+				ok = true
+				for _, p := range val {
+					nilPtr = nilPtr || p == nil
+				}
+			}
+		} else {
+			switch val := v.(type) {
+			case net.IP:
+				ok = true
+			case *net.IP:
+				ok, nilPtr = true, val == nil
+			}
+		}
+	case sdk.FieldTypeIPNet:
+		if isList {
+			switch val := v.(type) {
+			case []net.IPNet:
+				ok = true
+			case []*net.IPNet:
+				ok = true
+				for _, p := range val {
+					nilPtr = nilPtr || p == nil
+				}
+			}
+		} else {
+			switch val := v.(type) {
+			case net.IPNet:
+				ok = true
+			case *net.IPNet:
+				ok, nilPtr = true, val == nil
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported field type %d", ftype)
+	}
+	if !ok {
+		return fmt.Errorf("value of type %T is not valid for a field of type %d (list: %v)", v, ftype, isList)
+	}
+	if nilPtr {
+		return fmt.Errorf("value of type %T contains a nil pointer", v)
+	}
+	return nil
+}
+
+// CanonicalTypes maps each field type to the Go type of its values in
+// the canonical form of sdktest.ExtractField
+var CanonicalTypes = map[uint32]reflect.Type{
+	sdk.FieldTypeBool:    reflect.TypeOf(false),
+	sdk.FieldTypeUint64:  reflect.TypeOf(uint64(0)),
+	sdk.FieldTypeCharBuf: reflect.TypeOf(""),
+	sdk.FieldTypeRelTime: reflect.TypeOf(time.Duration(0)),
+	sdk.FieldTypeAbsTime: reflect.TypeOf(time.Time{}),
+	sdk.FieldTypeIPAddr:  reflect.TypeOf(net.IP{}),
+	sdk.FieldTypeIPNet:   reflect.TypeOf(net.IPNet{}),
+}
+
+// Canonical converts v, which must be a valid value for the given
+// field type as of Check, in the canonical form of sdktest.ExtractField
+func Canonical(ftype uint32, isList bool, v interface{}) interface{} {
+	if v == nil || !isList {
+		return canonicalScalar(v)
+	}
+	list := reflect.ValueOf(v)
+	res := reflect.MakeSlice(reflect.SliceOf(CanonicalTypes[ftype]), 0, list.Len())
+	for i := 0; i < list.Len(); i++ {
+		res = reflect.Append(res, reflect.ValueOf(canonicalScalar(list.Index(i).Interface())))
+	}
+	return res.Interface()
+}
+
+func canonicalScalar(v interface{}) interface{} {
+	switch val := v.(type) {
+	case []byte:
+		return string(val)
+	case *time.Duration:
+		return *val
+	case time.Time:
+		return time.Unix(0, val.UnixNano()).UTC()
+	case *time.Time:
+		return time.Unix(0, val.UnixNano()).UTC()
+	case net.IP:
+		return CanonicalIP(val)
+	case *net.IP:
+		return CanonicalIP(*val)
+	case net.IPNet:
+		return net.IPNet{IP: CanonicalIP(val.IP)}
+	case *net.IPNet:
+		return net.IPNet{IP: CanonicalIP(val.IP)}
+	}
+	return v
+}
+
+// CanonicalIP returns ip in its 16-byte form, or a copy of it if invalid
+func CanonicalIP(ip net.IP) net.IP {
+	if ip16 := ip.To16(); ip16 != nil {
+		return ip16
+	}
+	return append(net.IP{}, ip...)
+}
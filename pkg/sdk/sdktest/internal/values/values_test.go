@@ -0,0 +1,74 @@
+// SPDX-License-Identifier: Apache-2.0
+/*
+Copyright (C) 2026 The Falco Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package values
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk"
+)
+
+func TestCheckValue(t *testing.T) {
+	ip := net.ParseIP("10.0.0.1")
+	valid := []struct {
+		ftype  uint32
+		isList bool
+		value  interface{}
+	}{
+		{sdk.FieldTypeBool, false, true},
+		{sdk.FieldTypeBool, true, []bool{true}},
+		{sdk.FieldTypeUint64, false, uint64(1)},
+		{sdk.FieldTypeUint64, true, []uint64{1}},
+		{sdk.FieldTypeCharBuf, false, "a"},
+		{sdk.FieldTypeCharBuf, false, []byte("a")},
+		{sdk.FieldTypeCharBuf, true, []string{"a"}},
+		{sdk.FieldTypeCharBuf, true, [][]byte{[]byte("a")}},
+		{sdk.FieldTypeRelTime, false, time.Second},
+		{sdk.FieldTypeAbsTime, true, []time.Time{time.Now()}},
+		{sdk.FieldTypeIPAddr, false, &ip},
+		{sdk.FieldTypeIPNet, true, []net.IPNet{{IP: ip}}},
+	}
+	for _, v := range valid {
+		if err := Check(v.ftype, v.isList, v.value); err != nil {
+			t.Fatalf("unexpected error for %T: %s", v.value, err.Error())
+		}
+	}
+
+	invalid := []struct {
+		ftype  uint32
+		isList bool
+		value  interface{}
+	}{
+		{sdk.FieldTypeBool, false, uint64(1)},
+		{sdk.FieldTypeUint64, false, 1},
+		{sdk.FieldTypeUint64, true, uint64(1)},
+		{sdk.FieldTypeCharBuf, true, []byte("a")},
+		{sdk.FieldTypeRelTime, false, (*time.Duration)(nil)},
+		{sdk.FieldTypeAbsTime, true, []*time.Time{nil}},
+		{sdk.FieldTypeIPAddr, false, "10.0.0.1"},
+		{sdk.FieldTypeIPNet, false, ip},
+		{0, false, uint64(1)},
+	}
+	for _, v := range invalid {
+		if err := Check(v.ftype, v.isList, v.value); err == nil {
+			t.Fatalf("expected error for %T with type %d (list: %v)", v.value, v.ftype, v.isList)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+// SPDX-License-Identifier: Apache-2.0
+/*
+Copyright (C) 2026 The Falco Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package sdktest provides utilities for testing plugins built with this
+// SDK, without building them as shared libraries. This includes in-memory
+// implementations of the interfaces of the sdk package, helpers for
+// extracting fields and asserting their values, a helper for fuzzing
+// the code of plugins that decodes untrusted event data.
+//
+// Regressions can be tested with golden files, which record event streams
+// and the values of the fields extracted from them. The golden files are
+// regenerated by running the tests with the -sdktest.update flag.
+//
+// The sdktest/harness package drives plugins through the C symbols they
+// export. Once it is imported, the performance of field extraction and
+// event production through them can be measured with BenchmarkExtract and
+// BenchmarkNextBatch.
+package sdktest
+
+import "github.com/falcosecurity/plugin-sdk-go/pkg/sdk"
+
+// Extractor is the interface that plugins with field extraction capability
+// must implement to be tested with this package. All the plugins built
+// with the sdk/plugins/extractor package implement it.
+type Extractor interface {
+	sdk.Extractor
+	//
+	// Fields return the list of extractor fields exported by this plugin.
+	Fields() []sdk.FieldEntry
+}
@@ -0,0 +1,184 @@
+// SPDX-License-Identifier: Apache-2.0
+/*
+Copyright (C) 2026 The Falco Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdktest
+
+import (
+	"encoding/binary"
+	"errors"
+	"math/rand"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk"
+)
+
+// samplePlugin decodes events made of a sequence of little-endian uint64
+type samplePlugin struct {
+	panicOnShort bool
+	badType      bool
+}
+
+func (s *samplePlugin) Fields() []sdk.FieldEntry {
+	return []sdk.FieldEntry{
+		{Type: "uint64", Name: "sample.count"},
+		{Type: "uint64", Name: "sample.value", Arg: sdk.FieldEntryArg{IsRequired: true, IsIndex: true, IndexRange: &sdk.FieldEntryArgRange{Min: 0, Max: 7}}},
+		{Type: "uint64", Name: "sample.values", IsList: true},
+		{Type: "string", Name: "sample.key", Arg: sdk.FieldEntryArg{IsKey: true, AllowedKeys: []string{"hex", "dec"}}},
+		{Type: "reltime", Name: "sample.duration"},
+		{Type: "ipaddr", Name: "sample.ip", Aliases: []string{"sample.addr"}},
+		{Type: "string", Name: "sample.hex", IsList: true},
+	}
+}
+
+func (s *samplePlugin) values(evt sdk.EventReader) []uint64 {
+	var res []uint64
+	b := evt.Bytes()
+	for len(b) >= 8 {
+		res = append(res, binary.LittleEndian.Uint64(b))
+		b = b[8:]
+	}
+	if s.panicOnShort && len(b) > 0 {
+		panic("short event")
+	}
+	return res
+}
+
+func (s *samplePlugin) Extract(req sdk.ExtractRequest, evt sdk.EventReader) error {
+	values := s.values(evt)
+	switch req.FieldID() {
+	case 0:
+		req.SetValue(uint64(len(values)))
+	case 1:
+		if req.ArgIndex() >= uint64(len(values)) {
+			return errors.New("index out of bounds")
+		}
+		req.SetValue(values[req.ArgIndex()])
+	case 2:
+		req.SetValue(values)
+	case 3:
+		req.SetValue(req.ArgKey())
+	case 4:
+		if s.badType {
+			req.SetValue(uint64(len(values)))
+		} else {
+			req.SetValue(time.Duration(len(values)))
+		}
+	case 5:
+		if len(values) > 0 {
+			ip := make(net.IP, 4)
+			binary.BigEndian.PutUint32(ip, uint32(values[0]))
+			req.SetValue(ip)
+		}
+	case 6:
+		var res [][]byte
+		for _, v := range values {
+			res = append(res, []byte(strconv.FormatUint(v, 16)))
+		}
+		req.SetValue(res)
+	}
+	return nil
+}
+
+func (s *samplePlugin) String(evt sdk.EventReader) (string, error) {
+	return strings.Repeat("x", len(s.values(evt))), nil
+}
+
+func TestSetRandomArg(t *testing.T) {
+	p := &samplePlugin{}
+	fields := p.Fields()
+	rnd := rand.New(rand.NewSource(0))
+	accepted := make(map[string]int)
+	for i := 0; i < 1000; i++ {
+		for j := range fields {
+			req := &InMemoryExtractRequest{ValField: fields[j].Name}
+			setRandomArg(&fields[j], req, rnd)
+			if fields[j].Arg.IsRequired && !req.ValArgPresent {
+				t.Fatalf("expected argument for field '%s'", fields[j].Name)
+			}
+			if !fields[j].Arg.IsIndex && !fields[j].Arg.IsKey && req.ValArgPresent {
+				t.Fatalf("unexpected argument for field '%s'", fields[j].Name)
+			}
+			if strings.Contains(req.ValArgKey, "\x00") {
+				t.Fatalf("unexpected null character in key for field '%s'", fields[j].Name)
+			}
+			if fields[j].Arg.Validate(req) == nil {
+				accepted[fields[j].Name]++
+			}
+		}
+	}
+	for _, f := range fields {
+		if accepted[f.Name] == 0 {
+			t.Fatalf("no valid argument generated for field '%s'", f.Name)
+		}
+	}
+}
+
+func TestCheckEvent(t *testing.T) {
+	rnd := rand.New(rand.NewSource(0))
+	evt := &InMemoryEventReader{Buffer: make([]byte, 20)}
+
+	// errors are not failures
+	if err := checkEvent(&samplePlugin{}, evt, rnd); err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	// panics are failures
+	err := checkEvent(&samplePlugin{panicOnShort: true}, evt, rnd)
+	if err == nil || !strings.Contains(err.Error(), "short event") {
+		t.Fatalf("expected panic error, but found: %v", err)
+	}
+
+	// invalid types are failures
+	err = checkEvent(&samplePlugin{badType: true}, evt, rnd)
+	if err == nil || !strings.Contains(err.Error(), "sample.duration") {
+		t.Fatalf("expected invalid value error, but found: %v", err)
+	}
+}
+
+func TestParseField(t *testing.T) {
+	fields := (&samplePlugin{}).Fields()
+	for _, tc := range []struct {
+		field    string
+		expected *Field
+		err      error
+	}{
+		{field: "sample.count", expected: &Field{Index: 0, Name: "sample.count"}},
+		{field: "sample.value[3]", expected: &Field{Index: 1, Name: "sample.value", ArgIndex: 3, ArgPresent: true}},
+		{field: "sample.key[hex]", expected: &Field{Index: 3, Name: "sample.key", ArgKey: "hex", ArgPresent: true}},
+		{field: "sample.addr", expected: &Field{Index: 5, Name: "sample.ip"}},
+		{field: "sample.value[x]", err: sdk.ErrInvalidFieldArg},
+		{field: "sample.count[0]", err: sdk.ErrInvalidFieldArg},
+		{field: "sample.unknown"},
+	} {
+		f, err := ParseField(fields, tc.field)
+		if tc.expected == nil {
+			if err == nil || (tc.err != nil && !errors.Is(err, tc.err)) {
+				t.Errorf("%s: expected error %v, but found %v", tc.field, tc.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", tc.field, err.Error())
+		} else if *f != *tc.expected {
+			t.Errorf("%s: expected %+v, but found %+v", tc.field, *tc.expected, *f)
+		}
+	}
+}
@@ -51,6 +51,7 @@ limitations under the License.
 //  - extract:      plugin_extract_fields
 //  - evtstr:       plugin_event_to_string
 //  - progress:     plugin_get_progress
+//  - metrics:      plugin_get_metrics
 //
 // There are no horizontal dependencies between the sub-packages, which means
 // that they are independent from one another. Each sub-package only depends
@@ -59,4 +60,10 @@ limitations under the License.
 // composable, and developers can easily mix manually implemented C symbols
 // with the prebuilt ones, as long as the interface requirements are respected.
 //
+// The prebuilt symbols recover from the panics raised by the plugin code,
+// and turn them into failures. The panic value and its stack trace are
+// recorded as a sdk.PanicError through the sdk.LastError interface. If the
+// plugin state implements sdk.Poisoner, it gets marked as poisoned and the
+// subsequent calls to the prebuilt symbols fail fast with sdk.ErrPoisoned.
+//
 package symbols
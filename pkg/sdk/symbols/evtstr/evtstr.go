@@ -22,6 +22,11 @@ limitations under the License.
 // of cgo.Handle from this SDK. The value of the s handle must implement
 // the sdk.Stringer and sdk.StringerBuffer interfaces.
 //
+// Panics raised by String are recovered and the returned string describes
+// the panic, as it happens for errors. The panic is recorded as a
+// sdk.PanicError in the value of the s handle as for the sdk.LastError and
+// sdk.Poisoner interfaces.
+//
 // This function is part of the plugin_api interface as defined in plugin_api.h.
 // In almost all cases, your plugin should import this module,
 // unless your plugin exports those symbols by other means.
@@ -36,14 +41,25 @@ import (
 
 	"github.com/falcosecurity/plugin-sdk-go/pkg/cgo"
 	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk"
+	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk/internal/recovery"
 )
 
 //export plugin_event_to_string
-func plugin_event_to_string(pState C.uintptr_t, evt *C.ss_plugin_event_input) *C.char {
+func plugin_event_to_string(pState C.uintptr_t, evt *C.ss_plugin_event_input) (res *C.char) {
 	buf := cgo.Handle(pState).Value().(sdk.StringerBuffer).StringerBuffer()
 	stringer, ok := cgo.Handle(pState).Value().(sdk.Stringer)
 	if ok {
-		if str, err := stringer.String(sdk.NewEventReader(unsafe.Pointer(evt))); err == nil {
+		if err := recovery.Poisoned(cgo.Handle(pState)); err != nil {
+			buf.Write(err.Error())
+			return (*C.char)(buf.CharPtr())
+		}
+		defer recovery.Recover(cgo.Handle(pState), func(err error) {
+			buf.Write(err.Error())
+			res = (*C.char)(buf.CharPtr())
+		})
+		evtReader := sdk.NewEventReader(unsafe.Pointer(evt))
+		defer sdk.ReleaseEventReader(evtReader)
+		if str, err := stringer.String(evtReader); err == nil {
 			buf.Write(str)
 		} else {
 			buf.Write(err.Error())
@@ -21,6 +21,7 @@ import (
 	"bytes"
 	"errors"
 	"io/ioutil"
+	"strings"
 	"testing"
 	"time"
 	"unsafe"
@@ -37,6 +38,7 @@ var strSuccess = "success"
 type sampleEvtStr struct {
 	strBuf       ptr.StringBuffer
 	shouldError  bool
+	shouldPanic  bool
 	expectedData []byte
 }
 
@@ -58,6 +60,9 @@ func (s *sampleEvtStr) StringerBuffer() sdk.StringBuffer {
 }
 
 func (s *sampleEvtStr) String(evt sdk.EventReader) (string, error) {
+	if s.shouldPanic {
+		panic(errTest)
+	}
 	if s.shouldError {
 		return "", errTest
 	}
@@ -97,4 +102,12 @@ func TestEvtStr(t *testing.T) {
 	if str != errTest.Error() {
 		t.Errorf("expected %s, but found %s", strSuccess, str)
 	}
+
+	// test recovered panic
+	sample.shouldPanic = true
+	cStr = plugin_event_to_string(_Ctype_uintptr_t(handle), event)
+	str = ptr.GoString(unsafe.Pointer(cStr))
+	if !strings.HasPrefix(str, "panic: "+errTest.Error()) {
+		t.Errorf("expected panic description, but found %s", str)
+	}
 }
@@ -23,8 +23,6 @@ package extract
 import "C"
 import (
 	"fmt"
-	"math"
-	"reflect"
 	"runtime"
 	"sync"
 	"sync/atomic"
@@ -62,9 +60,10 @@ import (
 //   - Worker 2 will sync over batch slots: 2, 5, 8, 11, ...
 //
 // note: this package is aware and dependent on the current implementation
-// of our cgo package. Since cgo.Handle values are regular integers between 1
-// and cgo.MaxHandle, they are used to assign the batch slot index to
-// each consumer. Each initialized plugin is a distinct consumer and has
+// of our cgo package. Since cgo.Handle values hold a compact index between 1
+// and cgo.MaxHandle, it is used to assign the batch slot index to
+// each consumer. The batch is allocated in chunks as greater indexes are
+// used, so that its size scales with the number of plugin handles. Each initialized plugin is a distinct consumer and has
 // its own assigned cgo.Handle value. I really don't like leaking this
 // implementation knowledge of our cgo package, however the goal here is to
 // reach maximum performance and using array-based access is our best option.
@@ -73,29 +72,30 @@ import (
 // cgo.Handle implementation
 
 const (
-	state_unused   = iota // the lock is unused
-	state_wait            // the lock is free and a new request can be sent
-	state_data_req        // an extraction request has been sent by the consumer
-	state_exit_req        // an exit request has been sent by the consumer
-	state_exit_ack        // an exit request has been resolved by the worker
+	state_unused        = iota // the lock is unused
+	state_wait                 // the lock is free and a new request can be sent
+	state_data_req             // an extraction request has been sent by the consumer
+	state_exit_req             // an exit request has been sent by the consumer
+	state_exit_ack             // an exit request has been resolved by the worker
+	state_data_req_wait        // an extraction request has been sent by the consumer, which is blocked waiting
 )
 
 const (
-	// starvationThresholdNs is the time in nanoseconds on which async workers
-	// can be in busy-loop without going to sleep
-	starvationThresholdNs = int64(1e6)
+	// asyncChunkSize is the physical size of the batch chunks allocated
+	// in C memory, namely the number of locks added at each allocation
+	asyncChunkSize = C.ASYNC_CHUNK_SIZE
 	//
-	// sleepTimeNs is the sleep time in nanoseconds for async workers
-	// after busy-looping for starvationThresholdNs time
-	sleepTimeNs = 1e7 * time.Nanosecond
-	//
-	// asyncBatchSize is the physical size of batches allocated
-	// in C memory, namely the total number of locks available
-	asyncBatchSize = cgo.MaxHandle + 1
+	// asyncMaxChunks is the max number of batch chunks, so that the batch
+	// has one slot for each valid cgo.Handle index
+	asyncMaxChunks = C.ASYNC_MAX_CHUNKS
 	//
 	// max number of seconds we're willing to wait for a worker to exit
 	// once released before triggering a panic
 	workerReleaseTimeoutInSeconds = 10
+	//
+	// max time an idle worker blocks on a futex before checking its
+	// slots again, just as a safety net against missed wake-ups
+	workerFutexTimeout = time.Second
 )
 
 var (
@@ -103,9 +103,20 @@ var (
 	ctx asyncContext
 )
 
+func init() {
+	if C.ASYNC_HANDLE_INDEX_BITS != cgo.HandleIndexBits {
+		panic("plugin-sdk-go/sdk/symbols/extract: async batch layout does not match cgo.Handle")
+	}
+}
+
 // asyncContext bundles all the state information used by the async
 // extraction optimization
 type asyncContext struct {
+	// stats are the statistics collected by the async workers, and the
+	// ones collected from the batch slots once released. This is the
+	// first field to guarantee the 64-bit alignment of its counters.
+	stats asyncStats
+	//
 	// disabled is false if the async optimization is configured
 	// to be enabled
 	disabled bool
@@ -114,18 +125,34 @@ type asyncContext struct {
 	// async optimization
 	available bool
 	//
-	// m ensures that Async/SetAsync/StartAsync/StopAsync are invoked
-	// with mutual exclusion
+	// m ensures that Async/SetAsync/AsyncConfig/SetAsyncConfig/StartAsync/StopAsync
+	// are invoked with mutual exclusion
 	m sync.Mutex
 	//
+	// config is the configuration set with SetAsyncConfig
+	config AsyncConfig
+	//
+	// spinNs, sleep, and pinCPUs are the busy-wait time in nanoseconds,
+	// the sleep time, and the CPUs for pinning of the async workers,
+	// as configured when the batch is initialized
+	spinNs  int64
+	sleep   time.Duration
+	pinCPUs []int
+	//
+	// futex is true if workers and consumers wait for each other
+	// with futexes, as configured when the batch is initialized
+	futex bool
+	//
 	// count is incremented at every call to StartAsync and
 	// is decremented at every call to StopAsync
 	count int32
 	//
-	// batch is a batch of info that is shared between C and Go.
+	// chunks are the chunks of a batch of info that is shared between
+	// C and Go, of which only the first numChunks are allocated.
 	// Each slot of the batch contains a distinct lock and is assigned
-	// to only one cgo.Handle value.
-	batch []C.async_extractor_info
+	// to only one cgo.Handle index.
+	chunks    []*[asyncChunkSize]C.async_extractor_info
+	numChunks int
 	//
 	// maxWorkers is the max number of workers that can be active at the same time
 	maxWorkers int32
@@ -135,7 +162,7 @@ type asyncContext struct {
 	activeWorkers []bool
 	//
 	// maxBatchIdx is the greatest slot index occupied in the batch.
-	// This value is >= 0 and < asyncCtxBatchCap. This is used by async workers
+	// This value is >= 0 and within the allocated chunks. This is used by async workers
 	// to avoid looping over batch slots that are known to be unused in order to
 	// minimize the synchronization overhead.
 	maxBatchIdx int32
@@ -153,25 +180,59 @@ func (a *asyncContext) Async() bool {
 	return !a.disabled
 }
 
+func (a *asyncContext) SetAsyncConfig(c AsyncConfig) error {
+	if err := c.validate(); err != nil {
+		return err
+	}
+	c.PinCPUs = append([]int(nil), c.PinCPUs...)
+	a.m.Lock()
+	defer a.m.Unlock()
+	a.config = c
+	return nil
+}
+
+func (a *asyncContext) AsyncConfig() AsyncConfig {
+	a.m.Lock()
+	defer a.m.Unlock()
+	res := a.config
+	res.PinCPUs = append([]int(nil), res.PinCPUs...)
+	return res
+}
+
 // 1 batch slot maps to only 1 worker
 func (a *asyncContext) batchIdxToWorkerIdx(slotIdx int32) int32 {
 	return slotIdx % a.maxWorkers
 }
 
-// 1 worker maps to 1+ batch slots
-func (a *asyncContext) workerIdxToBatchIdxs(workerIdx int32) (res []int32) {
-	for i := int32(workerIdx); i < int32(asyncBatchSize); i += a.maxWorkers {
+// 1 worker maps to 1+ batch slots, of which we consider the ones up to maxBatchIdx
+func (a *asyncContext) workerIdxToBatchIdxs(workerIdx, maxBatchIdx int32) (res []int32) {
+	for i := int32(workerIdx); i <= maxBatchIdx; i += a.maxWorkers {
 		res = append(res, i)
 	}
 	return
 }
 
 func (a *asyncContext) handleToBatchIdx(h cgo.Handle) int32 {
-	return int32(h) - 1
+	return int32(h.Index()) - 1
+}
+
+// slot returns the batch slot at the given index, which must be
+// within the allocated chunks
+func (a *asyncContext) slot(batchIdx int32) *C.async_extractor_info {
+	return &a.chunks[batchIdx/asyncChunkSize][batchIdx%asyncChunkSize]
 }
 
-func (a *asyncContext) getMaxWorkers(maxProcs int) int32 {
-	return int32(math.Ceil(math.Log2(float64(maxProcs))))
+// growBatch allocates the batch chunks up to the one containing the slot
+// at the given index, with all the slots set as unused
+func (a *asyncContext) growBatch(batchIdx int32, allocChunk func(int) *[asyncChunkSize]C.async_extractor_info) {
+	for a.numChunks <= int(batchIdx/asyncChunkSize) {
+		chunk := allocChunk(a.numChunks)
+		for i := range chunk {
+			atomic.StoreInt32((*int32)(&chunk[i].lock), state_unused)
+		}
+		a.chunks[a.numChunks] = chunk
+		a.numChunks++
+	}
 }
 
 func (a *asyncContext) acquireWorker(workerIdx int32) {
@@ -183,54 +244,97 @@ func (a *asyncContext) acquireWorker(workerIdx int32) {
 	// start the worker
 	a.activeWorkers[workerIdx] = true
 	go func() {
+		// pin the worker to a CPU, if requested
+		if len(a.pinCPUs) > 0 {
+			runtime.LockOSThread()
+			defer runtime.UnlockOSThread()
+			C.async_pin_thread(C.int(a.pinCPUs[int(workerIdx)%len(a.pinCPUs)]))
+		}
+
 		waitStartTime := time.Now().UnixNano()
-		batchIdxs := a.workerIdxToBatchIdxs(workerIdx)
 		for {
-			// loop over async context batch slots in round-robin
-			for _, i := range batchIdxs {
-				// reduce sync overhead by skipping unused batch slots
-				if i > a.maxBatchIdx {
-					// from this point on we'll only encountered unused slots
-					// so we mind as well just start over
-					break
-				}
+			// loop over async context batch slots in round-robin, and
+			// reduce sync overhead by skipping the slots after maxBatchIdx,
+			// which are known to be unused
+			maxBatchIdx := atomic.LoadInt32(&a.maxBatchIdx)
+			for i := workerIdx; i <= maxBatchIdx; i += a.maxWorkers {
+				info := a.slot(i)
 
 				// check for incoming request, if any, otherwise busy waits
-				switch atomic.LoadInt32((*int32)(&a.batch[i].lock)) {
+				switch atomic.LoadInt32((*int32)(&info.lock)) {
 
-				case state_data_req:
+				case state_data_req, state_data_req_wait:
 					// incoming data request, process it...
-					a.batch[i].rc = C.int32_t(
+					info.rc = C.int32_t(
 						plugin_extract_fields_sync(
-							C.uintptr_t(uintptr(a.batch[i].s)),
-							a.batch[i].evt,
-							uint32(a.batch[i].num_fields),
-							a.batch[i].fields,
-							a.batch[i].value_offsets,
+							C.uintptr_t(uintptr(info.s)),
+							info.evt,
+							uint32(info.num_fields),
+							info.fields,
+							info.value_offsets,
 						),
 					)
-					// processing done, return back to waiting state
-					atomic.StoreInt32((*int32)(&a.batch[i].lock), state_wait)
+					// processing done, return back to waiting state,
+					// and wake up the consumer if it's blocked waiting
+					if atomic.SwapInt32((*int32)(&info.lock), state_wait) == state_data_req_wait {
+						C.async_futex_wake((*C.int32_t)(unsafe.Pointer(&info.lock)))
+					}
 					// reset waiting start time
 					waitStartTime = 0
 
 				case state_exit_req:
 					// Incoming exit request. Send ack and exit.
-					atomic.StoreInt32((*int32)(&a.batch[i].lock), state_exit_ack)
+					atomic.StoreInt32((*int32)(&info.lock), state_exit_ack)
 					return
 				}
 
-				// busy wait, then sleep after 1ms
+				// busy wait, then block or sleep after the configured spin time
 				if waitStartTime == 0 {
 					waitStartTime = time.Now().UnixNano()
-				} else if time.Now().UnixNano()-waitStartTime > starvationThresholdNs {
-					time.Sleep(sleepTimeNs)
+				} else if time.Now().UnixNano()-waitStartTime > a.spinNs {
+					if a.futex {
+						a.parkWorker(workerIdx)
+						waitStartTime = 0
+					} else if a.sleep > 0 {
+						atomic.AddUint64(&a.stats.workerSleeps, 1)
+						time.Sleep(a.sleep)
+					} else {
+						atomic.AddUint64(&a.stats.workerSleeps, 1)
+						runtime.Gosched()
+					}
 				}
 			}
 		}
 	}()
 }
 
+// parkWorker blocks the worker at the given index until a consumer sends a
+// new request to one of its slots, or until an exit request is sent
+func (a *asyncContext) parkWorker(workerIdx int32) {
+	// advertise that the worker is about to block, so that new requests
+	// wake it up, and then check for requests sent in the meanwhile
+	wake := (*int32)(&a.slot(workerIdx).wake)
+	atomic.StoreInt32(wake, 1)
+	for i := workerIdx; i <= atomic.LoadInt32(&a.maxBatchIdx); i += a.maxWorkers {
+		switch atomic.LoadInt32((*int32)(&a.slot(i).lock)) {
+		case state_data_req, state_data_req_wait, state_exit_req:
+			atomic.StoreInt32(wake, 0)
+			return
+		}
+	}
+	atomic.AddUint64(&a.stats.workerParks, 1)
+	C.async_futex_wait((*C.int32_t)(unsafe.Pointer(wake)), 1, C.int64_t(workerFutexTimeout))
+	atomic.StoreInt32(wake, 0)
+}
+
+// unparkWorker wakes up the worker at the given index, if it's blocked
+func (a *asyncContext) unparkWorker(workerIdx int32) {
+	wake := (*int32)(&a.slot(workerIdx).wake)
+	if atomic.SwapInt32(wake, 0) != 0 {
+		C.async_futex_wake((*C.int32_t)(unsafe.Pointer(wake)))
+	}
+}
+
 func (a *asyncContext) releaseWorker(workerIdx int32) {
 	if !a.activeWorkers[workerIdx] {
 		// work is not running, no need to stop it
@@ -239,8 +343,8 @@ func (a *asyncContext) releaseWorker(workerIdx int32) {
 
 	// check all the batch slots assigned to the worker,
 	// and stop it only if all of them are unused
-	for _, i := range a.workerIdxToBatchIdxs(workerIdx) {
-		if atomic.LoadInt32((*int32)(&a.batch[i].lock)) != state_unused {
+	for _, i := range a.workerIdxToBatchIdxs(workerIdx, atomic.LoadInt32(&a.maxBatchIdx)) {
+		if atomic.LoadInt32((*int32)(&a.slot(i).lock)) != state_unused {
 			// worker is still needed, we should not stop it
 			return
 		}
@@ -250,19 +354,20 @@ func (a *asyncContext) releaseWorker(workerIdx int32) {
 	// unused and the worker is looping over unused locks. Right from the Go
 	// side, we use the first visible slot and set an exit request. The worker
 	// will eventually synchronize with the used lock and stop.
-	idx := a.workerIdxToBatchIdxs(workerIdx)[0]
+	lock := (*int32)(&a.slot(workerIdx).lock)
 	waitStartTime := time.Now()
-	for !atomic.CompareAndSwapInt32((*int32)(&a.batch[idx].lock), state_unused, state_exit_req) {
+	for !atomic.CompareAndSwapInt32(lock, state_unused, state_exit_req) {
 		// spinning, but let's yield first
 		runtime.Gosched()
 		if time.Since(waitStartTime).Seconds() > workerReleaseTimeoutInSeconds {
 			panic("plugin-sdk-go/sdk/symbols/extract: async worker release timeout expired (1)")
 		}
 	}
+	a.unparkWorker(workerIdx)
 
 	// wait for worker exiting
 	waitStartTime = time.Now()
-	for atomic.LoadInt32((*int32)(&a.batch[idx].lock)) != state_exit_ack {
+	for atomic.LoadInt32(lock) != state_exit_ack {
 		// spinning, but let's yield first
 		runtime.Gosched()
 		if time.Since(waitStartTime).Seconds() > workerReleaseTimeoutInSeconds {
@@ -271,11 +376,11 @@ func (a *asyncContext) releaseWorker(workerIdx int32) {
 	}
 
 	// restore first worker slot
-	atomic.StoreInt32((*int32)(&a.batch[idx].lock), state_unused)
+	atomic.StoreInt32(lock, state_unused)
 	a.activeWorkers[workerIdx] = false
 }
 
-func (a *asyncContext) StartAsync(handle cgo.Handle, allocBatch func() []C.async_extractor_info) {
+func (a *asyncContext) StartAsync(handle cgo.Handle, allocChunk func(int) *[asyncChunkSize]C.async_extractor_info) {
 	a.m.Lock()
 	defer a.m.Unlock()
 
@@ -299,32 +404,44 @@ func (a *asyncContext) StartAsync(handle cgo.Handle, allocBatch func() []C.async
 	}
 
 	// init the context when the first consumer starts the async optimization
-	if a.count >= 1 && a.batch == nil {
-		// init a new batch
-		a.batch = allocBatch()
-		for i := 0; i < asyncBatchSize; i++ {
-			atomic.StoreInt32((*int32)(&a.batch[i].lock), state_unused)
-		}
+	if a.count >= 1 && a.chunks == nil {
+		// init a new batch, with no chunk allocated yet
+		a.chunks = make([]*[asyncChunkSize]C.async_extractor_info, asyncMaxChunks)
+		a.numChunks = 0
 
 		// no batch index is used at the beginning
 		atomic.StoreInt32(&a.maxBatchIdx, 0)
 
-		// compute the max number of workers and set all of them as unused
-		a.maxWorkers = a.getMaxWorkers(runtime.GOMAXPROCS(0))
+		// apply the configuration, compute the max number of workers,
+		// and set all of them as unused
+		a.spinNs = int64(a.config.spinDuration())
+		a.sleep = a.config.sleepDuration()
+		a.pinCPUs = a.config.PinCPUs
+		a.maxWorkers = a.config.workers(runtime.GOMAXPROCS(0))
 		a.activeWorkers = make([]bool, a.maxWorkers)
+		a.futex = a.config.Wait == AsyncWaitFutex && C.async_futex_supported() != 0
+		if a.futex {
+			C.async_set_wait(C.ASYNC_WAIT_FUTEX, C.int(a.maxWorkers))
+		}
+
+		// each worker needs its first slot to be released, and the slot
+		// at index 0 is always considered by the workers
+		a.growBatch(a.maxWorkers-1, allocChunk)
 	}
 
 	// assign a batch slot to this handle and acquire a worker.
-	// Each handle has a 1-1 mapping with a batch slot
+	// Each handle has a 1-1 mapping with a batch slot, and the batch
+	// grows as needed
 	batchIdx := a.handleToBatchIdx(handle)
-	atomic.StoreInt32((*int32)(&a.batch[batchIdx].lock), state_wait)
+	a.growBatch(batchIdx, allocChunk)
+	atomic.StoreInt32((*int32)(&a.slot(batchIdx).lock), state_wait)
 	if batchIdx > atomic.LoadInt32(&a.maxBatchIdx) {
 		atomic.StoreInt32(&a.maxBatchIdx, batchIdx)
 	}
 	a.acquireWorker(a.batchIdxToWorkerIdx(batchIdx))
 }
 
-func (a *asyncContext) StopAsync(handle cgo.Handle, freeBatch func([]C.async_extractor_info)) {
+func (a *asyncContext) StopAsync(handle cgo.Handle, freeChunks func([]*[asyncChunkSize]C.async_extractor_info)) {
 	a.m.Lock()
 	defer a.m.Unlock()
 
@@ -333,19 +450,21 @@ func (a *asyncContext) StopAsync(handle cgo.Handle, freeBatch func([]C.async_ext
 		panic("plugin-sdk-go/sdk/symbols/extract: async worker stopped without being started")
 	}
 
-	if a.batch != nil {
+	if a.chunks != nil {
 		// update the state vars if this handle used async extraction
 		batchIdx := a.handleToBatchIdx(handle)
-		if atomic.LoadInt32((*int32)(&a.batch[batchIdx].lock)) != state_unused {
-			// set the assigned batch slot as unused and release worker
-			atomic.StoreInt32((*int32)(&a.batch[batchIdx].lock), state_unused)
+		if int(batchIdx/asyncChunkSize) < a.numChunks && atomic.LoadInt32((*int32)(&a.slot(batchIdx).lock)) != state_unused {
+			// set the assigned batch slot as unused and release worker,
+			// by keeping the statistics it collected
+			a.collectSlotStats(batchIdx)
+			atomic.StoreInt32((*int32)(&a.slot(batchIdx).lock), state_unused)
 			a.releaseWorker(a.batchIdxToWorkerIdx(batchIdx))
 
 			// update the current maximum used slot, so that async workers
 			// will not try to sync over this index
 			if batchIdx == atomic.LoadInt32(&a.maxBatchIdx) {
 				for i := int32(batchIdx) - 1; i >= 0; i-- {
-					if atomic.LoadInt32((*int32)(&a.batch[i].lock)) != state_unused {
+					if atomic.LoadInt32((*int32)(&a.slot(i).lock)) != state_unused {
 						atomic.StoreInt32(&a.maxBatchIdx, i)
 						break
 					}
@@ -362,21 +481,19 @@ func (a *asyncContext) StopAsync(handle cgo.Handle, freeBatch func([]C.async_ext
 					panic(fmt.Sprintf("plugin-sdk-go/sdk/symbols/extract: worker %d can't be stopped", i))
 				}
 			}
-			freeBatch(a.batch)
-			a.batch = nil
+			C.async_set_wait(C.ASYNC_WAIT_SPIN, 1)
+			freeChunks(a.chunks[:a.numChunks])
+			a.chunks = nil
+			a.numChunks = 0
 		}
 	}
 }
 
-func allocBatchInCMemory() (res []C.async_extractor_info) {
-	cBuf := unsafe.Pointer(C.async_init((C.size_t)(asyncBatchSize)))
-	(*reflect.SliceHeader)(unsafe.Pointer(&res)).Data = uintptr(cBuf)
-	(*reflect.SliceHeader)(unsafe.Pointer(&res)).Len = int(asyncBatchSize)
-	(*reflect.SliceHeader)(unsafe.Pointer(&res)).Cap = int(asyncBatchSize)
-	return
+func allocChunkInCMemory(chunkIdx int) *[asyncChunkSize]C.async_extractor_info {
+	return (*[asyncChunkSize]C.async_extractor_info)(unsafe.Pointer(C.async_alloc_chunk((C.size_t)(chunkIdx))))
 }
 
-func freeBatchInCMemory(c []C.async_extractor_info) {
+func freeChunksInCMemory(c []*[asyncChunkSize]C.async_extractor_info) {
 	C.async_deinit()
 }
 
@@ -412,6 +529,13 @@ func Async() bool {
 // so it should be carefully used only if the rate of C -> Go calls makes
 // the tradeoff worth it.
 //
+// The number of workers, how long they busy-wait before sleeping, whether
+// they are pinned to CPUs, and whether they block on futexes instead of
+// sleeping can be configured with SetAsyncConfig. Whether the optimization
+// pays off for a given workload can be assessed with Stats.
+// For example, in containers with CPU limits, reducing the busy-wait time
+// prevents idle workers from consuming the CPU quota.
+//
 // The behavior of StartAsync is influenced by the value set through SetAsync:
 // if set to true the SDK will attempt to run the optimization depending on
 // the underlying runtime capacity, otherwise this will have no effect.
@@ -427,12 +551,12 @@ func Async() bool {
 // StartAsync and StopAsync calls because the optimization can eccessively
 // occupy the downsized Go runtime and eventually block it.
 func StartAsync(handle cgo.Handle) {
-	ctx.StartAsync(handle, allocBatchInCMemory)
+	ctx.StartAsync(handle, allocChunkInCMemory)
 }
 
 // StopAsync deinitializes the asynchronous extraction mode for the given plugin
 // handle, and undoes a single previous StartAsync call. It is a run-time error
 // if StartAsync was not called before calling StopAsync.
 func StopAsync(handle cgo.Handle) {
-	ctx.StopAsync(handle, freeBatchInCMemory)
+	ctx.StopAsync(handle, freeChunksInCMemory)
 }
@@ -41,11 +41,11 @@ func (s *sampleAsyncExtract) Extract(req sdk.ExtractRequest, evt sdk.EventReader
 	return nil
 }
 
-func testAllocAsyncBatch() []_Ctype_async_extractor_info {
-	return make([]_Ctype_async_extractor_info, asyncBatchSize)
+func testAllocAsyncBatch(chunkIdx int) *[asyncChunkSize]_Ctype_async_extractor_info {
+	return new([asyncChunkSize]_Ctype_async_extractor_info)
 }
 
-func testReleaseAsyncBatch(c []_Ctype_async_extractor_info) {}
+func testReleaseAsyncBatch(c []*[asyncChunkSize]_Ctype_async_extractor_info) {}
 
 func TestSetAsync(t *testing.T) {
 	a := asyncContext{}
@@ -65,14 +65,18 @@ func TestSetAsync(t *testing.T) {
 }
 
 func TestAsyncGetMaxWorkers(t *testing.T) {
-	a := asyncContext{}
+	c := AsyncConfig{}
 	expected := []int32{0, 1, 2, 2, 3, 3, 3, 3, 4, 4, 4, 4, 4, 4, 4, 4, 5}
 	for i, ex := range expected {
-		v := a.getMaxWorkers(i + 1)
+		v := c.workers(i + 1)
 		if v != ex {
-			t.Fatalf("getMaxWorkers returned %d but expected %d", v, ex)
+			t.Fatalf("workers returned %d but expected %d", v, ex)
 		}
 	}
+	c.Workers = 3
+	if v := c.workers(16); v != 3 {
+		t.Fatalf("workers returned %d but expected %d", v, 3)
+	}
 }
 
 func TestAsyncBatchIdxWorkerIdx(t *testing.T) {
@@ -85,16 +89,53 @@ func TestAsyncBatchIdxWorkerIdx(t *testing.T) {
 		}
 		for i := int32(0); i < a.maxWorkers; i++ {
 			expectedIdx := int32(i)
-			for _, v := range a.workerIdxToBatchIdxs(i) {
+			for _, v := range a.workerIdxToBatchIdxs(i, 1000) {
 				if v != expectedIdx {
 					t.Fatalf("workerIdxToBatchIdxs returned %d but expected %d", v, expectedIdx)
 				}
 				expectedIdx += a.maxWorkers
 			}
+			if expectedIdx <= 1000 {
+				t.Fatalf("workerIdxToBatchIdxs stopped at %d but expected to reach %d", expectedIdx-a.maxWorkers, 1000)
+			}
 		}
 	}
 }
 
+func TestAsyncGrowBatch(t *testing.T) {
+	a := asyncContext{chunks: make([]*[asyncChunkSize]_Ctype_async_extractor_info, asyncMaxChunks)}
+	allocs := 0
+	alloc := func(chunkIdx int) *[asyncChunkSize]_Ctype_async_extractor_info {
+		if chunkIdx != allocs {
+			t.Fatalf("allocated chunk %d but expected %d", chunkIdx, allocs)
+		}
+		allocs++
+		return testAllocAsyncBatch(chunkIdx)
+	}
+
+	// chunks are allocated up to the one of the requested slot
+	a.growBatch(0, alloc)
+	a.growBatch(asyncChunkSize*2+1, alloc)
+	a.growBatch(asyncChunkSize, alloc)
+	if a.numChunks != 3 || allocs != 3 {
+		t.Fatalf("expected %d chunks, but found %d (%d allocations)", 3, a.numChunks, allocs)
+	}
+	for _, i := range []int32{0, asyncChunkSize - 1, asyncChunkSize*3 - 1} {
+		if s := atomic.LoadInt32((*int32)(&a.slot(i).lock)); s != state_unused {
+			t.Fatalf("expected slot %d to be unused, but found state %d", i, s)
+		}
+	}
+
+	// handle indexes beyond the first chunk map to their own slot
+	h := cgo.Handle(asyncChunkSize*2 + 5)
+	if a.handleToBatchIdx(h) != asyncChunkSize*2+4 {
+		t.Fatalf("expected slot %d, but found %d", asyncChunkSize*2+4, a.handleToBatchIdx(h))
+	}
+	if a.handleToBatchIdx(h|1<<cgo.HandleIndexBits) != a.handleToBatchIdx(h) {
+		t.Fatalf("expected the handle generation not to affect the slot")
+	}
+}
+
 func testWithMockPlugins(n int, f func([]cgo.Handle)) {
 	plugins := make([]sampleAsyncExtract, n)
 	handles := make([]cgo.Handle, n)
@@ -111,18 +152,18 @@ func testWithMockPlugins(n int, f func([]cgo.Handle)) {
 
 // this simulates a C consumer as in extract.c
 func testSimulateAsyncRequest(t testing.TB, a *asyncContext, h cgo.Handle, r *_Ctype_ss_plugin_extract_field) {
-	i := a.handleToBatchIdx(h)
-	a.batch[i].s = unsafe.Pointer(h)
-	a.batch[i].evt = nil
-	a.batch[i].num_fields = 1
-	a.batch[i].fields = r
-
-	atomic.StoreInt32((*int32)(&a.batch[i].lock), state_data_req)
-	for atomic.LoadInt32((*int32)(&a.batch[i].lock)) != state_wait {
+	info := a.slot(a.handleToBatchIdx(h))
+	info.s = unsafe.Pointer(h)
+	info.evt = nil
+	info.num_fields = 1
+	info.fields = r
+
+	atomic.StoreInt32((*int32)(&info.lock), state_data_req)
+	for atomic.LoadInt32((*int32)(&info.lock)) != state_wait {
 		// spin
 	}
-	if int32(a.batch[i].rc) != sdk.SSPluginSuccess {
-		t.Fatalf("extraction failed with rc %v", int32(a.batch[i].rc))
+	if int32(info.rc) != sdk.SSPluginSuccess {
+		t.Fatalf("extraction failed with rc %v", int32(info.rc))
 	}
 }
 
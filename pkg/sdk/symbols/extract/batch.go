@@ -0,0 +1,127 @@
+// SPDX-License-Identifier: Apache-2.0
+/*
+Copyright (C) 2026 The Falco Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package extract
+
+/*
+#include "extract.h"
+*/
+import "C"
+import (
+	"fmt"
+	"unsafe"
+
+	"github.com/falcosecurity/plugin-sdk-go/pkg/cgo"
+	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk"
+	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk/internal/hooks"
+	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk/internal/recovery"
+)
+
+// MaxBatchEvents is the maximum number of events that can be passed to
+// plugin_extract_fields_batch in a single call. This bounds the number of
+// requests kept in the sdk.ExtractRequestPool of the plugin, which never
+// shrinks.
+const MaxBatchEvents = sdk.MaxExtractBatchEvents
+
+// plugin_extract_fields_batch is an extension of the plugin API provided by
+// this SDK, which is not part of plugin_api.h and is not used by the plugin
+// framework. It extracts the same numFields fields from each of the
+// numEvents events, so that offline tools (such as the ones built on top of
+// the loader package) can extract many events with a single C -> Go call.
+//
+// The fields array has numEvents*numFields entries, of which the ones at
+// [i*numFields, (i+1)*numFields) are the requests for the i-th event. The
+// results are valid until the next extraction call. If the value of the s
+// handle implements sdk.BatchExtractor, ExtractBatch is invoked once for
+// each field with the requests of all the events. Otherwise, Extract is
+// invoked once for each request. The first failure makes the whole
+// batch fail, and so does passing more than MaxBatchEvents events.
+//
+//export plugin_extract_fields_batch
+func plugin_extract_fields_batch(plgState C.uintptr_t, numEvents uint32, evts *C.ss_plugin_event_input, numFields uint32, fields *C.ss_plugin_extract_field) (rc int32) {
+	pHandle := cgo.Handle(plgState)
+	extrReqs := pHandle.Value().(sdk.ExtractRequests)
+	beforeExtract := hooks.OnBeforeExtract()
+
+	if err := recovery.Poisoned(pHandle); err != nil {
+		pHandle.Value().(sdk.LastError).SetLastError(err)
+		return sdk.SSPluginFailure
+	}
+
+	defer recovery.Recover(pHandle, func(error) {
+		rc = sdk.SSPluginFailure
+	})
+
+	if numEvents > MaxBatchEvents {
+		pHandle.Value().(sdk.LastError).SetLastError(fmt.Errorf("too many events in batch extraction: %d (max %d)", numEvents, MaxBatchEvents))
+		return sdk.SSPluginFailure
+	}
+
+	evtInputs := unsafe.Slice(evts, numEvents)
+	evtReaders := make([]sdk.EventReader, numEvents)
+	for i := range evtInputs {
+		evtReaders[i] = sdk.NewEventReader(unsafe.Pointer(&evtInputs[i]))
+	}
+	defer func() {
+		for _, r := range evtReaders {
+			sdk.ReleaseEventReader(r)
+		}
+	}()
+
+	// each request has its own slot in the pool, so that the results
+	// of all the events are valid at the same time
+	flds := unsafe.Slice(fields, numEvents*numFields)
+	reqs := make([]sdk.ExtractRequest, numEvents)
+	for f := uint32(0); f < numFields; f++ {
+		for e := uint32(0); e < numEvents; e++ {
+			i := e*numFields + f
+			flds[i].res_len = (C.uint64_t)(0)
+			extrReq := extrReqs.ExtractRequests().Get(int(i))
+			extrReq.SetPtr(unsafe.Pointer(&flds[i]))
+			if r, ok := extrReq.(sdk.StringBytesExtractRequest); ok {
+				r.SetEventPtr(unsafe.Pointer(&evtInputs[e]))
+			}
+			extrReq.SetOffsetPtrs(nil, nil)
+			req, err := beforeExtract(pHandle, extrReq)
+			if err != nil {
+				pHandle.Value().(sdk.LastError).SetLastError(err)
+				return sdk.SSPluginFailure
+			}
+			reqs[e] = req
+		}
+		if err := extractBatch(pHandle, reqs, evtReaders); err != nil {
+			pHandle.Value().(sdk.LastError).SetLastError(err)
+			return sdk.SSPluginFailure
+		}
+	}
+	return sdk.SSPluginSuccess
+}
+
+// extractBatch extracts the given requests of the same field, either with
+// sdk.BatchExtractor if implemented, or with sdk.Extractor otherwise
+func extractBatch(pHandle cgo.Handle, reqs []sdk.ExtractRequest, evts []sdk.EventReader) error {
+	if b, ok := pHandle.Value().(sdk.BatchExtractor); ok {
+		return b.ExtractBatch(reqs, evts)
+	}
+	extract := pHandle.Value().(sdk.Extractor)
+	for i, req := range reqs {
+		if err := extract.Extract(req, evts[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
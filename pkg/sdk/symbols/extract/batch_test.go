@@ -0,0 +1,141 @@
+// SPDX-License-Identifier: Apache-2.0
+/*
+Copyright (C) 2026 The Falco Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package extract
+
+import (
+	"errors"
+	"testing"
+	"unsafe"
+
+	"github.com/falcosecurity/plugin-sdk-go/pkg/cgo"
+	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk"
+)
+
+type sampleBatchExtract struct {
+	sampleExtract
+	extracts int
+	batches  int
+}
+
+func (s *sampleBatchExtract) Extract(req sdk.ExtractRequest, evt sdk.EventReader) error {
+	s.extracts++
+	if s.err != nil {
+		return s.err
+	}
+	req.SetValue(evt.EventNum()*10 + req.FieldID())
+	return nil
+}
+
+type sampleVectorExtract struct {
+	sampleBatchExtract
+}
+
+func (s *sampleVectorExtract) ExtractBatch(reqs []sdk.ExtractRequest, evts []sdk.EventReader) error {
+	s.batches++
+	for i := range reqs {
+		reqs[i].SetValue(evts[i].EventNum()*10 + reqs[i].FieldID())
+	}
+	return nil
+}
+
+func testExtractBatch(t *testing.T, h cgo.Handle, nEvents, nFields int) int32 {
+	evts := make([]_Ctype_struct_ss_plugin_event_input, nEvents)
+	for i := range evts {
+		evt, freeEvt := allocSSPluginEvent(uint64(i), 0, []byte{byte(i)})
+		defer freeEvt()
+		evts[i] = *evt
+	}
+	fields := make([]_Ctype_ss_plugin_extract_field, nEvents*nFields)
+	for f := 0; f < nFields; f++ {
+		field, freeField := allocSSPluginExtractField(uint32(f), sdk.FieldTypeUint64, "test.field", "")
+		defer freeField()
+		for e := 0; e < nEvents; e++ {
+			fields[e*nFields+f] = *field
+		}
+	}
+
+	rc := plugin_extract_fields_batch(_Ctype_uintptr_t(h), uint32(nEvents), &evts[0], uint32(nFields), &fields[0])
+	if rc != sdk.SSPluginSuccess {
+		return rc
+	}
+	for e := 0; e < nEvents; e++ {
+		for f := 0; f < nFields; f++ {
+			field := &fields[e*nFields+f]
+			value := **((**uint64)(unsafe.Pointer(&field.res[0])))
+			if field.res_len != 1 || value != uint64(e*10+f) {
+				t.Fatalf("event %d field %d: expected %d, but found %d (len %d)", e, f, e*10+f, value, field.res_len)
+			}
+		}
+	}
+	return rc
+}
+
+func TestExtractBatch(t *testing.T) {
+	sample := &sampleBatchExtract{}
+	sample.reqs = sdk.NewExtractRequestPool()
+	defer sample.reqs.Free()
+	handle := cgo.NewHandle(sample)
+	defer handle.Delete()
+
+	// results of all the events are valid at the same time
+	if rc := testExtractBatch(t, handle, 8, 3); rc != sdk.SSPluginSuccess {
+		t.Fatalf("expected success, but found %d (%v)", rc, sample.lastErr)
+	}
+	if sample.extracts != 8*3 {
+		t.Fatalf("expected %d extractions, but found %d", 8*3, sample.extracts)
+	}
+
+	// the first failure makes the whole batch fail
+	sample.err = errTest
+	sample.extracts = 0
+	if rc := testExtractBatch(t, handle, 8, 3); rc != sdk.SSPluginFailure || !errors.Is(sample.lastErr, errTest) {
+		t.Fatalf("expected failure, but found %d (%v)", rc, sample.lastErr)
+	}
+	if sample.extracts != 1 {
+		t.Fatalf("expected %d extractions, but found %d", 1, sample.extracts)
+	}
+
+	// the number of events is bounded, and so is the request pool
+	sample.err = nil
+	sample.extracts = 0
+	if rc := testExtractBatch(t, handle, MaxBatchEvents+1, 1); rc != sdk.SSPluginFailure || sample.lastErr == nil {
+		t.Fatalf("expected failure, but found %d (%v)", rc, sample.lastErr)
+	}
+	if sample.extracts != 0 {
+		t.Fatalf("expected no extraction, but found %d", sample.extracts)
+	}
+	if rc := testExtractBatch(t, handle, MaxBatchEvents, 1); rc != sdk.SSPluginSuccess {
+		t.Fatalf("expected success, but found %d (%v)", rc, sample.lastErr)
+	}
+}
+
+func TestExtractBatchVectorized(t *testing.T) {
+	sample := &sampleVectorExtract{}
+	sample.reqs = sdk.NewExtractRequestPool()
+	defer sample.reqs.Free()
+	handle := cgo.NewHandle(sample)
+	defer handle.Delete()
+
+	// ExtractBatch is invoked once for each field
+	if rc := testExtractBatch(t, handle, 16, 2); rc != sdk.SSPluginSuccess {
+		t.Fatalf("expected success, but found %d (%v)", rc, sample.lastErr)
+	}
+	if sample.batches != 2 || sample.extracts != 0 {
+		t.Fatalf("expected %d batches and no extraction, but found %d and %d", 2, sample.batches, sample.extracts)
+	}
+}
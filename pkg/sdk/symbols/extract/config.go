@@ -0,0 +1,203 @@
+// SPDX-License-Identifier: Apache-2.0
+/*
+Copyright (C) 2026 The Falco Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package extract
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+)
+
+const (
+	// DefaultAsyncSpinDuration is the default value of AsyncConfig.SpinDuration
+	DefaultAsyncSpinDuration = time.Millisecond
+	//
+	// DefaultAsyncSleepDuration is the default value of AsyncConfig.SleepDuration
+	DefaultAsyncSleepDuration = 10 * time.Millisecond
+)
+
+// AsyncWait represents the strategy with which the async workers and the
+// consumers of the async extraction optimization wait for each other.
+type AsyncWait string
+
+const (
+	// AsyncWaitSpin makes idle workers busy-wait, and then sleep or yield
+	// the CPU as configured with AsyncConfig.SleepDuration. Consumers
+	// always busy-wait for their requests to be completed.
+	AsyncWaitSpin AsyncWait = "spin"
+	//
+	// AsyncWaitFutex makes idle workers busy-wait, and then block until
+	// woken up by a new request. Consumers busy-wait briefly, and then
+	// block until their requests are completed. This reduces the CPU
+	// usage of idle workers to zero without affecting the latency under
+	// load. This is supported on Linux only, and AsyncWaitSpin is used
+	// on other platforms.
+	AsyncWaitFutex AsyncWait = "futex"
+)
+
+// AsyncConfig represents the configuration of the async extraction
+// optimization. For each field, the zero value stands for its default.
+//
+// AsyncConfig can be decoded from JSON, so that it can be part of the
+// init config of a plugin. Durations are represented either as strings
+// parsable by time.ParseDuration (e.g. "500us"), or as numbers of
+// nanoseconds.
+type AsyncConfig struct {
+	// Workers is the max number of async workers. By default, this is
+	// ceil(log2(runtime.GOMAXPROCS(0))). There can't be more workers than
+	// the slots of the async batch, which is one for each cgo.Handle index.
+	Workers int `json:"workers,omitempty"`
+	//
+	// SpinDuration is how long an idle async worker busy-waits for new
+	// requests before going to sleep. By default, this is
+	// DefaultAsyncSpinDuration. A negative value disables busy-waiting,
+	// which reduces the CPU usage at the cost of a greater latency.
+	SpinDuration time.Duration `json:"spinDuration,omitempty"`
+	//
+	// SleepDuration is how long an idle async worker sleeps after
+	// busy-waiting. By default, this is DefaultAsyncSleepDuration.
+	// A negative value makes workers yield the CPU without sleeping.
+	SleepDuration time.Duration `json:"sleepDuration,omitempty"`
+	//
+	// PinCPUs is a hint for pinning each async worker to a CPU, in
+	// round-robin over the listed CPU indexes. Pinning is supported on
+	// Linux only, and is disabled if the list is empty.
+	PinCPUs []int `json:"pinCPUs,omitempty"`
+	//
+	// Wait is the strategy used by workers and consumers for waiting each
+	// other. By default, this is AsyncWaitSpin. SleepDuration is not used
+	// with AsyncWaitFutex.
+	Wait AsyncWait `json:"wait,omitempty"`
+}
+
+const (
+	// maxPinCPU is the max CPU index supported for pinning
+	maxPinCPU = 1023
+	//
+	// maxAsyncWorkers is the max number of async workers, each of which
+	// needs at least one slot in the async batch
+	maxAsyncWorkers = asyncMaxChunks * asyncChunkSize
+)
+
+func (a *AsyncConfig) validate() error {
+	if a.Workers < 0 || a.Workers > maxAsyncWorkers {
+		return fmt.Errorf("invalid number of async workers: %d", a.Workers)
+	}
+	switch a.Wait {
+	case "", AsyncWaitSpin, AsyncWaitFutex:
+	default:
+		return fmt.Errorf("invalid async wait strategy: %s", a.Wait)
+	}
+	for _, c := range a.PinCPUs {
+		if c < 0 || c > maxPinCPU {
+			return fmt.Errorf("invalid CPU index for pinning async workers: %d", c)
+		}
+	}
+	return nil
+}
+
+// workers returns the max number of workers for the given number of procs
+func (a *AsyncConfig) workers(maxProcs int) int32 {
+	if a.Workers > 0 {
+		return int32(a.Workers)
+	}
+	return int32(math.Ceil(math.Log2(float64(maxProcs))))
+}
+
+// spinDuration returns the busy-wait duration, which is zero if disabled
+func (a *AsyncConfig) spinDuration() time.Duration {
+	if a.SpinDuration == 0 {
+		return DefaultAsyncSpinDuration
+	}
+	if a.SpinDuration < 0 {
+		return 0
+	}
+	return a.SpinDuration
+}
+
+// sleepDuration returns the sleep duration, which is zero if disabled
+func (a *AsyncConfig) sleepDuration() time.Duration {
+	if a.SleepDuration == 0 {
+		return DefaultAsyncSleepDuration
+	}
+	if a.SleepDuration < 0 {
+		return 0
+	}
+	return a.SleepDuration
+}
+
+func (a *AsyncConfig) UnmarshalJSON(b []byte) error {
+	type asyncConfig AsyncConfig
+	var raw struct {
+		asyncConfig
+		SpinDuration  json.RawMessage `json:"spinDuration,omitempty"`
+		SleepDuration json.RawMessage `json:"sleepDuration,omitempty"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	res := AsyncConfig(raw.asyncConfig)
+	var err error
+	if res.SpinDuration, err = decodeDuration(raw.SpinDuration); err != nil {
+		return err
+	}
+	if res.SleepDuration, err = decodeDuration(raw.SleepDuration); err != nil {
+		return err
+	}
+	*a = res
+	return nil
+}
+
+func decodeDuration(b json.RawMessage) (time.Duration, error) {
+	if len(b) == 0 {
+		return 0, nil
+	}
+	var v interface{}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return 0, err
+	}
+	switch d := v.(type) {
+	case nil:
+		return 0, nil
+	case float64:
+		return time.Duration(d), nil
+	case string:
+		return time.ParseDuration(d)
+	default:
+		return 0, fmt.Errorf("invalid duration: %s", string(b))
+	}
+}
+
+// SetAsyncConfig sets the configuration of the async extraction optimization.
+// This returns an error if the configuration is invalid.
+//
+// The configuration is applied when the async optimization gets initialized,
+// namely at the first call to StartAsync, or at the first one after all the
+// previous StartAsync calls have been undone with StopAsync. As such, this
+// should be invoked before that happens, such as in the Init method of
+// plugins using the extractor package.
+func SetAsyncConfig(c AsyncConfig) error {
+	return ctx.SetAsyncConfig(c)
+}
+
+// GetAsyncConfig returns the configuration of the async extraction
+// optimization set with SetAsyncConfig.
+func GetAsyncConfig() AsyncConfig {
+	return ctx.AsyncConfig()
+}
@@ -0,0 +1,90 @@
+// SPDX-License-Identifier: Apache-2.0
+/*
+Copyright (C) 2026 The Falco Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package extract
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestAsyncConfigJSON(t *testing.T) {
+	var c AsyncConfig
+	in := `{"workers": 2, "spinDuration": "500us", "sleepDuration": 1000, "pinCPUs": [1, 3], "wait": "futex"}`
+	if err := json.Unmarshal([]byte(in), &c); err != nil {
+		t.Fatal(err)
+	}
+	expected := AsyncConfig{
+		Workers:       2,
+		SpinDuration:  500 * time.Microsecond,
+		SleepDuration: time.Microsecond,
+		PinCPUs:       []int{1, 3},
+		Wait:          AsyncWaitFutex,
+	}
+	if !reflect.DeepEqual(c, expected) {
+		t.Fatalf("expected %+v, but found %+v", expected, c)
+	}
+
+	// durations are optional
+	c = AsyncConfig{}
+	if err := json.Unmarshal([]byte(`{"workers": 1}`), &c); err != nil {
+		t.Fatal(err)
+	}
+	if c.spinDuration() != DefaultAsyncSpinDuration || c.sleepDuration() != DefaultAsyncSleepDuration {
+		t.Fatalf("expected default durations, but found %+v", c)
+	}
+
+	for _, in := range []string{`{"spinDuration": "1 hour"}`, `{"sleepDuration": true}`} {
+		if err := json.Unmarshal([]byte(in), &c); err == nil {
+			t.Errorf("expected error for %s", in)
+		}
+	}
+}
+
+func TestSetAsyncConfig(t *testing.T) {
+	a := asyncContext{}
+	if err := a.SetAsyncConfig(AsyncConfig{Workers: -1}); err == nil {
+		t.Errorf("expected error")
+	}
+	if err := a.SetAsyncConfig(AsyncConfig{Workers: maxAsyncWorkers + 1}); err == nil {
+		t.Errorf("expected error")
+	}
+	if err := a.SetAsyncConfig(AsyncConfig{Workers: maxAsyncWorkers}); err != nil {
+		t.Error(err)
+	}
+	if err := a.SetAsyncConfig(AsyncConfig{PinCPUs: []int{maxPinCPU + 1}}); err == nil {
+		t.Errorf("expected error")
+	}
+	if err := a.SetAsyncConfig(AsyncConfig{Wait: "sleep"}); err == nil {
+		t.Errorf("expected error")
+	}
+
+	cpus := []int{0}
+	c := AsyncConfig{SpinDuration: -1, SleepDuration: -1, PinCPUs: cpus}
+	if err := a.SetAsyncConfig(c); err != nil {
+		t.Fatal(err)
+	}
+	cpus[0] = 1
+	if res := a.AsyncConfig(); res.PinCPUs[0] != 0 || res.SpinDuration != -1 {
+		t.Fatalf("expected %+v, but found %+v", c, res)
+	}
+	if c.spinDuration() != 0 || c.sleepDuration() != 0 {
+		t.Fatalf("expected spinning and sleeping to be disabled")
+	}
+}
@@ -15,8 +15,16 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
+#ifdef __linux__
+#define _GNU_SOURCE
+#include <sched.h>
+#include <limits.h>
+#include <sys/syscall.h>
+#include <linux/futex.h>
+#endif
 #include <stddef.h>
 #include <stdlib.h>
+#include <time.h>
 #include <unistd.h>
 #include <sys/time.h>
 #include "extract.h"
@@ -35,20 +43,175 @@ enum worker_state
 	DATA_REQ = 2,
 	EXIT_REQ = 3,
 	EXIT_ACK = 4,
+	DATA_REQ_WAIT = 5, // a data request whose consumer is blocked waiting
 };
 
-static async_extractor_info *s_async_ctx_batch = NULL;
+// ASYNC_FUTEX_SPIN is the number of times a consumer checks for the request
+// to be completed before blocking, with the ASYNC_WAIT_FUTEX strategy
+#define ASYNC_FUTEX_SPIN 4096
+
+// s_async_wait and s_async_max_workers are the wait strategy and the max
+// number of workers, as configured when the batch is initialized
+static atomic_int s_async_wait = ASYNC_WAIT_SPIN;
+static atomic_int s_async_max_workers = 1;
+
+// s_async_sync_requests is the number of requests served with a regular
+// C -> Go call, because the async optimization is not in use
+static atomic_uint_least64_t s_async_sync_requests = 0;
 
-async_extractor_info *async_init(size_t size)
+// s_async_ctx_chunks are the chunks of the batch shared with the Go
+// workers. Chunks are NULL until allocated, and are allocated in order.
+static _Atomic(async_extractor_info *) s_async_ctx_chunks[ASYNC_MAX_CHUNKS];
+
+async_extractor_info *async_alloc_chunk(size_t chunk_idx)
 {
-	s_async_ctx_batch = (async_extractor_info *)malloc(sizeof(async_extractor_info) * size);
-	return s_async_ctx_batch;
+	// note: all the locks start in the UNUSED state
+	async_extractor_info *chunk = (async_extractor_info *)calloc(ASYNC_CHUNK_SIZE, sizeof(async_extractor_info));
+	atomic_store_explicit(&s_async_ctx_chunks[chunk_idx], chunk, memory_order_seq_cst);
+	return chunk;
 }
 
 void async_deinit()
 {
-	free(s_async_ctx_batch);
-	s_async_ctx_batch = NULL;
+	for (size_t i = 0; i < ASYNC_MAX_CHUNKS; i++)
+	{
+		free(atomic_exchange_explicit(&s_async_ctx_chunks[i], NULL, memory_order_seq_cst));
+	}
+}
+
+int async_futex_supported()
+{
+#ifdef __linux__
+	return 1;
+#else
+	return 0;
+#endif
+}
+
+void async_set_wait(int strategy, int max_workers)
+{
+	atomic_store_explicit(&s_async_max_workers, max_workers, memory_order_seq_cst);
+	atomic_store_explicit(&s_async_wait, strategy, memory_order_seq_cst);
+}
+
+// Blocks the calling thread until *addr is woken up with async_futex_wake,
+// as long as *addr is equal to val. A non-positive timeout means no timeout.
+void async_futex_wait(int32_t *addr, int32_t val, int64_t timeout_ns)
+{
+#ifdef __linux__
+	struct timespec ts;
+	ts.tv_sec = timeout_ns / 1000000000;
+	ts.tv_nsec = timeout_ns % 1000000000;
+	syscall(SYS_futex, addr, FUTEX_WAIT_PRIVATE, val, timeout_ns > 0 ? &ts : NULL, NULL, 0);
+#endif
+}
+
+// Wakes up all the threads blocked on addr with async_futex_wait
+void async_futex_wake(int32_t *addr)
+{
+#ifdef __linux__
+	syscall(SYS_futex, addr, FUTEX_WAKE_PRIVATE, INT_MAX, NULL, NULL, 0);
+#endif
+}
+
+uint64_t async_sync_requests()
+{
+	return atomic_load_explicit(&s_async_sync_requests, memory_order_relaxed);
+}
+
+// Returns the current time in nanoseconds from a monotonic clock,
+// or 0 if not supported
+static inline uint64_t async_now_ns()
+{
+#ifdef CLOCK_MONOTONIC
+	struct timespec ts;
+	if (clock_gettime(CLOCK_MONOTONIC, &ts) == 0)
+	{
+		return (uint64_t)ts.tv_sec * 1000000000 + (uint64_t)ts.tv_nsec;
+	}
+#endif
+	return 0;
+}
+
+// Updates the latency statistics of the given slot. This is only invoked
+// by the consumer owning the slot, so no read-modify-write is needed.
+static inline void async_record_latency(async_extractor_info *info, uint64_t start_ns)
+{
+	uint64_t end_ns = async_now_ns();
+	if (start_ns == 0 || end_ns < start_ns)
+	{
+		return;
+	}
+	uint64_t lat = end_ns - start_ns;
+	atomic_store_explicit(&info->stat_latency_samples, atomic_load_explicit(&info->stat_latency_samples, memory_order_relaxed) + 1, memory_order_relaxed);
+	atomic_store_explicit(&info->stat_latency_ns, atomic_load_explicit(&info->stat_latency_ns, memory_order_relaxed) + lat, memory_order_relaxed);
+	if (lat > atomic_load_explicit(&info->stat_latency_max_ns, memory_order_relaxed))
+	{
+		atomic_store_explicit(&info->stat_latency_max_ns, lat, memory_order_relaxed);
+	}
+}
+
+// Returns the batch slot at the given index, or NULL if not allocated
+static inline async_extractor_info *async_slot(size_t idx)
+{
+	async_extractor_info *chunk = NULL;
+	if (idx < ASYNC_MAX_CHUNKS * ASYNC_CHUNK_SIZE)
+	{
+		chunk = atomic_load_explicit(&s_async_ctx_chunks[idx / ASYNC_CHUNK_SIZE], memory_order_seq_cst);
+	}
+	return chunk == NULL ? NULL : &chunk[idx % ASYNC_CHUNK_SIZE];
+}
+
+// Waits for the request of the given slot to be completed with the
+// ASYNC_WAIT_FUTEX strategy: the worker is woken up if blocked, and the
+// consumer spins briefly before blocking until the request is completed
+static void async_wait_futex(async_extractor_info *info, size_t idx)
+{
+	// wake up the worker serving this slot, if it's blocked
+	async_extractor_info *w = async_slot(idx % (size_t)atomic_load_explicit(&s_async_max_workers, memory_order_seq_cst));
+	if (w != NULL && atomic_exchange_explicit(&w->wake, 0, memory_order_seq_cst) != 0)
+	{
+		async_futex_wake((int32_t *)&w->wake);
+	}
+
+	// spin briefly, as the request is likely to be completed soon under load
+	for (int i = 0; i < ASYNC_FUTEX_SPIN; i++)
+	{
+		if (atomic_load_explicit(&info->lock, memory_order_seq_cst) == WAIT)
+		{
+			return;
+		}
+	}
+
+	// notify the worker that we are blocking, and block until completion
+	int32_t cur;
+	while ((cur = atomic_load_explicit(&info->lock, memory_order_seq_cst)) != WAIT)
+	{
+		if (cur == DATA_REQ)
+		{
+			atomic_compare_exchange_strong_explicit(&info->lock, &cur, DATA_REQ_WAIT, memory_order_seq_cst, memory_order_seq_cst);
+			continue;
+		}
+		async_futex_wait((int32_t *)&info->lock, DATA_REQ_WAIT, 0);
+	}
+}
+
+// Pins the calling thread to the given CPU. Returns 0 in case of success,
+// and a non-zero value if pinning failed or is not supported.
+int async_pin_thread(int cpu)
+{
+#ifdef __linux__
+	cpu_set_t set;
+	if (cpu < 0 || cpu >= CPU_SETSIZE)
+	{
+		return -1;
+	}
+	CPU_ZERO(&set);
+	CPU_SET(cpu, &set);
+	return sched_setaffinity(0, sizeof(set), &set);
+#else
+	return -1;
+#endif
 }
 
 // Defined in extract.go
@@ -58,8 +221,8 @@ extern int32_t plugin_extract_fields_sync(ss_plugin_t *s,
 										  ss_plugin_extract_field *fields,
 										  ss_plugin_extract_value_offsets *offsets);
 
-// This is the plugin API function. If s_async_ctx_batch is
-// non-NULL, it calls the async extractor function. Otherwise, it
+// This is the plugin API function. If the batch slot assigned to s is
+// allocated and in use, it calls the async extractor function. Otherwise, it
 // calls the synchronous extractor function.
 FALCO_PLUGIN_SDK_PUBLIC int32_t plugin_extract_fields(ss_plugin_t *s,
 							  const ss_plugin_event_input *evt,
@@ -68,32 +231,53 @@ FALCO_PLUGIN_SDK_PUBLIC int32_t plugin_extract_fields(ss_plugin_t *s,
 	// note: concurrent requests are supported on the context batch, but each
 	// slot with a different value of ss_plugin_t *s. As such, for each lock
 	// we assume worker is already in WAIT state. This is possible because
-	// ss_plugin_t *s is an integer number representing a cgo.Handle, and can
-	// have values in the range of [1, cgo.MaxHandle]
+	// ss_plugin_t *s is an integer number representing a cgo.Handle, of which
+	// the least significant ASYNC_HANDLE_INDEX_BITS bits are an index in the
+	// range of [1, cgo.MaxHandle]
 	//
 	// todo(jasondellaluce): this is dependent on the implementation of our
 	// cgo.Handle to optimize performance, so change this if we ever change
 	// how cgo.Handles are represented
-	
+	size_t idx = ((size_t)s & ASYNC_HANDLE_INDEX_MASK) - 1;
+	async_extractor_info *info = async_slot(idx);
+
 	// if async optimization is not available, go with a simple C -> Go call
-	if (s_async_ctx_batch == NULL
-		|| atomic_load_explicit(&s_async_ctx_batch[(size_t)s - 1].lock, memory_order_seq_cst) != WAIT)
+	if (info == NULL
+		|| atomic_load_explicit(&info->lock, memory_order_seq_cst) != WAIT)
 	{
+		atomic_fetch_add_explicit(&s_async_sync_requests, 1, memory_order_relaxed);
 		return plugin_extract_fields_sync(s, evt, in->num_fields, in->fields, in->value_offsets);
 	}
 
+	// count the request, and sample its latency once in a while
+	uint64_t n = atomic_load_explicit(&info->stat_requests, memory_order_relaxed);
+	atomic_store_explicit(&info->stat_requests, n + 1, memory_order_relaxed);
+	uint64_t start_ns = n % ASYNC_LATENCY_SAMPLE_RATE == 0 ? async_now_ns() : 0;
+
 	// Set input data
-	s_async_ctx_batch[(size_t)s - 1].s = s;
-	s_async_ctx_batch[(size_t)s - 1].evt = evt;
-	s_async_ctx_batch[(size_t)s - 1].num_fields = in->num_fields;
-	s_async_ctx_batch[(size_t)s - 1].fields = in->fields;
-	s_async_ctx_batch[(size_t)s - 1].value_offsets = in->value_offsets;
+	info->s = s;
+	info->evt = evt;
+	info->num_fields = in->num_fields;
+	info->fields = in->fields;
+	info->value_offsets = in->value_offsets;
 
 	// notify data request
-	atomic_store_explicit(&s_async_ctx_batch[(size_t)s - 1].lock, DATA_REQ, memory_order_seq_cst);
+	atomic_store_explicit(&info->lock, DATA_REQ, memory_order_seq_cst);
 
-	// busy-wait until worker completation
-	while (atomic_load_explicit(&s_async_ctx_batch[(size_t)s - 1].lock, memory_order_seq_cst) != WAIT);
+	// wait until worker completation
+	if (atomic_load_explicit(&s_async_wait, memory_order_seq_cst) == ASYNC_WAIT_FUTEX)
+	{
+		async_wait_futex(info, idx);
+	}
+	else
+	{
+		// busy-wait
+		while (atomic_load_explicit(&info->lock, memory_order_seq_cst) != WAIT);
+	}
 
-	return s_async_ctx_batch[(size_t)s - 1].rc;
+	if (start_ns != 0)
+	{
+		async_record_latency(info, start_ns);
+	}
+	return info->rc;
 }
@@ -15,13 +15,29 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-// This package exports the following C function:
+// This package exports the following C functions:
 // - ss_plugin_rc plugin_extract_fields(ss_plugin_t *s, const ss_plugin_event *evt, uint32_t num_fields, ss_plugin_extract_field *fields)
+// - ss_plugin_rc plugin_extract_fields_batch(ss_plugin_t *s, uint32_t num_events, const ss_plugin_event_input *evts, uint32_t num_fields, ss_plugin_extract_field *fields)
+//
+// The exported plugin_extract_fields_batch is an extension provided by this
+// SDK, which is not part of plugin_api.h, and which allows extracting fields
+// from many events at once. The sdk.BatchExtractor interface can be
+// optionally implemented to extract each field from all the events at once.
 //
 // The exported plugin_extract_fields requires s to be a handle
 // of cgo.Handle from this SDK. The value of the s handle must implement
 // the sdk.Extractor and sdk.ExtractRequests interfaces.
 //
+// Before invoking Extract, each request goes through the callback installed
+// by the sdk/plugins/extractor package, if any, which resolves field aliases
+// and makes requests with invalid field arguments fail with
+// sdk.ErrInvalidFieldArg.
+//
+// Panics raised by Extract are recovered and turned into a failure, also
+// when the async extraction optimization is enabled. The panic is recorded
+// as a sdk.PanicError in the value of the s handle as for the sdk.LastError
+// and sdk.Poisoner interfaces.
+//
 // This function is part of the plugin_api interface as defined in plugin_api.h.
 // In almost all cases, your plugin should import this module, unless your
 // plugin exports those symbols by other means.
@@ -37,13 +53,28 @@ import (
 
 	"github.com/falcosecurity/plugin-sdk-go/pkg/cgo"
 	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk"
+	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk/internal/hooks"
+	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk/internal/recovery"
 )
 
 //export plugin_extract_fields_sync
-func plugin_extract_fields_sync(plgState C.uintptr_t, evt *C.ss_plugin_event_input, numFields uint32, fields *C.ss_plugin_extract_field, offsets *C.ss_plugin_extract_value_offsets) int32 {
+func plugin_extract_fields_sync(plgState C.uintptr_t, evt *C.ss_plugin_event_input, numFields uint32, fields *C.ss_plugin_extract_field, offsets *C.ss_plugin_extract_value_offsets) (rc int32) {
 	pHandle := cgo.Handle(plgState)
 	extract := pHandle.Value().(sdk.Extractor)
 	extrReqs := pHandle.Value().(sdk.ExtractRequests)
+	beforeExtract := hooks.OnBeforeExtract()
+
+	if err := recovery.Poisoned(pHandle); err != nil {
+		pHandle.Value().(sdk.LastError).SetLastError(err)
+		return sdk.SSPluginFailure
+	}
+
+	defer recovery.Recover(pHandle, func(error) {
+		rc = sdk.SSPluginFailure
+	})
+
+	evtReader := sdk.NewEventReader(unsafe.Pointer(evt))
+	defer sdk.ReleaseEventReader(evtReader)
 
 	// https://go.dev/wiki/cgo#turning-c-arrays-into-go-slices
 	flds := (*[1 << 28]C.struct_ss_plugin_extract_field)(unsafe.Pointer(fields))[:numFields:numFields]
@@ -58,6 +89,9 @@ func plugin_extract_fields_sync(plgState C.uintptr_t, evt *C.ss_plugin_event_inp
 		flds[i].res_len = (C.uint64_t)(0)
 		extrReq = extrReqs.ExtractRequests().Get(int(flds[i].field_id))
 		extrReq.SetPtr(unsafe.Pointer(&flds[i]))
+		if r, ok := extrReq.(sdk.StringBytesExtractRequest); ok {
+			r.SetEventPtr(unsafe.Pointer(evt))
+		}
 
 		if offsets == nil {
 			extrReq.SetOffsetPtrs(nil, nil)
@@ -68,7 +102,10 @@ func plugin_extract_fields_sync(plgState C.uintptr_t, evt *C.ss_plugin_event_inp
 			)
 		}
 
-		err := extract.Extract(extrReq, sdk.NewEventReader(unsafe.Pointer(evt)))
+		req, err := beforeExtract(pHandle, extrReq)
+		if err == nil {
+			err = extract.Extract(req, evtReader)
+		}
 		if err != nil {
 			pHandle.Value().(sdk.LastError).SetLastError(err)
 			return sdk.SSPluginFailure
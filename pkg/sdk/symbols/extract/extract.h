@@ -22,9 +22,19 @@ limitations under the License.
 
 typedef struct async_extractor_info
 {
+	// statistics, only written by the consumer owning the slot
+	atomic_uint_least64_t stat_requests;
+	atomic_uint_least64_t stat_latency_samples;
+	atomic_uint_least64_t stat_latency_ns;
+	atomic_uint_least64_t stat_latency_max_ns;
+
 	// lock
 	atomic_int_least32_t lock;
 
+	// wake is non-zero if the worker that has this slot as its first
+	// one is blocked waiting for requests (see ASYNC_WAIT_FUTEX)
+	atomic_int_least32_t wake;
+
 	// input data
 	ss_plugin_t *s;
 	const ss_plugin_event_input *evt;
@@ -36,5 +46,27 @@ typedef struct async_extractor_info
 	int32_t rc;
 } async_extractor_info;
 
-async_extractor_info *async_init(size_t size);
+// The batch of async_extractor_info is allocated in chunks of
+// ASYNC_CHUNK_SIZE entries, up to one entry for each cgo.Handle index.
+// ASYNC_HANDLE_INDEX_BITS must match cgo.HandleIndexBits.
+#define ASYNC_HANDLE_INDEX_BITS 20
+#define ASYNC_HANDLE_INDEX_MASK ((1 << ASYNC_HANDLE_INDEX_BITS) - 1)
+#define ASYNC_CHUNK_SIZE 256
+#define ASYNC_MAX_CHUNKS ((ASYNC_HANDLE_INDEX_MASK + 1) / ASYNC_CHUNK_SIZE)
+
+// The latency of one async request every ASYNC_LATENCY_SAMPLE_RATE
+// is measured by each consumer
+#define ASYNC_LATENCY_SAMPLE_RATE 64
+
+// The strategies with which the consumers and the workers wait for each other
+#define ASYNC_WAIT_SPIN 0
+#define ASYNC_WAIT_FUTEX 1
+
+async_extractor_info *async_alloc_chunk(size_t chunk_idx);
 void async_deinit();
+int async_pin_thread(int cpu);
+int async_futex_supported();
+void async_set_wait(int strategy, int max_workers);
+void async_futex_wait(int32_t *addr, int32_t val, int64_t timeout_ns);
+void async_futex_wake(int32_t *addr);
+uint64_t async_sync_requests();
@@ -25,14 +25,16 @@ import (
 	"github.com/falcosecurity/plugin-sdk-go/pkg/cgo"
 	"github.com/falcosecurity/plugin-sdk-go/pkg/ptr"
 	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk"
+	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk/internal/hooks"
 )
 
 var errTest = errors.New("testErr")
 
 type sampleExtract struct {
-	reqs    sdk.ExtractRequestPool
-	err     error
-	lastErr error
+	reqs        sdk.ExtractRequestPool
+	err         error
+	lastErr     error
+	shouldPanic bool
 }
 
 func (s *sampleExtract) ExtractRequests() sdk.ExtractRequestPool {
@@ -44,6 +46,10 @@ func (s *sampleExtract) SetExtractRequests(reqs sdk.ExtractRequestPool) {
 }
 
 func (s *sampleExtract) Extract(req sdk.ExtractRequest, evt sdk.EventReader) error {
+	if s.shouldPanic {
+		var nilSample *sampleExtract
+		return nilSample.err
+	}
 	return s.err
 }
 
@@ -157,3 +163,61 @@ func TestExtract(t *testing.T) {
 		t.Errorf("(lastErr): expected %s, but found %s", errTest.Error(), sample.lastErr.Error())
 	}
 }
+
+func TestExtractRecover(t *testing.T) {
+	sample := &sampleExtract{shouldPanic: true}
+	handle := cgo.NewHandle(sample)
+	defer handle.Delete()
+	reqs := sdk.NewExtractRequestPool()
+	defer reqs.Free()
+	sample.reqs = reqs
+
+	event, freeEvent := allocSSPluginEvent(1, uint64(time.Now().UnixNano()), []byte{1, 2, 3})
+	defer freeEvent()
+	field, freeField := allocSSPluginExtractField(1, sdk.FieldTypeUint64, "test.field", "")
+	defer freeField()
+
+	// nil dereference in the extractor is recovered
+	res := plugin_extract_fields_sync(_Ctype_uintptr_t(handle), event, 1, field, nil)
+	var panicErr *sdk.PanicError
+	if res != sdk.SSPluginFailure {
+		t.Errorf("(res): expected %d, but found %d", sdk.SSPluginFailure, res)
+	} else if !errors.As(sample.lastErr, &panicErr) {
+		t.Errorf("(lastErr): expected sdk.PanicError, but found %v", sample.lastErr)
+	}
+
+	// without sdk.Poisoner, subsequent calls invoke the extractor again
+	sample.shouldPanic = false
+	res = plugin_extract_fields_sync(_Ctype_uintptr_t(handle), event, 1, field, nil)
+	if res != sdk.SSPluginSuccess {
+		t.Errorf("(res): expected %d, but found %d", sdk.SSPluginSuccess, res)
+	}
+}
+
+func TestExtractBeforeExtract(t *testing.T) {
+	sample := &sampleExtract{shouldPanic: true}
+	handle := cgo.NewHandle(sample)
+	defer handle.Delete()
+	reqs := sdk.NewExtractRequestPool()
+	defer reqs.Free()
+	sample.reqs = reqs
+
+	event, freeEvent := allocSSPluginEvent(1, uint64(time.Now().UnixNano()), []byte{1, 2, 3})
+	defer freeEvent()
+	field, freeField := allocSSPluginExtractField(1, sdk.FieldTypeUint64, "test.field", "")
+	defer freeField()
+
+	// a failing hook prevents Extract from being invoked
+	hooks.SetOnBeforeExtract(func(h cgo.Handle, req sdk.ExtractRequest) (sdk.ExtractRequest, error) {
+		return nil, sdk.ErrInvalidFieldArg
+	})
+	defer hooks.SetOnBeforeExtract(func(h cgo.Handle, req sdk.ExtractRequest) (sdk.ExtractRequest, error) {
+		return req, nil
+	})
+	res := plugin_extract_fields_sync(_Ctype_uintptr_t(handle), event, 1, field, nil)
+	if res != sdk.SSPluginFailure {
+		t.Errorf("(res): expected %d, but found %d", sdk.SSPluginFailure, res)
+	} else if sample.lastErr != sdk.ErrInvalidFieldArg {
+		t.Errorf("(lastErr): expected %s, but found %v", sdk.ErrInvalidFieldArg.Error(), sample.lastErr)
+	}
+}
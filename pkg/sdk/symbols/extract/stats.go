@@ -0,0 +1,172 @@
+// SPDX-License-Identifier: Apache-2.0
+/*
+Copyright (C) 2026 The Falco Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package extract
+
+/*
+#include "extract.h"
+*/
+import "C"
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk"
+)
+
+// AsyncLatencySampleRate is the rate at which the latency of the requests
+// served by the async workers is sampled: the latency of one request every
+// AsyncLatencySampleRate is measured for each plugin.
+const AsyncLatencySampleRate = C.ASYNC_LATENCY_SAMPLE_RATE
+
+// AsyncStats represents the statistics of the async extraction optimization.
+// All the counters are cumulative since the start of the program, and
+// include all the plugins sharing the same SDK.
+type AsyncStats struct {
+	// AsyncRequests is the number of extraction requests served by
+	// the async workers.
+	AsyncRequests uint64
+	//
+	// SyncRequests is the number of extraction requests served with a
+	// regular C -> Go call, because the async optimization is disabled,
+	// unavailable, or not started for the requesting plugin.
+	SyncRequests uint64
+	//
+	// WorkerSleeps is the number of times an idle async worker slept or
+	// yielded the CPU after busy-waiting, with AsyncWaitSpin.
+	WorkerSleeps uint64
+	//
+	// WorkerParks is the number of times an idle async worker blocked
+	// waiting for new requests after busy-waiting, with AsyncWaitFutex.
+	WorkerParks uint64
+	//
+	// ActiveWorkers is the number of async workers currently running.
+	ActiveWorkers int
+	//
+	// LatencySamples is the number of requests served by the async workers
+	// whose latency has been measured (see AsyncLatencySampleRate).
+	LatencySamples uint64
+	//
+	// MeanLatency and MaxLatency are the mean and max latency of the
+	// sampled requests, from when they are sent by the plugin framework
+	// to when they are completed by an async worker.
+	MeanLatency time.Duration
+	MaxLatency  time.Duration
+}
+
+// Metrics returns the statistics as metrics suitable for being
+// returned by plugin_get_metrics().
+func (s AsyncStats) Metrics() []sdk.Metric {
+	return []sdk.Metric{
+		{Name: "async_extract_requests", Type: sdk.MetricTypeMonotonic, Value: s.AsyncRequests},
+		{Name: "async_extract_sync_requests", Type: sdk.MetricTypeMonotonic, Value: s.SyncRequests},
+		{Name: "async_extract_worker_sleeps", Type: sdk.MetricTypeMonotonic, Value: s.WorkerSleeps},
+		{Name: "async_extract_worker_parks", Type: sdk.MetricTypeMonotonic, Value: s.WorkerParks},
+		{Name: "async_extract_active_workers", Type: sdk.MetricTypeNonMonotonic, Value: uint64(s.ActiveWorkers)},
+		{Name: "async_extract_latency_mean_ns", Type: sdk.MetricTypeNonMonotonic, Value: uint64(s.MeanLatency)},
+		{Name: "async_extract_latency_max_ns", Type: sdk.MetricTypeNonMonotonic, Value: uint64(s.MaxLatency)},
+	}
+}
+
+// asyncStats are the statistics of the async extraction optimization
+// collected on the Go side
+type asyncStats struct {
+	// requests, latencySamples, latencyNs, and maxLatencyNs are collected
+	// from the batch slots once released, and are protected by the mutex
+	// of asyncContext
+	requests       uint64
+	latencySamples uint64
+	latencyNs      uint64
+	maxLatencyNs   uint64
+	//
+	// workerSleeps and workerParks are updated atomically by the workers
+	workerSleeps uint64
+	workerParks  uint64
+}
+
+// slotStats returns the statistics collected by the consumer of the
+// batch slot at the given index, which must be within the allocated chunks
+func (a *asyncContext) slotStats(batchIdx int32) (requests, samples, latencyNs, maxLatencyNs uint64) {
+	info := a.slot(batchIdx)
+	requests = atomic.LoadUint64((*uint64)(&info.stat_requests))
+	samples = atomic.LoadUint64((*uint64)(&info.stat_latency_samples))
+	latencyNs = atomic.LoadUint64((*uint64)(&info.stat_latency_ns))
+	maxLatencyNs = atomic.LoadUint64((*uint64)(&info.stat_latency_max_ns))
+	return
+}
+
+// collectSlotStats moves the statistics of the batch slot at the given
+// index into the ones of the context, and resets them. This must be invoked
+// with the context mutex held, and when the slot consumer is not active.
+func (a *asyncContext) collectSlotStats(batchIdx int32) {
+	requests, samples, latencyNs, maxLatencyNs := a.slotStats(batchIdx)
+	a.stats.requests += requests
+	a.stats.latencySamples += samples
+	a.stats.latencyNs += latencyNs
+	if maxLatencyNs > a.stats.maxLatencyNs {
+		a.stats.maxLatencyNs = maxLatencyNs
+	}
+	info := a.slot(batchIdx)
+	atomic.StoreUint64((*uint64)(&info.stat_requests), 0)
+	atomic.StoreUint64((*uint64)(&info.stat_latency_samples), 0)
+	atomic.StoreUint64((*uint64)(&info.stat_latency_ns), 0)
+	atomic.StoreUint64((*uint64)(&info.stat_latency_max_ns), 0)
+}
+
+func (a *asyncContext) Stats() AsyncStats {
+	a.m.Lock()
+	defer a.m.Unlock()
+
+	res := AsyncStats{
+		AsyncRequests:  a.stats.requests,
+		SyncRequests:   uint64(C.async_sync_requests()),
+		WorkerSleeps:   atomic.LoadUint64(&a.stats.workerSleeps),
+		WorkerParks:    atomic.LoadUint64(&a.stats.workerParks),
+		LatencySamples: a.stats.latencySamples,
+		MaxLatency:     time.Duration(a.stats.maxLatencyNs),
+	}
+	latencyNs := a.stats.latencyNs
+	for _, active := range a.activeWorkers {
+		if active {
+			res.ActiveWorkers++
+		}
+	}
+
+	// add the statistics of the slots still in use
+	if a.chunks != nil {
+		for i := int32(0); i <= atomic.LoadInt32(&a.maxBatchIdx); i++ {
+			requests, samples, slotLatencyNs, maxLatencyNs := a.slotStats(i)
+			res.AsyncRequests += requests
+			res.LatencySamples += samples
+			latencyNs += slotLatencyNs
+			if time.Duration(maxLatencyNs) > res.MaxLatency {
+				res.MaxLatency = time.Duration(maxLatencyNs)
+			}
+		}
+	}
+	if res.LatencySamples > 0 {
+		res.MeanLatency = time.Duration(latencyNs / res.LatencySamples)
+	}
+	return res
+}
+
+// Stats returns the statistics of the async extraction optimization.
+// This is safe to be invoked concurrently with the field extractions, but
+// the returned values might not reflect the ones in flight.
+func Stats() AsyncStats {
+	return ctx.Stats()
+}
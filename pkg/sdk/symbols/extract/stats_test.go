@@ -0,0 +1,69 @@
+// SPDX-License-Identifier: Apache-2.0
+/*
+Copyright (C) 2026 The Falco Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package extract
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func testSetSlotStats(a *asyncContext, batchIdx int32, requests, samples, latencyNs, maxLatencyNs uint64) {
+	info := a.slot(batchIdx)
+	atomic.StoreUint64((*uint64)(&info.stat_requests), requests)
+	atomic.StoreUint64((*uint64)(&info.stat_latency_samples), samples)
+	atomic.StoreUint64((*uint64)(&info.stat_latency_ns), latencyNs)
+	atomic.StoreUint64((*uint64)(&info.stat_latency_max_ns), maxLatencyNs)
+}
+
+func TestAsyncStats(t *testing.T) {
+	a := asyncContext{
+		chunks:        make([]*[asyncChunkSize]_Ctype_async_extractor_info, asyncMaxChunks),
+		maxWorkers:    2,
+		activeWorkers: []bool{true, false},
+	}
+	a.growBatch(asyncChunkSize, testAllocAsyncBatch)
+	atomic.StoreInt32(&a.maxBatchIdx, asyncChunkSize)
+	atomic.AddUint64(&a.stats.workerSleeps, 3)
+	atomic.AddUint64(&a.stats.workerParks, 4)
+
+	// statistics are summed across the slots in use
+	testSetSlotStats(&a, 0, 100, 2, 300, 200)
+	testSetSlotStats(&a, asyncChunkSize, 50, 1, 900, 900)
+	s := a.Stats()
+	if s.AsyncRequests != 150 || s.LatencySamples != 3 || s.WorkerSleeps != 3 || s.WorkerParks != 4 || s.ActiveWorkers != 1 {
+		t.Fatalf("unexpected stats: %+v", s)
+	}
+	if s.MeanLatency != 400*time.Nanosecond || s.MaxLatency != 900*time.Nanosecond {
+		t.Fatalf("unexpected latency stats: %+v", s)
+	}
+
+	// statistics are kept once the slots are released
+	a.collectSlotStats(asyncChunkSize)
+	if r, _, _, _ := a.slotStats(asyncChunkSize); r != 0 {
+		t.Fatalf("expected slot stats to be reset")
+	}
+	a.chunks = nil
+	if s2 := a.Stats(); s2.AsyncRequests != 50 || s2.MaxLatency != 900*time.Nanosecond || s2.MeanLatency != 900*time.Nanosecond {
+		t.Fatalf("unexpected stats after release: %+v", s2)
+	}
+
+	if m := s.Metrics(); len(m) == 0 || m[0].Value != s.AsyncRequests {
+		t.Fatalf("unexpected metrics: %+v", m)
+	}
+}
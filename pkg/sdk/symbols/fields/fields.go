@@ -26,6 +26,8 @@ package fields
 import "C"
 import (
 	"encoding/json"
+	"fmt"
+	"sync/atomic"
 
 	"github.com/falcosecurity/plugin-sdk-go/pkg/ptr"
 	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk"
@@ -34,12 +36,134 @@ import (
 var (
 	fields []sdk.FieldEntry
 	buf    ptr.StringBuffer
+	//
+	// exported is the list of fields returned by plugin_get_fields, which
+	// is the list set with SetFields followed by one entry for each alias
+	exported []sdk.FieldEntry
+	//
+	// aliasOf maps the index of each alias entry, after subtracting
+	// len(fields), to the index of its field in the list set with SetFields
+	aliasOf []uint64
+	//
+	// warnings describes the issues of the fields set with SetFields that
+	// do not make them invalid
+	warnings []string
 )
 
+// aliasExtractRequest wraps an sdk.ExtractRequest for an alias entry and
+// reports the ID and name of the aliased field instead
+type aliasExtractRequest struct {
+	sdk.ExtractRequest
+	fieldID uint64
+}
+
+func (a *aliasExtractRequest) FieldID() uint64 {
+	return a.fieldID
+}
+
+func (a *aliasExtractRequest) Field() string {
+	return fields[a.fieldID].Name
+}
+
 // SetFields sets a slice of sdk.FieldEntry representing the list of extractor
-// fields exported by this plugin.
+// fields exported by this plugin. This function panics if one of the fields
+// is not valid as for the Check method of sdk.FieldEntry, or if two fields
+// or aliases have the same name. The issues that do not make a field
+// invalid are returned by Warnings.
+//
+// Each of the aliases of a field is exported as a distinct field entry,
+// appended after the ones of f so that the index of each field in f stays
+// the same. Aliases inherit all the other members of their field.
 func SetFields(f []sdk.FieldEntry) {
+	var exp []sdk.FieldEntry
+	var als []uint64
+	var warns []string
+	names := make(map[string]bool)
+	checkName := func(name string) {
+		if names[name] {
+			panic(fmt.Sprintf("plugin-sdk-go/sdk/symbols/fields.SetFields: field '%s' is defined more than once", name))
+		}
+		names[name] = true
+	}
+	for i, field := range f {
+		if err := field.Check(); err != nil {
+			panic(fmt.Sprintf("plugin-sdk-go/sdk/symbols/fields.SetFields: invalid field '%s': %s", field.Name, err.Error()))
+		}
+		for _, w := range field.Warnings() {
+			warns = append(warns, fmt.Sprintf("field '%s': %s", field.Name, w))
+		}
+		checkName(field.Name)
+		for _, a := range field.Aliases {
+			checkName(a)
+			alias := field
+			alias.Name = a
+			alias.Aliases = nil
+			exp = append(exp, alias)
+			als = append(als, uint64(i))
+		}
+	}
 	fields = f
+	exported = append(append([]sdk.FieldEntry{}, f...), exp...)
+	aliasOf = als
+	warnings = warns
+}
+
+// Resolve returns an sdk.ExtractRequest reporting the ID and the name of the
+// aliased field if the given one is a request for an alias. Otherwise, the
+// given sdk.ExtractRequest is returned as-is.
+func Resolve(req sdk.ExtractRequest) sdk.ExtractRequest {
+	if req.FieldID() >= uint64(len(fields)) && req.FieldID()-uint64(len(fields)) < uint64(len(aliasOf)) {
+		return &aliasExtractRequest{
+			ExtractRequest: req,
+			fieldID:        aliasOf[req.FieldID()-uint64(len(fields))],
+		}
+	}
+	return req
+}
+
+// Validate returns an error wrapping sdk.ErrInvalidFieldArg if the argument
+// of the given sdk.ExtractRequest does not satisfy the constraints of the
+// requested field, as for the Validate method of sdk.FieldEntryArg.
+// Requests for fields not set with SetFields are considered valid.
+func Validate(req sdk.ExtractRequest) error {
+	if req.FieldID() < uint64(len(fields)) {
+		return fields[req.FieldID()].Arg.Validate(req)
+	}
+	return nil
+}
+
+// Deprecations keeps track of the deprecated fields that have already been
+// warned about for a given plugin state.
+type Deprecations struct {
+	// warned has a non-zero entry for each deprecated field in the list
+	// set with SetFields that has already been warned about
+	warned []int32
+}
+
+// NewDeprecations returns a new Deprecations for the fields set with
+// SetFields, none of which has been warned about.
+func NewDeprecations() *Deprecations {
+	return &Deprecations{warned: make([]int32, len(fields))}
+}
+
+// Warning returns a non-nil error describing the deprecation notice of the
+// requested field, if the field is deprecated. For each field, this happens
+// only for the first request. This is safe to be invoked concurrently.
+func (d *Deprecations) Warning(req sdk.ExtractRequest) error {
+	id := req.FieldID()
+	if id < uint64(len(d.warned)) && len(fields[id].Deprecated) > 0 {
+		if atomic.CompareAndSwapInt32(&d.warned[id], 0, 1) {
+			return fmt.Errorf("field '%s' is deprecated: %s", fields[id].Name, fields[id].Deprecated)
+		}
+	}
+	return nil
+}
+
+// Warnings returns a description of each issue of the fields set with
+// SetFields that does not make them invalid, as for the Warnings method
+// of sdk.FieldEntry.
+func Warnings() []string {
+	return warnings
 }
 
 // Fields returns the slice of sdk.FieldEntry set with SetFields().
@@ -49,7 +173,7 @@ func Fields() []sdk.FieldEntry {
 
 //export plugin_get_fields
 func plugin_get_fields() *C.char {
-	b, err := json.Marshal(&fields)
+	b, err := json.Marshal(&exported)
 	if err != nil {
 		return nil
 	}
@@ -21,6 +21,7 @@ import (
 	"encoding/json"
 	"testing"
 	"reflect"
+	"strings"
 	"unsafe"
 
 	"github.com/falcosecurity/plugin-sdk-go/pkg/ptr"
@@ -57,3 +58,139 @@ func TestFields(t *testing.T) {
 		t.Errorf("expected %s, but found %s", string(b), str)
 	}
 }
+
+func TestSetFieldsInvalidArg(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("expected panic")
+		}
+	}()
+	SetFields([]sdk.FieldEntry{
+		{Type: "string", Name: "test.field", Arg: sdk.FieldEntryArg{IsKey: true, KeyPattern: "[a-z"}},
+	})
+}
+
+func TestSetFieldsWarnings(t *testing.T) {
+	// legacy inconsistencies of the arguments and unknown properties are
+	// reported as warnings
+	SetFields([]sdk.FieldEntry{
+		{Type: "string", Name: "test.field1", Arg: sdk.FieldEntryArg{IsRequired: true}},
+		{Type: "string", Name: "test.field2", Arg: sdk.FieldEntryArg{IsKey: true, IsIndex: true}},
+		{Type: "string", Name: "test.field3", Arg: sdk.FieldEntryArg{IsKey: true}},
+		{Type: "string", Name: "test.field4", Properties: []sdk.FieldProperty{"unknown"}},
+	})
+	if len(Warnings()) != 3 || !strings.Contains(Warnings()[0], "test.field1") ||
+		!strings.Contains(Warnings()[1], "test.field2") || !strings.Contains(Warnings()[2], "test.field4") {
+		t.Errorf("unexpected warnings: %v", Warnings())
+	}
+	SetFields([]sdk.FieldEntry{{Type: "string", Name: "test.field"}})
+	if len(Warnings()) != 0 {
+		t.Errorf("unexpected warnings: %v", Warnings())
+	}
+}
+
+type sampleExtractRequest struct {
+	sdk.ExtractRequest
+	fieldID uint64
+	field   string
+}
+
+func (s *sampleExtractRequest) FieldID() uint64 {
+	return s.fieldID
+}
+
+func (s *sampleExtractRequest) Field() string {
+	return s.field
+}
+
+func (s *sampleExtractRequest) ArgPresent() bool {
+	return false
+}
+
+func TestSetFieldsInvalid(t *testing.T) {
+	invalid := [][]sdk.FieldEntry{
+		{{Type: "string", Name: "test.field"}, {Type: "string", Name: "test.field"}},
+		{{Type: "string", Name: "test.field", Aliases: []string{"test.other"}}, {Type: "string", Name: "test.other"}},
+	}
+	for i, f := range invalid {
+		func() {
+			defer func() {
+				if r := recover(); r == nil {
+					t.Errorf("(#%d): expected panic", i)
+				}
+			}()
+			SetFields(f)
+		}()
+	}
+}
+
+func TestFieldsAliases(t *testing.T) {
+	SetFields([]sdk.FieldEntry{
+		{Type: "uint64", Name: "test.field1", Desc: "Test Field 1"},
+		{Type: "string", Name: "test.field2", Desc: "Test Field 2", Aliases: []string{"test.alias2"}},
+	})
+
+	// aliases are exported after the fields
+	var res []sdk.FieldEntry
+	cStr := plugin_get_fields()
+	if err := json.Unmarshal([]byte(ptr.GoString(unsafe.Pointer(cStr))), &res); err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 3 {
+		t.Fatalf("expected %d, but found %d", 3, len(res))
+	}
+	if res[2].Name != "test.alias2" || res[2].Type != "string" || res[2].Desc != "Test Field 2" {
+		t.Errorf("wrong alias entry: %+v", res[2])
+	}
+
+	// alias requests are resolved to their field
+	req := Resolve(&sampleExtractRequest{fieldID: 2, field: "test.alias2"})
+	if req.FieldID() != 1 || req.Field() != "test.field2" {
+		t.Errorf("expected alias to resolve to %d (%s), but found %d (%s)", 1, "test.field2", req.FieldID(), req.Field())
+	}
+	orig := &sampleExtractRequest{fieldID: 1, field: "test.field2"}
+	if Resolve(orig) != orig {
+		t.Errorf("expected non-alias request to be returned as-is")
+	}
+}
+
+func TestFieldsDeprecationWarning(t *testing.T) {
+	SetFields([]sdk.FieldEntry{
+		{Type: "uint64", Name: "test.field1", Desc: "Test Field 1"},
+		{Type: "uint64", Name: "test.field2", Desc: "Test Field 2", Deprecated: "use test.field1 instead"},
+	})
+	d := NewDeprecations()
+	if d.Warning(&sampleExtractRequest{fieldID: 0}) != nil {
+		t.Errorf("expected no warning for non-deprecated field")
+	}
+	if d.Warning(&sampleExtractRequest{fieldID: 1}) == nil {
+		t.Errorf("expected warning for deprecated field")
+	}
+	if d.Warning(&sampleExtractRequest{fieldID: 1}) != nil {
+		t.Errorf("expected warning to be reported only once")
+	}
+
+	// each plugin state is warned independently
+	if NewDeprecations().Warning(&sampleExtractRequest{fieldID: 1}) == nil {
+		t.Errorf("expected warning for deprecated field in another plugin state")
+	}
+}
+
+func TestFieldsAddOutput(t *testing.T) {
+	SetFields([]sdk.FieldEntry{
+		{Type: "uint64", Name: "test.field", Desc: "Test Field", Properties: []sdk.FieldProperty{sdk.FieldPropertyAddOutput}},
+	})
+
+	// addOutput is exported as a property, as parsed by the framework
+	var res []map[string]interface{}
+	cStr := plugin_get_fields()
+	if err := json.Unmarshal([]byte(ptr.GoString(unsafe.Pointer(cStr))), &res); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(res[0]["properties"], []interface{}{"addOutput"}) {
+		t.Errorf("expected addOutput property, but found %v", res[0]["properties"])
+	}
+	if _, ok := res[0]["addOutput"]; ok {
+		t.Errorf("unexpected addOutput key")
+	}
+}
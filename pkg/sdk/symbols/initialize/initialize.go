@@ -24,15 +24,22 @@ limitations under the License.
 // sdk.ExtractRequests interface, the function checks if an instance of
 // sdk.ExtractRequestPool has already been set. If not, a default
 // one is created on the fly and set with the SetExtractRequests method.
+// The log function passed by the owner in the init input, if any, is kept
+// for the SDK to send warnings to the owner's logger, such as the ones about
+// the usage of deprecated fields.
 //
 // The exported plugin_destroy requires s to be a handle
 // of cgo.Handle from this SDK. If the value of the s handle implements
 // the sdk.Destroyer interface, the function calls its Destroy method.
 // If any of sdk.ExtractRequests, sdk.LastErrorBuffer, sdk.StringerBuffer,
-// or sdk.ProgresserBuffer, are implemented, the function calls the Free method
-// on the returned sdk.StringBuffer. Finally, the function deletes the
+// sdk.ProgresserBuffer, or sdk.MetricsBuffer are implemented, the function
+// calls the Free method on the returned buffer. Finally, the function deletes the
 // s cgo.Handle.
 //
+// Panics raised by the plugin code in both functions are recovered. If the
+// init callback panics, plugin_init returns a failure and the returned
+// state reports a sdk.PanicError as its last error.
+//
 // This function is part of the plugin_api interface as defined in plugin_api.h.
 // In almost all cases, your plugin should import this module, unless your
 // plugin exports those symbols by other means.
@@ -43,10 +50,14 @@ package initialize
 */
 import "C"
 import (
+	"unsafe"
+
 	"github.com/falcosecurity/plugin-sdk-go/pkg/cgo"
 	"github.com/falcosecurity/plugin-sdk-go/pkg/ptr"
 	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk"
 	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk/internal/hooks"
+	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk/internal/logger"
+	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk/internal/recovery"
 )
 
 type baseInit struct {
@@ -66,6 +77,13 @@ func (b *baseInit) LastErrorBuffer() sdk.StringBuffer {
 	return &b.lastErrBuf
 }
 
+// failedInit is the state returned by plugin_init when the initialization
+// fails. The hooks run after a successful initialization are not run for
+// it, so neither are the ones run before destroying the state.
+type failedInit struct {
+	baseInit
+}
+
 // OnInitFn is a callback used in plugin_init.
 type OnInitFn func(config string) (sdk.PluginState, error)
 
@@ -82,15 +100,26 @@ func SetOnInit(fn OnInitFn) {
 	onInitFn = fn
 }
 
+// safeInit invokes onInitFn and turns a panic into a *sdk.PanicError.
+// There is no plugin state to record the panic into yet, so the error
+// is just returned to the caller.
+func safeInit(config string) (state sdk.PluginState, err error) {
+	defer recovery.Recover(0, func(panicErr error) {
+		state = nil
+		err = panicErr
+	})
+	return onInitFn(config)
+}
+
 //export plugin_init
 func plugin_init(in *C.ss_plugin_init_input, rc *int32) C.uintptr_t {
 	var state sdk.PluginState
 	var err error
 
 	// todo(jasondellaluce,therealbobo): support table access and owner operations
-	state, err = onInitFn(C.GoString(in.config))
+	state, err = safeInit(C.GoString(in.config))
 	if err != nil {
-		state = &baseInit{}
+		state = &failedInit{}
 		state.(sdk.LastError).SetLastError(err)
 		*rc = sdk.SSPluginFailure
 	} else {
@@ -104,6 +133,7 @@ func plugin_init(in *C.ss_plugin_init_input, rc *int32) C.uintptr_t {
 
 	handle := cgo.NewHandle(state)
 	if *rc == sdk.SSPluginSuccess {
+		logger.Set(handle, unsafe.Pointer(in.owner), unsafe.Pointer(in.log_fn))
 		hooks.OnAfterInit()(handle)
 	}
 
@@ -114,9 +144,15 @@ func plugin_init(in *C.ss_plugin_init_input, rc *int32) C.uintptr_t {
 func plugin_destroy(pState C.uintptr_t) {
 	if pState != 0 {
 		handle := cgo.Handle(pState)
-		hooks.OnBeforeDestroy()(handle)
+		if _, ok := handle.Value().(*failedInit); !ok {
+			hooks.OnBeforeDestroy()(handle)
+		}
 		if state, ok := handle.Value().(sdk.Destroyer); ok {
-			state.Destroy()
+			// a panicking Destroy must not prevent releasing the resources
+			func() {
+				defer recovery.Recover(handle, func(error) {})
+				state.Destroy()
+			}()
 		}
 		if state, ok := handle.Value().(sdk.ExtractRequests); ok {
 			state.ExtractRequests().Free()
@@ -131,6 +167,10 @@ func plugin_destroy(pState C.uintptr_t) {
 		if state, ok := handle.Value().(sdk.ProgressBuffer); ok {
 			state.ProgressBuffer().Free()
 		}
+		if state, ok := handle.Value().(sdk.MetricsBuffer); ok {
+			state.MetricsBuffer().Free()
+		}
+		logger.Delete(handle)
 		handle.Delete()
 	}
 }
@@ -25,6 +25,7 @@ import (
 	"github.com/falcosecurity/plugin-sdk-go/pkg/cgo"
 	"github.com/falcosecurity/plugin-sdk-go/pkg/ptr"
 	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk"
+	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk/internal/hooks"
 )
 
 var errTest = errors.New("errTest")
@@ -115,6 +116,24 @@ func TestInitialize(t *testing.T) {
 	}
 	handle.Delete()
 
+	// panic
+	SetOnInit(func(config string) (sdk.PluginState, error) {
+		panic(errTest)
+	})
+	in.config = (*_Ctype_char)(cStr.CharPtr())
+	handle = cgo.Handle(plugin_init(&in, &res))
+	if res != sdk.SSPluginFailure {
+		t.Errorf("(res): expected %d, but found %d", sdk.SSPluginFailure, res)
+	}
+	var panicErr *sdk.PanicError
+	val, ok = handle.Value().(sdk.LastError)
+	if !ok {
+		t.Errorf("(value): should implement sdk.LastError")
+	} else if !errors.As(val.LastError(), &panicErr) || panicErr.Value != errTest {
+		t.Errorf("(err): expected sdk.PanicError, but found %v", val.LastError())
+	}
+	handle.Delete()
+
 	// success
 	state := &sampleInitialize{}
 	SetOnInit(func(config string) (sdk.PluginState, error) {
@@ -136,3 +155,32 @@ func TestInitialize(t *testing.T) {
 		t.Errorf("expected Destroy() to be called")
 	}
 }
+
+func TestInitializeFailureHooks(t *testing.T) {
+	afterInit, beforeDestroy := 0, 0
+	hooks.SetOnAfterInit(func(cgo.Handle) { afterInit++ })
+	hooks.SetOnBeforeDestroy(func(cgo.Handle) { beforeDestroy++ })
+	defer hooks.SetOnAfterInit(func(cgo.Handle) {})
+	defer hooks.SetOnBeforeDestroy(func(cgo.Handle) {})
+
+	var res int32
+	var in _Ctype_struct_ss_plugin_init_input
+	SetOnInit(func(config string) (sdk.PluginState, error) {
+		return nil, errTest
+	})
+	plugin_destroy(plugin_init(&in, &res))
+	if res != sdk.SSPluginFailure {
+		t.Errorf("(res): expected %d, but found %d", sdk.SSPluginFailure, res)
+	}
+	if afterInit != 0 || beforeDestroy != 0 {
+		t.Errorf("expected no hook to be called on failure, but found %d and %d calls", afterInit, beforeDestroy)
+	}
+
+	SetOnInit(func(config string) (sdk.PluginState, error) {
+		return &sampleInitialize{}, nil
+	})
+	plugin_destroy(plugin_init(&in, &res))
+	if afterInit != 1 || beforeDestroy != 1 {
+		t.Errorf("expected hooks to be called once on success, but found %d and %d calls", afterInit, beforeDestroy)
+	}
+}
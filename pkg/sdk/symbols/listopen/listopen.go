@@ -18,6 +18,10 @@ limitations under the License.
 // This package exports the following C function:
 // - char* plugin_list_open_params()
 //
+// Panics raised by the OpenParams method of the plugin are recovered and
+// turned into a failure. The panic is recorded as a sdk.PanicError in the
+// value of the s handle as for the sdk.LastError and sdk.Poisoner interfaces.
+//
 // This function is part of the plugin_api interface as defined in plugin_api.h.
 // In almost all cases, your plugin should import this module, unless your
 // plugin exports those symbols by other means.
@@ -32,13 +36,23 @@ import (
 
 	"github.com/falcosecurity/plugin-sdk-go/pkg/cgo"
 	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk"
+	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk/internal/recovery"
 )
 
 //export plugin_list_open_params
-func plugin_list_open_params(pState C.uintptr_t, rc *int32) *C.char {
+func plugin_list_open_params(pState C.uintptr_t, rc *int32) (res *C.char) {
 	*rc = sdk.SSPluginSuccess
 	if openParams, ok := cgo.Handle(pState).Value().(sdk.OpenParams); ok {
 		if buf, ok := cgo.Handle(pState).Value().(sdk.OpenParamsBuffer); ok {
+			if err := recovery.Poisoned(cgo.Handle(pState)); err != nil {
+				cgo.Handle(pState).Value().(sdk.LastError).SetLastError(err)
+				*rc = sdk.SSPluginFailure
+				return nil
+			}
+			defer recovery.Recover(cgo.Handle(pState), func(error) {
+				*rc = sdk.SSPluginFailure
+				res = nil
+			})
 			list, err := openParams.OpenParams()
 			if err != nil {
 				cgo.Handle(pState).Value().(sdk.LastError).SetLastError(err)
@@ -38,9 +38,10 @@ var sampleParams = []sdk.OpenParam{
 }
 
 type sampleOpenParams struct {
-	lastErr error
-	params  []sdk.OpenParam
-	strBuf  ptr.StringBuffer
+	lastErr     error
+	params      []sdk.OpenParam
+	strBuf      ptr.StringBuffer
+	shouldPanic bool
 }
 
 func (b *sampleOpenParams) LastError() error {
@@ -56,6 +57,9 @@ func (s *sampleOpenParams) OpenParamsBuffer() sdk.StringBuffer {
 }
 
 func (s *sampleOpenParams) OpenParams() ([]sdk.OpenParam, error) {
+	if s.shouldPanic {
+		panic(errTest)
+	}
 	return s.params, s.lastErr
 }
 
@@ -83,8 +87,22 @@ func TestOpenParams(t *testing.T) {
 		t.Errorf("(value): expected nil, but found %s", str)
 	}
 
+	// panic
+	pState.lastErr = nil
+	pState.shouldPanic = true
+	var panicErr *sdk.PanicError
+	str = ptr.GoString(unsafe.Pointer(plugin_list_open_params((_Ctype_uintptr_t)(pHandle), &res)))
+	if res != sdk.SSPluginFailure {
+		t.Errorf("(res): expected %d, but found %d", sdk.SSPluginFailure, res)
+	} else if !errors.As(pState.LastError(), &panicErr) {
+		t.Errorf("(err): expected sdk.PanicError, but found %v", pState.LastError())
+	} else if str != "" {
+		t.Errorf("(value): expected nil, but found %s", str)
+	}
+
 	// success
 	pState.lastErr = nil
+	pState.shouldPanic = false
 	pState.params = sampleParams
 	bytes, err = json.Marshal(&sampleParams)
 	if err != nil {
@@ -0,0 +1,85 @@
+// SPDX-License-Identifier: Apache-2.0
+/*
+Copyright (C) 2026 The Falco Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This package exports the following C function:
+// - ss_plugin_metric* plugin_get_metrics(ss_plugin_t* s, uint32_t* num_metrics)
+//
+// The exported plugin_get_metrics requires s to be a handle of cgo.Handle
+// from this SDK. If the value of the s handle implements the
+// sdk.MetricsBuffer interface, the function returns the metrics provided by
+// the plugin through the sdk.Metrics interface, if implemented, followed by
+// the ones provided by the SDK itself, such as the statistics of the async
+// extraction optimization. Otherwise, no metric is returned.
+//
+// Panics raised by Metrics are recovered and reported as if the plugin
+// did not implement sdk.Metrics. The panic is recorded as a sdk.PanicError
+// in the value of the s handle as for the sdk.LastError and sdk.Poisoner
+// interfaces.
+//
+// This function is part of the plugin_api interface as defined in plugin_api.h.
+// In almost all cases, your plugin should import this module,
+// unless your plugin exports those symbols by other means.
+package metrics
+
+/*
+#include "../../plugin_api.h"
+*/
+import "C"
+import (
+	"github.com/falcosecurity/plugin-sdk-go/pkg/cgo"
+	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk"
+	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk/internal/hooks"
+	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk/internal/recovery"
+)
+
+// pluginMetrics returns the metrics of the plugin, or nil if it panics
+func pluginMetrics(handle cgo.Handle, m sdk.Metrics) (res []sdk.Metric) {
+	defer recovery.Recover(handle, func(error) {
+		res = nil
+	})
+	res = m.Metrics()
+	return res[:len(res):len(res)]
+}
+
+//export plugin_get_metrics
+func plugin_get_metrics(pState C.uintptr_t, numMetrics *uint32) *C.ss_plugin_metric {
+	*numMetrics = 0
+	if pState == 0 {
+		return nil
+	}
+	handle := cgo.Handle(pState)
+	state, ok := handle.Value().(sdk.MetricsBuffer)
+	if !ok {
+		return nil
+	}
+
+	var metrics []sdk.Metric
+	if m, ok := handle.Value().(sdk.Metrics); ok && recovery.Poisoned(handle) == nil {
+		metrics = pluginMetrics(handle, m)
+	}
+	metrics = append(metrics, hooks.OnMetrics()(handle)...)
+
+	buf := state.MetricsBuffer()
+	if err := buf.Write(metrics); err != nil {
+		if state, ok := handle.Value().(sdk.LastError); ok {
+			state.SetLastError(err)
+		}
+		return nil
+	}
+	*numMetrics = uint32(buf.Len())
+	return (*C.ss_plugin_metric)(buf.ArrayPtr())
+}
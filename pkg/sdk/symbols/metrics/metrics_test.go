@@ -0,0 +1,135 @@
+// SPDX-License-Identifier: Apache-2.0
+/*
+Copyright (C) 2026 The Falco Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"errors"
+	"testing"
+	"unsafe"
+
+	"github.com/falcosecurity/plugin-sdk-go/pkg/cgo"
+	"github.com/falcosecurity/plugin-sdk-go/pkg/ptr"
+	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk"
+	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk/internal/hooks"
+)
+
+type sampleMetrics struct {
+	buf         sdk.MetricBuffer
+	metrics     []sdk.Metric
+	lastErr     error
+	poisonErr   error
+	shouldPanic bool
+}
+
+func (s *sampleMetrics) MetricsBuffer() sdk.MetricBuffer {
+	return s.buf
+}
+
+func (s *sampleMetrics) Metrics() []sdk.Metric {
+	if s.shouldPanic {
+		panic("test panic")
+	}
+	return s.metrics
+}
+
+func (s *sampleMetrics) LastError() error {
+	return s.lastErr
+}
+
+func (s *sampleMetrics) SetLastError(err error) {
+	s.lastErr = err
+}
+
+func (s *sampleMetrics) Poisoned() error {
+	return s.poisonErr
+}
+
+func (s *sampleMetrics) SetPoisoned(err error) {
+	s.poisonErr = err
+}
+
+func getMetrics(h cgo.Handle) []string {
+	var n uint32
+	arr := unsafe.Slice(plugin_get_metrics(_Ctype_uintptr_t(h), &n), n)
+	res := []string{}
+	for _, m := range arr {
+		res = append(res, ptr.GoString(unsafe.Pointer(m.name)))
+	}
+	return res
+}
+
+func assertMetrics(t *testing.T, h cgo.Handle, expected ...string) {
+	t.Helper()
+	res := getMetrics(h)
+	if len(res) != len(expected) {
+		t.Fatalf("expected metrics %v, but found %v", expected, res)
+	}
+	for i := range res {
+		if res[i] != expected[i] {
+			t.Fatalf("expected metrics %v, but found %v", expected, res)
+		}
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	sample := &sampleMetrics{buf: sdk.NewMetricBuffer()}
+	defer sample.buf.Free()
+	handle := cgo.NewHandle(sample)
+	defer handle.Delete()
+
+	// plugin metrics only
+	assertMetrics(t, handle)
+	sample.metrics = []sdk.Metric{{Name: "a", Value: uint64(1)}, {Name: "b", Value: 2.0}}
+	assertMetrics(t, handle, "a", "b")
+
+	// SDK metrics are appended without modifying the plugin ones
+	hooks.SetOnMetrics(func(cgo.Handle) []sdk.Metric {
+		return []sdk.Metric{{Name: "sdk", Value: uint32(1)}}
+	})
+	defer hooks.SetOnMetrics(func(cgo.Handle) []sdk.Metric { return nil })
+	sample.metrics = make([]sdk.Metric, 1, 10)
+	sample.metrics[0] = sdk.Metric{Name: "a", Value: uint64(1)}
+	assertMetrics(t, handle, "a", "sdk")
+	if sample.metrics[:2][1].Name != "" {
+		t.Errorf("expected plugin metrics not to be modified")
+	}
+
+	// unsupported values are reported as errors
+	sample.metrics = []sdk.Metric{{Name: "a", Value: "str"}}
+	assertMetrics(t, handle)
+	if sample.lastErr == nil {
+		t.Errorf("expected last error to be set")
+	}
+
+	// panics are recovered, and poisoned plugins report SDK metrics only
+	sample.shouldPanic = true
+	assertMetrics(t, handle, "sdk")
+	var panicErr *sdk.PanicError
+	if !errors.As(sample.poisonErr, &panicErr) {
+		t.Errorf("expected plugin to be poisoned, but found %v", sample.poisonErr)
+	}
+	sample.shouldPanic = false
+	assertMetrics(t, handle, "sdk")
+}
+
+func TestMetricsNoBuffer(t *testing.T) {
+	handle := cgo.NewHandle(1)
+	defer handle.Delete()
+	assertMetrics(t, handle)
+	assertMetrics(t, 0)
+}
@@ -23,6 +23,15 @@ limitations under the License.
 // the sdk.PluginState interface. The value of the h handle must implement
 // the sdk.Events and the sdk.NextBatcher interfaces.
 //
+// Errors returned by NextBatch that match sdk.ErrTemporary or sdk.ErrSkip
+// are set as the last error of the s handle value, but are reported to the
+// framework as timeouts so that the capture session is not ended.
+//
+// Panics raised by NextBatch are recovered and turned into a failure, with
+// a sdk.PanicError set as the last error of the s handle value. If the value
+// of the s handle implements sdk.Poisoner, it gets poisoned and subsequent
+// calls fail fast.
+//
 // This function is part of the plugin_api interface as defined in plugin_api.h.
 // In almost all cases, your plugin should import this module, unless your
 // plugin exports those symbols by other means.
@@ -33,23 +42,46 @@ package nextbatch
 */
 import "C"
 import (
+	"errors"
+
 	"github.com/falcosecurity/plugin-sdk-go/pkg/cgo"
 	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk"
+	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk/internal/recovery"
 )
 
 //export plugin_next_batch
-func plugin_next_batch(pState C.uintptr_t, iState C.uintptr_t, nevts *uint32, retEvts ***C.ss_plugin_event) int32 {
+func plugin_next_batch(pState C.uintptr_t, iState C.uintptr_t, nevts *uint32, retEvts ***C.ss_plugin_event) (rc int32) {
 	events := cgo.Handle(iState).Value().(sdk.Events).Events()
-	n, err := cgo.Handle(iState).Value().(sdk.NextBatcher).NextBatch(cgo.Handle(pState).Value().(sdk.PluginState), events)
+	nextBatcher := cgo.Handle(iState).Value().(sdk.NextBatcher)
+	plgState := cgo.Handle(pState).Value().(sdk.PluginState)
+
+	if err := recovery.Poisoned(cgo.Handle(pState)); err != nil {
+		*nevts = uint32(0)
+		*retEvts = nil
+		cgo.Handle(pState).Value().(sdk.LastError).SetLastError(err)
+		return sdk.SSPluginFailure
+	}
+
+	defer recovery.Recover(cgo.Handle(pState), func(error) {
+		*nevts = uint32(0)
+		*retEvts = nil
+		rc = sdk.SSPluginFailure
+	})
+
+	n, err := nextBatcher.NextBatch(plgState, events)
 
 	*nevts = uint32(n)
 	*retEvts = (**C.ss_plugin_event)(events.ArrayPtr())
-	switch err {
-	case nil:
+	switch {
+	case err == nil:
 		return sdk.SSPluginSuccess
-	case sdk.ErrEOF:
+	case errors.Is(err, sdk.ErrEOF):
 		return sdk.SSPluginEOF
-	case sdk.ErrTimeout:
+	case errors.Is(err, sdk.ErrTimeout):
+		return sdk.SSPluginTimeout
+	case errors.Is(err, sdk.ErrTemporary), errors.Is(err, sdk.ErrSkip):
+		// the failure is recorded, but the capture session goes on
+		cgo.Handle(pState).Value().(sdk.LastError).SetLastError(err)
 		return sdk.SSPluginTimeout
 	default:
 		*nevts = uint32(0)
@@ -19,6 +19,7 @@ package nextbatch
 
 import (
 	"errors"
+	"fmt"
 	"testing"
 	"unsafe"
 
@@ -103,9 +104,82 @@ func TestNextBatch(t *testing.T) {
 	sample.err = sdk.ErrEOF
 	doTest("timeout", sdk.SSPluginEOF, uint32(sample.n), events.ArrayPtr(), nil)
 
+	// wrapped eof
+	sample.n = 5
+	sample.err = fmt.Errorf("wrapped: %w", sdk.ErrEOF)
+	doTest("wrapped eof", sdk.SSPluginEOF, uint32(sample.n), events.ArrayPtr(), nil)
+
+	// temporary failure
+	sample.n = 5
+	sample.err = sdk.Temporary(errTest)
+	doTest("temporary", sdk.SSPluginTimeout, uint32(sample.n), events.ArrayPtr(), sample.err)
+
+	// skipped event
+	sample.lastErr = nil
+	sample.n = 3
+	sample.err = fmt.Errorf("wrapped: %w", sdk.Skip(errTest))
+	doTest("skip", sdk.SSPluginTimeout, uint32(sample.n), events.ArrayPtr(), sample.err)
+
 	// failure
 	sample.n = 0
 	sample.err = errTest
 	doTest("failure", sdk.SSPluginFailure, uint32(sample.n), nil, errTest)
 
 }
+
+type samplePanicNextBatch struct {
+	sampleNextBatch
+	poisonErr error
+	calls     int
+}
+
+func (s *samplePanicNextBatch) NextBatch(pState sdk.PluginState, evts sdk.EventWriters) (int, error) {
+	s.calls++
+	panic("test panic")
+}
+
+func (s *samplePanicNextBatch) Poisoned() error {
+	return s.poisonErr
+}
+
+func (s *samplePanicNextBatch) SetPoisoned(err error) {
+	s.poisonErr = err
+}
+
+func TestNextBatchRecover(t *testing.T) {
+	sample := &samplePanicNextBatch{}
+	handle := cgo.NewHandle(sample)
+	defer handle.Delete()
+	events, err := sdk.NewEventWriters(10, 10)
+	if err != nil {
+		t.Error(err)
+	}
+	defer events.Free()
+	sample.events = events
+
+	var resNum uint32
+	var resPtr **_Ctype_ss_plugin_event
+
+	// panic is recovered
+	r := plugin_next_batch(_Ctype_uintptr_t(handle), _Ctype_uintptr_t(handle), &resNum, &resPtr)
+	var panicErr *sdk.PanicError
+	if r != sdk.SSPluginFailure {
+		t.Errorf("(res): expected %d, but found %d", sdk.SSPluginFailure, r)
+	} else if resNum != 0 || resPtr != nil {
+		t.Errorf("(num, ptr): expected zero values, but found %d, %v", resNum, resPtr)
+	} else if !errors.As(sample.lastErr, &panicErr) || len(panicErr.Stack) == 0 {
+		t.Errorf("(err): expected sdk.PanicError with stack, but found %v", sample.lastErr)
+	} else if sample.poisonErr != sample.lastErr {
+		t.Errorf("(poison): expected state to be poisoned")
+	}
+
+	// poisoned state fails fast
+	r = plugin_next_batch(_Ctype_uintptr_t(handle), _Ctype_uintptr_t(handle), &resNum, &resPtr)
+	if r != sdk.SSPluginFailure {
+		t.Errorf("(res): expected %d, but found %d", sdk.SSPluginFailure, r)
+	} else if !errors.Is(sample.lastErr, sdk.ErrPoisoned) {
+		t.Errorf("(err): expected %s, but found %v", sdk.ErrPoisoned.Error(), sample.lastErr)
+	} else if sample.calls != 1 {
+		t.Errorf("(calls): expected %d, but found %d", 1, sample.calls)
+	}
+}
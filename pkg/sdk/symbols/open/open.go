@@ -34,6 +34,11 @@ limitations under the License.
 // on the returned sdk.EventWriters. Finally, the function deletes the
 // h cgo.Handle.
 //
+// Panics raised by the plugin code in both functions are recovered. The
+// panic is recorded as a sdk.PanicError in the value of the s handle as for
+// the sdk.LastError and sdk.Poisoner interfaces, and plugin_open returns
+// a failure. A poisoned s handle makes plugin_open fail fast.
+//
 // This function is part of the plugin_api interface as defined in plugin_api.h.
 // In almost all cases, your plugin should import this module, unless your
 // plugin exports those symbols by other means.
@@ -46,6 +51,7 @@ import "C"
 import (
 	"github.com/falcosecurity/plugin-sdk-go/pkg/cgo"
 	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk"
+	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk/internal/recovery"
 )
 
 var (
@@ -65,11 +71,22 @@ func SetOnOpen(fn OnOpenFn) {
 }
 
 //export plugin_open
-func plugin_open(plgState C.uintptr_t, params *C.char, rc *int32) C.uintptr_t {
+func plugin_open(plgState C.uintptr_t, params *C.char, rc *int32) (res C.uintptr_t) {
 	if onOpenFn == nil {
 		panic("plugin-sdk-go/sdk/symbols/open: SetOnOpen must be called")
 	}
 
+	if err := recovery.Poisoned(cgo.Handle(plgState)); err != nil {
+		cgo.Handle(plgState).Value().(sdk.LastError).SetLastError(err)
+		*rc = sdk.SSPluginFailure
+		return 0
+	}
+
+	defer recovery.Recover(cgo.Handle(plgState), func(error) {
+		*rc = sdk.SSPluginFailure
+		res = 0
+	})
+
 	iState, err := onOpenFn(C.GoString(params))
 	if err == nil {
 		// this allows a nil iState
@@ -97,7 +114,11 @@ func plugin_close(plgState C.uintptr_t, instanceState C.uintptr_t) {
 	if instanceState != 0 {
 		handle := cgo.Handle(instanceState)
 		if state, ok := handle.Value().(sdk.Closer); ok {
-			state.Close()
+			// a panicking Close must not prevent releasing the resources
+			func() {
+				defer recovery.Recover(cgo.Handle(plgState), func(error) {})
+				state.Close()
+			}()
 		}
 		if state, ok := handle.Value().(sdk.Events); ok {
 			state.Events().Free()
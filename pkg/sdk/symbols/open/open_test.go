@@ -109,6 +109,20 @@ func TestInitialize(t *testing.T) {
 		t.Errorf("(err): expected %s, but found %s", errTest.Error(), pState.LastError().Error())
 	}
 
+	// panic
+	SetOnOpen(func(config string) (sdk.InstanceState, error) {
+		panic(errTest)
+	})
+	iHandle = cgo.Handle(plugin_open((_Ctype_uintptr_t)(pHandle), (*_Ctype_char)(cStr.CharPtr()), &res))
+	var panicErr *sdk.PanicError
+	if res != sdk.SSPluginFailure {
+		t.Errorf("(res): expected %d, but found %d", sdk.SSPluginFailure, res)
+	} else if iHandle != 0 {
+		t.Errorf("(value): expected %d, but found %d", 0, iHandle)
+	} else if !errors.As(pState.LastError(), &panicErr) || panicErr.Value != errTest {
+		t.Errorf("(err): expected sdk.PanicError, but found %v", pState.LastError())
+	}
+
 	// success
 	iState := &sampleOpenInstance{}
 	SetOnOpen(func(config string) (sdk.InstanceState, error) {
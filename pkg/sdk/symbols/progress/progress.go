@@ -23,6 +23,11 @@ limitations under the License.
 // the sdk.PluginState interface. The value of the h handle must implement
 // the sdk.Progresser and sdk.ProgressBuffer interfaces.
 //
+// Panics raised by Progress are recovered and reported as if the instance
+// did not implement sdk.Progresser. The panic is recorded as a
+// sdk.PanicError in the value of the s handle as for the sdk.LastError and
+// sdk.Poisoner interfaces.
+//
 // This function is part of the plugin_api interface as defined in plugin_api.h.
 // In almost all cases, your plugin should import this module,
 // unless your plugin exports those symbols by other means.
@@ -35,14 +40,20 @@ import "C"
 import (
 	"github.com/falcosecurity/plugin-sdk-go/pkg/cgo"
 	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk"
+	"github.com/falcosecurity/plugin-sdk-go/pkg/sdk/internal/recovery"
 )
 
 //export plugin_get_progress
-func plugin_get_progress(pState C.uintptr_t, iState C.uintptr_t, progress_pct *uint32) *C.char {
+func plugin_get_progress(pState C.uintptr_t, iState C.uintptr_t, progress_pct *uint32) (res *C.char) {
 	buf := cgo.Handle(iState).Value().(sdk.ProgressBuffer).ProgressBuffer()
 	progresser, ok := cgo.Handle(iState).Value().(sdk.Progresser)
-	if ok {
-		pct, str := progresser.Progress(cgo.Handle(pState).Value().(sdk.PluginState))
+	if ok && recovery.Poisoned(cgo.Handle(pState)) == nil {
+		plgState := cgo.Handle(pState).Value().(sdk.PluginState)
+		defer recovery.Recover(cgo.Handle(pState), func(error) {
+			*progress_pct = 0
+			res = nil
+		})
+		pct, str := progresser.Progress(plgState)
 		*progress_pct = uint32(pct * 10000)
 		buf.Write(str)
 		return (*C.char)(buf.CharPtr())
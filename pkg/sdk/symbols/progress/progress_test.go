@@ -30,8 +30,9 @@ import (
 var testPct = float64(0.45)
 
 type sampleProgress struct {
-	strBuf ptr.StringBuffer
-	pct    float64
+	strBuf      ptr.StringBuffer
+	pct         float64
+	shouldPanic bool
 }
 
 func (s *sampleProgress) ProgressBuffer() sdk.StringBuffer {
@@ -43,6 +44,9 @@ func formatPercent(pct float64) string {
 }
 
 func (s *sampleProgress) Progress(pState sdk.PluginState) (float64, string) {
+	if s.shouldPanic {
+		panic("test panic")
+	}
 	return s.pct, formatPercent(s.pct)
 }
 
@@ -77,6 +81,16 @@ func TestProgress(t *testing.T) {
 	if resPct != uint32(testPct*10000) {
 		t.Errorf("expected %d, but found %d", uint32(testPct*10000), resPct)
 	}
+
+	// test recovered panic
+	sample.shouldPanic = true
+	res := plugin_get_progress(_Ctype_uintptr_t(pHandle), _Ctype_uintptr_t(iHandle), &resPct)
+	if res != nil {
+		t.Errorf("expected nil, but found %s", ptr.GoString(unsafe.Pointer(res)))
+	}
+	if resPct != 0 {
+		t.Errorf("expected %d, but found %d", 0, resPct)
+	}
 }
 
 func TestProgressPanic(t *testing.T) {